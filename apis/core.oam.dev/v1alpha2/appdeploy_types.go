@@ -22,6 +22,18 @@ import (
 	v1alpha1 "github.com/oam-dev/kubevela/apis/standard.oam.dev/v1alpha1"
 )
 
+// ComponentRolloutStrategyType decides how the components of an application are rolled out
+type ComponentRolloutStrategyType string
+
+const (
+	// OrderedComponentRollout rolls out the components one by one in the order of the component list,
+	// a component starts only after the previous one succeeds
+	OrderedComponentRollout ComponentRolloutStrategyType = "Ordered"
+
+	// ParallelComponentRollout rolls out all the components at the same time
+	ParallelComponentRollout ComponentRolloutStrategyType = "Parallel"
+)
+
 // ApplicationDeploymentSpec defines how to describe an upgrade between different application
 type ApplicationDeploymentSpec struct {
 	// TargetApplicationName contains the name of the application that we need to upgrade to.
@@ -32,21 +44,46 @@ type ApplicationDeploymentSpec struct {
 	// it can be omitted only when it's the first time to deploy the application
 	SourceApplicationName *string `json:"sourceApplicationName,omitempty"`
 
-	// The list of component to upgrade in the application.
-	// We only support single component application so far
-	// TODO: (RZ) Support multiple components in an application
+	// The list of component to upgrade in the application, each of them is rolled out with the rollout plan.
+	// It can be omitted only when the application has a single component
 	// +optional
 	ComponentList []string `json:"componentList,omitempty"`
 
+	// ComponentRolloutStrategy decides whether the components are rolled out one by one or at the same time,
+	// default is Ordered
+	// +optional
+	// +kubebuilder:validation:Enum=Ordered;Parallel
+	ComponentRolloutStrategy ComponentRolloutStrategyType `json:"componentRolloutStrategy,omitempty"`
+
 	// RolloutPlan is the details on how to rollout the resources
 	RolloutPlan v1alpha1.RolloutPlan `json:"rolloutPlan"`
 
 	// RevertOnDelete revert the rollout when the rollout CR is deleted, default is false
-	// It will remove the target application from the kubernetes
+	// It will scale the source application back to the full size and then remove the target application
+	// from the kubernetes
 	// +optional
 	RevertOnDelete *bool `json:"revertOnDelete,omitempty"`
 }
 
+// ComponentRolloutStatus is the rollout status of one component
+type ComponentRolloutStatus struct {
+	// ComponentName is the name of the component
+	ComponentName string `json:"componentName"`
+
+	v1alpha1.RolloutStatus `json:",inline"`
+}
+
+// ApplicationDeploymentStatus defines the observed state of ApplicationDeployment
+type ApplicationDeploymentStatus struct {
+	// RolloutStatus is the overall status, it's the same as the status of the component that is being rolled out
+	// except that the numbers of the upgraded replicas are the sum of all the components
+	v1alpha1.RolloutStatus `json:",inline"`
+
+	// ComponentStatuses is the rollout status of each component in the component list
+	// +optional
+	ComponentStatuses []ComponentRolloutStatus `json:"componentStatuses,omitempty"`
+}
+
 // ApplicationDeployment is the Schema for the ApplicationDeployment API
 // +kubebuilder:object:root=true
 // +kubebuilder:resource:categories={oam}
@@ -55,8 +92,8 @@ type ApplicationDeployment struct {
 	metav1.TypeMeta   `json:",inline"`
 	metav1.ObjectMeta `json:"metadata,omitempty"`
 
-	Spec   ApplicationDeploymentSpec `json:"spec,omitempty"`
-	Status v1alpha1.RolloutStatus    `json:"status,omitempty"`
+	Spec   ApplicationDeploymentSpec   `json:"spec,omitempty"`
+	Status ApplicationDeploymentStatus `json:"status,omitempty"`
 }
 
 // ApplicationDeploymentList contains a list of ApplicationDeployment
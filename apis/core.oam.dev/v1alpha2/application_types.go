@@ -32,10 +32,54 @@ type ApplicationPhase string
 const (
 	// ApplicationRendering means the app is rendering
 	ApplicationRendering ApplicationPhase = "rendering"
-	// ApplicationRunning means the app finished rendering and applied result to the cluster
+	// ApplicationRunning means the app is applied to the cluster and all of its components are healthy
 	ApplicationRunning ApplicationPhase = "running"
+	// ApplicationDegraded means the app is applied to the cluster and some of its components are unhealthy
+	ApplicationDegraded ApplicationPhase = "degraded"
+	// ApplicationUnhealthy means the app is applied to the cluster and none of its components is healthy
+	ApplicationUnhealthy ApplicationPhase = "unhealthy"
+	// ApplicationRendered means the app is rendered but not applied as it's annotated to dry run, the rendered
+	// resources are saved in the ConfigMap named <app>-dry-run
+	ApplicationRendered ApplicationPhase = "rendered"
+	// ApplicationDeleting means the app is being deleted and the resources rendered by it are being cleaned up
+	ApplicationDeleting ApplicationPhase = "deleting"
 )
 
+// AppResource is a resource rendered by the application
+type AppResource struct {
+	APIVersion string `json:"apiVersion"`
+	Kind       string `json:"kind"`
+	// Namespace is ignored if the resource is cluster-scoped
+	Namespace string `json:"namespace,omitempty"`
+	Name      string `json:"name"`
+	// Component is the component that renders the resource, it's empty for the application-level resources
+	Component string `json:"component,omitempty"`
+}
+
+// ApplicationComponentStatus records the health of a component, a component is healthy if its workload and all of
+// its traits are healthy
+type ApplicationComponentStatus struct {
+	Name string `json:"name"`
+	// WorkloadDefinition is the workload type of the component
+	WorkloadDefinition string `json:"workloadDefinition,omitempty"`
+	Healthy            bool   `json:"healthy"`
+	// Message is the result of the health policy of the workload, it explains why the workload is unhealthy
+	Message string `json:"message,omitempty"`
+	// Details are the optional details given by the health policy of the workload
+	Details map[string]string        `json:"details,omitempty"`
+	Traits  []ApplicationTraitStatus `json:"traits,omitempty"`
+}
+
+// ApplicationTraitStatus records the health of a trait
+type ApplicationTraitStatus struct {
+	Type    string `json:"type"`
+	Healthy bool   `json:"healthy"`
+	// Message is the result of the health policy of the trait, it explains why the trait is unhealthy
+	Message string `json:"message,omitempty"`
+	// Details are the optional details given by the health policy of the trait
+	Details map[string]string `json:"details,omitempty"`
+}
+
 // AppStatus defines the observed state of Application
 type AppStatus struct {
 	// INSERT ADDITIONAL STATUS FIELD - define observed state of cluster
@@ -47,6 +91,21 @@ type AppStatus struct {
 
 	// Components record the related Components created by Application Controller
 	Components []runtimev1alpha1.TypedReference `json:"components,omitempty"`
+
+	// Services record the health of every component, it's rechecked periodically after the application is applied
+	Services []ApplicationComponentStatus `json:"services,omitempty"`
+
+	// Resources record every resource rendered by the application, a resource is recorded after the resources
+	// it depends on. They're deleted in the reverse order when the application is deleted, unless the application
+	// has the annotation `app.oam.dev/orphan-resources: "true"`.
+	Resources []AppResource `json:"resources,omitempty"`
+
+	// Dependency records the dependencies of the components that are not satisfied yet, the workloads waiting
+	// for them are not applied.
+	Dependency DependencyStatus `json:"dependency,omitempty"`
+
+	// LatestRevision is the ApplicationRevision of the application that is applied last
+	LatestRevision *Revision `json:"latestRevision,omitempty"`
 }
 
 // ApplicationTrait defines the trait of application
@@ -70,13 +129,80 @@ type ApplicationComponent struct {
 	// scopes in ApplicationComponent defines the component-level scopes
 	// the format is <scope-type:scope-instance-name> pairs, the key represents type of `ScopeDefinition` while the value represent the name of scope instance.
 	Scopes map[string]string `json:"scopes,omitempty"`
+
+	// DependsOn are the names of the components that must be ready before the workload of this component is
+	// applied. A component is ready when all of its outputs are ready, or when its workload is created if it has
+	// no outputs. The components that the inputs come from are dependencies implicitly.
+	// +optional
+	DependsOn []string `json:"dependsOn,omitempty"`
+
+	// Outputs export the fields of the workload of this component to the components depending on it.
+	// +optional
+	Outputs []ApplicationComponentOutput `json:"outputs,omitempty"`
+
+	// Inputs fill the fields of the workload of this component with the outputs of the components it depends on.
+	// +optional
+	Inputs []ApplicationComponentInput `json:"inputs,omitempty"`
+}
+
+// ApplicationComponentOutput exports a field of the workload of a component, e.g. the name of the connection
+// secret of a database.
+type ApplicationComponentOutput struct {
+	// Name is the name of the output, it's unique in the component.
+	Name string `json:"name"`
+
+	// FieldPath refers to the field of the workload, e.g. spec.writeConnectionSecretToRef.name.
+	FieldPath string `json:"fieldPath"`
+
+	// Conditions specify the conditions that should be satisfied before the output is ready.
+	// If no conditions is specified, it is by default to check the output value not empty.
+	// +optional
+	Conditions []ConditionRequirement `json:"conditions,omitempty"`
+}
+
+// ApplicationComponentInput fills the fields of the workload of a component with an output of another component.
+type ApplicationComponentInput struct {
+	// Component is the name of the component the value comes from.
+	Component string `json:"component"`
+
+	// Output is the name of the output of the component.
+	Output string `json:"output"`
+
+	// ToFieldPaths specifies the field paths of the workload to fill the value.
+	ToFieldPaths []string `json:"toFieldPaths"`
+
+	// StrategyMergeKeys specifies the merge keys if the toFieldPaths target is an array, the value is appended
+	// to the array by default.
+	// +optional
+	StrategyMergeKeys []string `json:"strategyMergeKeys,omitempty"`
+}
+
+// ApplicationScope defines an application-level scope, the components of the application are put into
+// the scope instance without declaring it in every component.
+type ApplicationScope struct {
+	// Name is the name of the scope instance, an existing scope instance with the same name is adopted as is.
+	Name string `json:"name"`
+	// Type is the name of the `ScopeDefinition` of the scope.
+	Type string `json:"type"`
+	// Properties is the spec of the scope instance when it's created by the application.
+	// +kubebuilder:pruning:PreserveUnknownFields
+	// +optional
+	Properties runtime.RawExtension `json:"properties,omitempty"`
+	// ComponentSelector selects the components to be put into the scope by the labels of their workloads,
+	// the label `app.oam.dev/component` is set to the component name when matching.
+	// All the components are selected if it's not set.
+	// +optional
+	ComponentSelector *metav1.LabelSelector `json:"componentSelector,omitempty"`
 }
 
 // ApplicationSpec is the spec of Application
 type ApplicationSpec struct {
 	Components []ApplicationComponent `json:"components"`
 
-	// TODO(wonderflow): we should have application level scopes supported here
+	// Scopes are the application-level scopes, the components are attached to them
+	// in addition to their component-level scopes.
+	// +optional
+	Scopes []ApplicationScope `json:"scopes,omitempty"`
 }
 
 // +kubebuilder:object:root=true
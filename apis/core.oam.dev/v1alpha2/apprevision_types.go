@@ -0,0 +1,68 @@
+/*
+Copyright 2020 The KubeVela Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v1alpha2
+
+import (
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// DefinitionTemplate is the template of a workload or trait definition resolved when the revision is created
+type DefinitionTemplate struct {
+	// Name is the name of the definition
+	Name     string `json:"name"`
+	Template string `json:"template"`
+	// +optional
+	Health string `json:"health,omitempty"`
+}
+
+// ApplicationRevisionSpec is the snapshot of an application, it's immutable once created
+type ApplicationRevisionSpec struct {
+	// Revision is the revision number of the application, it starts from 1
+	Revision int64 `json:"revision"`
+
+	// Application is the spec of the application in this revision
+	Application ApplicationSpec `json:"application"`
+
+	// WorkloadTemplates are the templates of the workload types used by the application
+	// +optional
+	WorkloadTemplates []DefinitionTemplate `json:"workloadTemplates,omitempty"`
+
+	// TraitTemplates are the templates of the traits used by the application
+	// +optional
+	TraitTemplates []DefinitionTemplate `json:"traitTemplates,omitempty"`
+}
+
+// ApplicationRevision is an immutable snapshot of an Application and the definition templates it's rendered with,
+// it's created by the application controller for every change of the application.
+// +kubebuilder:object:root=true
+// +kubebuilder:resource:categories={oam},shortName=apprev
+// +kubebuilder:printcolumn:name="REVISION",type="integer",JSONPath=".spec.revision"
+// +kubebuilder:printcolumn:name="AGE",type="date",JSONPath=".metadata.creationTimestamp"
+type ApplicationRevision struct {
+	metav1.TypeMeta   `json:",inline"`
+	metav1.ObjectMeta `json:"metadata,omitempty"`
+
+	Spec ApplicationRevisionSpec `json:"spec,omitempty"`
+}
+
+// ApplicationRevisionList contains a list of ApplicationRevision
+// +kubebuilder:object:root=true
+type ApplicationRevisionList struct {
+	metav1.TypeMeta `json:",inline"`
+	metav1.ListMeta `json:"metadata,omitempty"`
+	Items           []ApplicationRevision `json:"items"`
+}
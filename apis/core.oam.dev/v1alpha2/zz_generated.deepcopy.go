@@ -22,9 +22,25 @@ package v1alpha2
 
 import (
 	"github.com/crossplane/crossplane-runtime/apis/core/v1alpha1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/runtime"
 )
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *AppResource) DeepCopyInto(out *AppResource) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new AppResource.
+func (in *AppResource) DeepCopy() *AppResource {
+	if in == nil {
+		return nil
+	}
+	out := new(AppResource)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *AppStatus) DeepCopyInto(out *AppStatus) {
 	*out = *in
@@ -34,6 +50,24 @@ func (in *AppStatus) DeepCopyInto(out *AppStatus) {
 		*out = make([]v1alpha1.TypedReference, len(*in))
 		copy(*out, *in)
 	}
+	if in.Services != nil {
+		in, out := &in.Services, &out.Services
+		*out = make([]ApplicationComponentStatus, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	if in.Resources != nil {
+		in, out := &in.Resources, &out.Resources
+		*out = make([]AppResource, len(*in))
+		copy(*out, *in)
+	}
+	in.Dependency.DeepCopyInto(&out.Dependency)
+	if in.LatestRevision != nil {
+		in, out := &in.LatestRevision, &out.LatestRevision
+		*out = new(Revision)
+		**out = **in
+	}
 }
 
 // DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new AppStatus.
@@ -91,6 +125,25 @@ func (in *ApplicationComponent) DeepCopyInto(out *ApplicationComponent) {
 			(*out)[key] = val
 		}
 	}
+	if in.DependsOn != nil {
+		in, out := &in.DependsOn, &out.DependsOn
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	if in.Outputs != nil {
+		in, out := &in.Outputs, &out.Outputs
+		*out = make([]ApplicationComponentOutput, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	if in.Inputs != nil {
+		in, out := &in.Inputs, &out.Inputs
+		*out = make([]ApplicationComponentInput, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
 }
 
 // DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ApplicationComponent.
@@ -103,6 +156,80 @@ func (in *ApplicationComponent) DeepCopy() *ApplicationComponent {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ApplicationComponentInput) DeepCopyInto(out *ApplicationComponentInput) {
+	*out = *in
+	if in.ToFieldPaths != nil {
+		in, out := &in.ToFieldPaths, &out.ToFieldPaths
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	if in.StrategyMergeKeys != nil {
+		in, out := &in.StrategyMergeKeys, &out.StrategyMergeKeys
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ApplicationComponentInput.
+func (in *ApplicationComponentInput) DeepCopy() *ApplicationComponentInput {
+	if in == nil {
+		return nil
+	}
+	out := new(ApplicationComponentInput)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ApplicationComponentOutput) DeepCopyInto(out *ApplicationComponentOutput) {
+	*out = *in
+	if in.Conditions != nil {
+		in, out := &in.Conditions, &out.Conditions
+		*out = make([]ConditionRequirement, len(*in))
+		copy(*out, *in)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ApplicationComponentOutput.
+func (in *ApplicationComponentOutput) DeepCopy() *ApplicationComponentOutput {
+	if in == nil {
+		return nil
+	}
+	out := new(ApplicationComponentOutput)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ApplicationComponentStatus) DeepCopyInto(out *ApplicationComponentStatus) {
+	*out = *in
+	if in.Details != nil {
+		in, out := &in.Details, &out.Details
+		*out = make(map[string]string, len(*in))
+		for key, val := range *in {
+			(*out)[key] = val
+		}
+	}
+	if in.Traits != nil {
+		in, out := &in.Traits, &out.Traits
+		*out = make([]ApplicationTraitStatus, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ApplicationComponentStatus.
+func (in *ApplicationComponentStatus) DeepCopy() *ApplicationComponentStatus {
+	if in == nil {
+		return nil
+	}
+	out := new(ApplicationComponentStatus)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *ApplicationConfiguration) DeepCopyInto(out *ApplicationConfiguration) {
 	*out = *in
@@ -349,6 +476,29 @@ func (in *ApplicationDeploymentSpec) DeepCopy() *ApplicationDeploymentSpec {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ApplicationDeploymentStatus) DeepCopyInto(out *ApplicationDeploymentStatus) {
+	*out = *in
+	in.RolloutStatus.DeepCopyInto(&out.RolloutStatus)
+	if in.ComponentStatuses != nil {
+		in, out := &in.ComponentStatuses, &out.ComponentStatuses
+		*out = make([]ComponentRolloutStatus, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ApplicationDeploymentStatus.
+func (in *ApplicationDeploymentStatus) DeepCopy() *ApplicationDeploymentStatus {
+	if in == nil {
+		return nil
+	}
+	out := new(ApplicationDeploymentStatus)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *ApplicationList) DeepCopyInto(out *ApplicationList) {
 	*out = *in
@@ -381,6 +531,111 @@ func (in *ApplicationList) DeepCopyObject() runtime.Object {
 	return nil
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ApplicationRevision) DeepCopyInto(out *ApplicationRevision) {
+	*out = *in
+	out.TypeMeta = in.TypeMeta
+	in.ObjectMeta.DeepCopyInto(&out.ObjectMeta)
+	in.Spec.DeepCopyInto(&out.Spec)
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ApplicationRevision.
+func (in *ApplicationRevision) DeepCopy() *ApplicationRevision {
+	if in == nil {
+		return nil
+	}
+	out := new(ApplicationRevision)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *ApplicationRevision) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ApplicationRevisionList) DeepCopyInto(out *ApplicationRevisionList) {
+	*out = *in
+	out.TypeMeta = in.TypeMeta
+	in.ListMeta.DeepCopyInto(&out.ListMeta)
+	if in.Items != nil {
+		in, out := &in.Items, &out.Items
+		*out = make([]ApplicationRevision, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ApplicationRevisionList.
+func (in *ApplicationRevisionList) DeepCopy() *ApplicationRevisionList {
+	if in == nil {
+		return nil
+	}
+	out := new(ApplicationRevisionList)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *ApplicationRevisionList) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ApplicationRevisionSpec) DeepCopyInto(out *ApplicationRevisionSpec) {
+	*out = *in
+	in.Application.DeepCopyInto(&out.Application)
+	if in.WorkloadTemplates != nil {
+		in, out := &in.WorkloadTemplates, &out.WorkloadTemplates
+		*out = make([]DefinitionTemplate, len(*in))
+		copy(*out, *in)
+	}
+	if in.TraitTemplates != nil {
+		in, out := &in.TraitTemplates, &out.TraitTemplates
+		*out = make([]DefinitionTemplate, len(*in))
+		copy(*out, *in)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ApplicationRevisionSpec.
+func (in *ApplicationRevisionSpec) DeepCopy() *ApplicationRevisionSpec {
+	if in == nil {
+		return nil
+	}
+	out := new(ApplicationRevisionSpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ApplicationScope) DeepCopyInto(out *ApplicationScope) {
+	*out = *in
+	in.Properties.DeepCopyInto(&out.Properties)
+	if in.ComponentSelector != nil {
+		in, out := &in.ComponentSelector, &out.ComponentSelector
+		*out = new(v1.LabelSelector)
+		(*in).DeepCopyInto(*out)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ApplicationScope.
+func (in *ApplicationScope) DeepCopy() *ApplicationScope {
+	if in == nil {
+		return nil
+	}
+	out := new(ApplicationScope)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *ApplicationSpec) DeepCopyInto(out *ApplicationSpec) {
 	*out = *in
@@ -391,6 +646,13 @@ func (in *ApplicationSpec) DeepCopyInto(out *ApplicationSpec) {
 			(*in)[i].DeepCopyInto(&(*out)[i])
 		}
 	}
+	if in.Scopes != nil {
+		in, out := &in.Scopes, &out.Scopes
+		*out = make([]ApplicationScope, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
 }
 
 // DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ApplicationSpec.
@@ -419,6 +681,28 @@ func (in *ApplicationTrait) DeepCopy() *ApplicationTrait {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ApplicationTraitStatus) DeepCopyInto(out *ApplicationTraitStatus) {
+	*out = *in
+	if in.Details != nil {
+		in, out := &in.Details, &out.Details
+		*out = make(map[string]string, len(*in))
+		for key, val := range *in {
+			(*out)[key] = val
+		}
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ApplicationTraitStatus.
+func (in *ApplicationTraitStatus) DeepCopy() *ApplicationTraitStatus {
+	if in == nil {
+		return nil
+	}
+	out := new(ApplicationTraitStatus)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *CPUResources) DeepCopyInto(out *CPUResources) {
 	*out = *in
@@ -562,6 +846,22 @@ func (in *ComponentParameterValue) DeepCopy() *ComponentParameterValue {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ComponentRolloutStatus) DeepCopyInto(out *ComponentRolloutStatus) {
+	*out = *in
+	in.RolloutStatus.DeepCopyInto(&out.RolloutStatus)
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ComponentRolloutStatus.
+func (in *ComponentRolloutStatus) DeepCopy() *ComponentRolloutStatus {
+	if in == nil {
+		return nil
+	}
+	out := new(ComponentRolloutStatus)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *ComponentScope) DeepCopyInto(out *ComponentScope) {
 	*out = *in
@@ -1081,6 +1381,21 @@ func (in *DefinitionReference) DeepCopy() *DefinitionReference {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *DefinitionTemplate) DeepCopyInto(out *DefinitionTemplate) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new DefinitionTemplate.
+func (in *DefinitionTemplate) DeepCopy() *DefinitionTemplate {
+	if in == nil {
+		return nil
+	}
+	out := new(DefinitionTemplate)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *DependencyFromObject) DeepCopyInto(out *DependencyFromObject) {
 	*out = *in
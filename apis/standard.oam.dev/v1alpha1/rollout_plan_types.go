@@ -2,6 +2,8 @@ package v1alpha1
 
 import (
 	runtimev1alpha1 "github.com/crossplane/crossplane-runtime/apis/core/v1alpha1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/util/intstr"
 )
 
@@ -16,7 +18,8 @@ const (
 	DecreaseFirstRolloutStrategyType RolloutStrategyType = "DecreaseFirst"
 )
 
-// HookType can be pre, post or during rollout
+// HookType can be pre, post or during rollout. The initialize and pre-batch hooks gate the rollout, it won't move
+// forward until they succeed. The post-batch and finalize hooks only notify, a failure is recorded but ignored.
 type HookType string
 
 const (
@@ -63,7 +66,7 @@ const (
 	BatchReady BatchRollingState = "batchReady"
 	// BatchVerifying verifying if the application is ready to roll. This happens when it's either manual or
 	// automatic with analysis
-	BatchVerifying RollingState = "batchVerifying"
+	BatchVerifying BatchRollingState = "batchVerifying"
 	// BatchAvailable one batch is ready, we could move to the batch
 	BatchAvailable BatchRollingState = "batchAvailable"
 )
@@ -162,6 +165,26 @@ type RolloutWebhook struct {
 	// Metadata (key-value pairs) for this webhook
 	// +optional
 	Metadata *map[string]string `json:"metadata,omitempty"`
+
+	// SecretRef selects the key of a Secret in the same namespace that holds the shared secret.
+	// The request body is signed with it using HMAC-SHA256 and the signature is sent in the X-Rollout-Signature header
+	// +optional
+	SecretRef *corev1.SecretKeySelector `json:"secretRef,omitempty"`
+
+	// Retry defines how a failed call is retried
+	// +optional
+	Retry *RolloutWebhookRetry `json:"retry,omitempty"`
+}
+
+// RolloutWebhookRetry defines how a failed webhook call is retried with an exponential backoff
+type RolloutWebhookRetry struct {
+	// Attempts is the max number of times to call the webhook, default is 3
+	// +optional
+	Attempts *int32 `json:"attempts,omitempty"`
+
+	// Backoff is the time to wait before the first retry, it doubles after each retry up to 5m, default is 1s
+	// +optional
+	Backoff string `json:"backoff,omitempty"`
 }
 
 // RolloutWebhookPayload holds the info and metadata sent to webhooks
@@ -172,10 +195,50 @@ type RolloutWebhookPayload struct {
 	// RolloutRef refers to the rollout that is controlling the rollout
 	RolloutRef *runtimev1alpha1.TypedReference `json:"rolloutRef"`
 
+	// Type is the type of the hook that is called
+	Type HookType `json:"type,omitempty"`
+
+	// Batch is the batch the hook is called for, it's only meaningful for the batch hooks
+	Batch int32 `json:"batch"`
+
 	// Metadata (key-value pairs) are the extra data send to this webhook
 	Metadata map[string]string `json:"metadata,omitempty"`
 }
 
+// RolloutWebhookResult records the result of a webhook invocation
+type RolloutWebhookResult struct {
+	// Name of the webhook
+	Name string `json:"name"`
+
+	// Type of the webhook
+	Type HookType `json:"type"`
+
+	// Batch is the batch the webhook is called for
+	Batch int32 `json:"batch"`
+
+	// Succeeded is true if the webhook returned a 2xx status code
+	Succeeded bool `json:"succeeded"`
+
+	// StatusCode is the status code of the last response, it's 0 if no response is received
+	// +optional
+	StatusCode int `json:"statusCode,omitempty"`
+
+	// Attempts is the number of times the webhook is called, it's not called again once all the attempts are used up
+	Attempts int32 `json:"attempts"`
+
+	// Message explains why the call failed
+	// +optional
+	Message string `json:"message,omitempty"`
+
+	// LastCallTime is the last time the webhook is called
+	LastCallTime metav1.Time `json:"lastCallTime"`
+
+	// NextRetryTime is the time to call the failed webhook again, it's not set if the webhook has succeeded or
+	// all the attempts are used up
+	// +optional
+	NextRetryTime *metav1.Time `json:"nextRetryTime,omitempty"`
+}
+
 // CanaryMetric holds the reference to metrics used for canary analysis
 type CanaryMetric struct {
 	// Name of the metric
@@ -188,7 +251,8 @@ type CanaryMetric struct {
 	// +optional
 	MetricsRange *MetricsExpectedRange `json:"metricsRange,omitempty"`
 
-	// TemplateRef references a metric template object
+	// TemplateRef references a metric template object, it's a ConfigMap that contains the `query` to run
+	// and the `address` of the metric `provider`
 	// +optional
 	TemplateRef *runtimev1alpha1.TypedReference `json:"templateRef,omitempty"`
 }
@@ -204,6 +268,29 @@ type MetricsExpectedRange struct {
 	Max *intstr.IntOrString `json:"max,omitempty"`
 }
 
+// CanaryMetricResult records the value observed by a canary metric check
+type CanaryMetricResult struct {
+	// Name of the metric
+	Name string `json:"name"`
+
+	// Batch is the batch the metric is checked at, it's -1 if the metric is checked before completing the rollout
+	Batch int32 `json:"batch"`
+
+	// Value is the observed value of the metric
+	// +optional
+	Value string `json:"value,omitempty"`
+
+	// Passed is true if the value is in the expected range
+	Passed bool `json:"passed"`
+
+	// Message explains why the check didn't pass
+	// +optional
+	Message string `json:"message,omitempty"`
+
+	// LastCheckTime is the last time the metric is checked
+	LastCheckTime metav1.Time `json:"lastCheckTime"`
+}
+
 // RolloutStatus defines the observed state of Rollout
 type RolloutStatus struct {
 	// Conditions represents the latest available observations of a CloneSet's current state.
@@ -222,6 +309,10 @@ type RolloutStatus struct {
 	// +optional
 	BatchRollingState BatchRollingState `json:"batchRollingState"`
 
+	// RolloutTargetSize is the size of the target resources. This is determined once the initial spec verification
+	// and does not change until the rollout is restarted
+	RolloutTargetSize int32 `json:"rolloutTargetSize,omitempty"`
+
 	// The current batch the rollout is working on/blocked
 	CurrentBatch int32 `json:"currentBatch"`
 
@@ -230,4 +321,12 @@ type RolloutStatus struct {
 
 	// UpgradedReplicas is the number of Pods upgraded by the rollout controller that have a Ready Condition.
 	UpgradedReadyReplicas int32 `json:"upgradedReadyReplicas"`
+
+	// CanaryMetricResults records the latest value observed by each canary metric
+	// +optional
+	CanaryMetricResults []CanaryMetricResult `json:"canaryMetricResults,omitempty"`
+
+	// WebhookResults records the latest invocation result of each webhook
+	// +optional
+	WebhookResults []RolloutWebhookResult `json:"webhookResults,omitempty"`
 }
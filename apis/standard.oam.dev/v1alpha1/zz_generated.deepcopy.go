@@ -22,6 +22,7 @@ package v1alpha1
 
 import (
 	corev1alpha1 "github.com/crossplane/crossplane-runtime/apis/core/v1alpha1"
+	"k8s.io/api/core/v1"
 	runtime "k8s.io/apimachinery/pkg/runtime"
 	"k8s.io/apimachinery/pkg/util/intstr"
 )
@@ -196,6 +197,22 @@ func (in *CanaryMetric) DeepCopy() *CanaryMetric {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *CanaryMetricResult) DeepCopyInto(out *CanaryMetricResult) {
+	*out = *in
+	in.LastCheckTime.DeepCopyInto(&out.LastCheckTime)
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new CanaryMetricResult.
+func (in *CanaryMetricResult) DeepCopy() *CanaryMetricResult {
+	if in == nil {
+		return nil
+	}
+	out := new(CanaryMetricResult)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *MetricsExpectedRange) DeepCopyInto(out *MetricsExpectedRange) {
 	*out = *in
@@ -527,6 +544,20 @@ func (in *RolloutPlan) DeepCopy() *RolloutPlan {
 func (in *RolloutStatus) DeepCopyInto(out *RolloutStatus) {
 	*out = *in
 	in.ConditionedStatus.DeepCopyInto(&out.ConditionedStatus)
+	if in.CanaryMetricResults != nil {
+		in, out := &in.CanaryMetricResults, &out.CanaryMetricResults
+		*out = make([]CanaryMetricResult, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	if in.WebhookResults != nil {
+		in, out := &in.WebhookResults, &out.WebhookResults
+		*out = make([]RolloutWebhookResult, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
 }
 
 // DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new RolloutStatus.
@@ -634,6 +665,16 @@ func (in *RolloutWebhook) DeepCopyInto(out *RolloutWebhook) {
 			}
 		}
 	}
+	if in.SecretRef != nil {
+		in, out := &in.SecretRef, &out.SecretRef
+		*out = new(v1.SecretKeySelector)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.Retry != nil {
+		in, out := &in.Retry, &out.Retry
+		*out = new(RolloutWebhookRetry)
+		(*in).DeepCopyInto(*out)
+	}
 }
 
 // DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new RolloutWebhook.
@@ -678,6 +719,46 @@ func (in *RolloutWebhookPayload) DeepCopy() *RolloutWebhookPayload {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *RolloutWebhookResult) DeepCopyInto(out *RolloutWebhookResult) {
+	*out = *in
+	in.LastCallTime.DeepCopyInto(&out.LastCallTime)
+	if in.NextRetryTime != nil {
+		in, out := &in.NextRetryTime, &out.NextRetryTime
+		*out = (*in).DeepCopy()
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new RolloutWebhookResult.
+func (in *RolloutWebhookResult) DeepCopy() *RolloutWebhookResult {
+	if in == nil {
+		return nil
+	}
+	out := new(RolloutWebhookResult)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *RolloutWebhookRetry) DeepCopyInto(out *RolloutWebhookRetry) {
+	*out = *in
+	if in.Attempts != nil {
+		in, out := &in.Attempts, &out.Attempts
+		*out = new(int32)
+		**out = **in
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new RolloutWebhookRetry.
+func (in *RolloutWebhookRetry) DeepCopy() *RolloutWebhookRetry {
+	if in == nil {
+		return nil
+	}
+	out := new(RolloutWebhookRetry)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *Route) DeepCopyInto(out *Route) {
 	*out = *in
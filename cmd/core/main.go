@@ -9,6 +9,7 @@ import (
 	"os/signal"
 	"path/filepath"
 	"strconv"
+	"strings"
 	"syscall"
 	"time"
 
@@ -76,6 +77,7 @@ func main() {
 	var controllerArgs oamcontroller.Args
 	var healthAddr string
 	var disableCaps string
+	var readableNamespaces string
 
 	flag.BoolVar(&useWebhook, "use-webhook", false, "Enable Admission Webhook")
 	flag.BoolVar(&useTraitInjector, "use-trait-injector", false, "Enable TraitInjector")
@@ -91,13 +93,20 @@ func main() {
 	flag.BoolVar(&logCompress, "log-compress", true, "Enable compression on the rotated logs.")
 	flag.IntVar(&controllerArgs.RevisionLimit, "revision-limit", 50,
 		"RevisionLimit is the maximum number of revisions that will be maintained. The default value is 50.")
+	flag.IntVar(&controllerArgs.AppRevisionLimit, "application-revision-limit", 10,
+		"AppRevisionLimit is the maximum number of application revisions that will be maintained for each application. The default value is 10.")
 	flag.StringVar(&healthAddr, "health-addr", ":9440", "The address the health endpoint binds to.")
 	flag.BoolVar(&controllerArgs.ApplyOnceOnly, "apply-once-only", false,
 		"For the purpose of some production environment that workload or trait should not be affected if no spec change")
 	flag.StringVar(&controllerArgs.CustomRevisionHookURL, "custom-revision-hook-url", "",
 		"custom-revision-hook-url is a webhook url which will let KubeVela core to call with applicationConfiguration and component info and return a customized component revision")
 	flag.StringVar(&disableCaps, "disable-caps", "", "To be disabled builtin capability list.")
+	flag.StringVar(&readableNamespaces, "template-readable-namespaces", "",
+		"The comma separated namespaces that the templates can read objects from besides the namespace of the application.")
 	flag.Parse()
+	if readableNamespaces != "" {
+		controllerArgs.ReadableNamespaces = strings.Split(readableNamespaces, ",")
+	}
 
 	// setup logging
 	var w io.Writer
@@ -176,11 +176,14 @@ func addDefaultHealthScopeToApplication(app *v1alpha2.Application) *v1alpha2.Hea
 	}
 	health.Name = FormatDefaultHealthScopeName(app.Name)
 	health.Namespace = app.Namespace
+	// the label marks the health scope belongs to the app so that it's deleted with the app
+	health.Labels = map[string]string{"application.oam.dev": app.Name}
 	health.Spec.WorkloadReferences = make([]v1alpha1.TypedReference, 0)
-	for i := range app.Spec.Components {
-		// FIXME(wonderflow): the hardcode health scope should be fixed.
-		app.Spec.Components[i].Scopes = map[string]string{"healthscopes.core.oam.dev": health.Name}
-	}
+	// FIXME(wonderflow): the hardcode health scope should be fixed.
+	app.Spec.Scopes = append(app.Spec.Scopes, v1alpha2.ApplicationScope{
+		Name: health.Name,
+		Type: "healthscopes.core.oam.dev",
+	})
 	return health
 }
 
@@ -65,9 +65,9 @@ func TestBuildOAMApplication2(t *testing.T) {
 							Settings: runtime.RawExtension{
 								Raw: []byte("{\"image\":\"busybox\"}"),
 							},
-							Scopes: map[string]string{"healthscopes.core.oam.dev": "test-default-health"},
 						},
 					},
+					Scopes: []v1alpha2.ApplicationScope{{Name: "test-default-health", Type: "healthscopes.core.oam.dev"}},
 				},
 			},
 		},
@@ -99,7 +99,6 @@ func TestBuildOAMApplication2(t *testing.T) {
 							Settings: runtime.RawExtension{
 								Raw: []byte("{\"image\":\"busybox\"}"),
 							},
-							Scopes: map[string]string{"healthscopes.core.oam.dev": "test-default-health"},
 							Traits: []v1alpha2.ApplicationTrait{
 								{
 									Name: "scaler",
@@ -110,6 +109,7 @@ func TestBuildOAMApplication2(t *testing.T) {
 							},
 						},
 					},
+					Scopes: []v1alpha2.ApplicationScope{{Name: "test-default-health", Type: "healthscopes.core.oam.dev"}},
 				},
 			},
 		},
@@ -277,7 +277,6 @@ outputs: ingress: {
 			Components: []v1alpha2.ApplicationComponent{{
 				WorkloadType: "webservice",
 				Name:         "express-server",
-				Scopes:       map[string]string{"healthscopes.core.oam.dev": "myapp-default-health"},
 				Settings: runtime.RawExtension{
 					Raw: []byte(`{"image": "oamdev/testapp:v1", "cmd": ["node", "server.js"]}`),
 				},
@@ -289,6 +288,7 @@ outputs: ingress: {
 				},
 				},
 			}},
+			Scopes: []v1alpha2.ApplicationScope{{Name: "myapp-default-health", Type: "healthscopes.core.oam.dev"}},
 		},
 	}
 	ac2 := ac1.DeepCopy()
@@ -299,7 +299,6 @@ outputs: ingress: {
 			Raw: []byte(`{"image":"bitnami/mongodb:3.6.20","cmd": ["mongodb"]}`),
 		},
 		Traits: []v1alpha2.ApplicationTrait{},
-		Scopes: map[string]string{"healthscopes.core.oam.dev": "myapp-default-health"},
 	})
 
 	ac3 := ac1.DeepCopy()
@@ -328,6 +327,7 @@ outputs: ingress: {
 	}
 	health.Name = FormatDefaultHealthScopeName("myapp")
 	health.Namespace = "default"
+	health.Labels = map[string]string{"application.oam.dev": "myapp"}
 	health.Spec.WorkloadReferences = make([]v1alpha1.TypedReference, 0)
 	type args struct {
 		appfileData       string
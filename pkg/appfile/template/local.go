@@ -0,0 +1,189 @@
+package template
+
+import (
+	"bytes"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"sigs.k8s.io/yaml"
+
+	"github.com/oam-dev/kubevela/apis/core.oam.dev/v1alpha2"
+	"github.com/oam-dev/kubevela/apis/types"
+)
+
+// LocalDefinition is a definition loaded from the local files
+type LocalDefinition struct {
+	Name string
+	// Type is empty for a CUE file without a definition, it's used as either a workload type or a trait
+	Type     types.CapType
+	Template string
+	Health   string
+	// File is the file that the template is loaded from
+	File string
+}
+
+// LocalManager manages the definitions loaded from the local files, it renders applications without a cluster
+type LocalManager struct {
+	Definitions map[string]*LocalDefinition
+}
+
+// LoadLocalDefinitions loads the WorkloadDefinitions and TraitDefinitions in the YAML files and the templates in the
+// CUE files under the directory. A definition without a template takes the template of the CUE file with the same
+// base name, e.g. `definitions/webservice.yaml` and `cue/webservice.cue` of hack/vela-templates, and any other CUE
+// file is a definition named by its base name.
+func LoadLocalDefinitions(dir string) (*LocalManager, error) {
+	m := &LocalManager{Definitions: map[string]*LocalDefinition{}}
+	cueFiles := map[string]string{}
+	var defs []*LocalDefinition
+	err := filepath.Walk(dir, func(path string, info os.FileInfo, err error) error {
+		if err != nil || info.IsDir() {
+			return err
+		}
+		switch filepath.Ext(path) {
+		case ".cue":
+			cueFiles[baseName(path)] = path
+		case ".yaml", ".yml":
+			fileDefs, err := loadDefinitionFile(path)
+			if err != nil {
+				return err
+			}
+			defs = append(defs, fileDefs...)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	paired := map[string]bool{}
+	for _, def := range defs {
+		if def.Template == "" {
+			if path, ok := cueFiles[baseName(def.File)]; ok {
+				paired[path] = true
+				if def.Template, err = readFile(path); err != nil {
+					return nil, err
+				}
+				def.File = path
+			}
+		}
+		if def.Template == "" {
+			return nil, fmt.Errorf("no template found in %s %s of %s", def.Type, def.Name, def.File)
+		}
+		if err := m.add(def); err != nil {
+			return nil, err
+		}
+	}
+	for name, path := range cueFiles {
+		if paired[path] {
+			continue
+		}
+		templ, err := readFile(path)
+		if err != nil {
+			return nil, err
+		}
+		if err := m.add(&LocalDefinition{Name: name, Template: templ, File: path}); err != nil {
+			return nil, err
+		}
+	}
+	return m, nil
+}
+
+func (m *LocalManager) add(def *LocalDefinition) error {
+	if existing, ok := m.Definitions[def.Name]; ok {
+		return fmt.Errorf("definition %s is defined in both %s and %s", def.Name, existing.File, def.File)
+	}
+	m.Definitions[def.Name] = def
+	return nil
+}
+
+// loadDefinitionFile loads the definitions in a YAML file, the objects of the other kinds are ignored
+func loadDefinitionFile(path string) ([]*LocalDefinition, error) {
+	data, err := ioutil.ReadFile(filepath.Clean(path))
+	if err != nil {
+		return nil, err
+	}
+	var defs []*LocalDefinition
+	for _, doc := range bytes.Split(data, []byte("\n---")) {
+		obj := map[string]interface{}{}
+		if err := yaml.Unmarshal(doc, &obj); err != nil {
+			return nil, fmt.Errorf("decode %s: %w", path, err)
+		}
+		u := unstructured.Unstructured{Object: obj}
+		var capType types.CapType
+		switch u.GetKind() {
+		case v1alpha2.WorkloadDefinitionKind:
+			capType = types.TypeWorkload
+		case v1alpha2.TraitDefinitionKind:
+			capType = types.TypeTrait
+		default:
+			continue
+		}
+		templ, _, _ := unstructured.NestedString(obj, "spec", "extension", "template")
+		health, _, _ := unstructured.NestedString(obj, "spec", "extension", "healthPolicy")
+		defs = append(defs, &LocalDefinition{Name: u.GetName(), Type: capType, Template: templ, Health: health,
+			File: path})
+	}
+	return defs, nil
+}
+
+func readFile(path string) (string, error) {
+	data, err := ioutil.ReadFile(filepath.Clean(path))
+	if err != nil {
+		return "", err
+	}
+	return string(data), nil
+}
+
+func baseName(path string) string {
+	return strings.TrimSuffix(filepath.Base(path), filepath.Ext(path))
+}
+
+// IsTrait checks whether the key of a service in the Appfile is a trait
+func (m *LocalManager) IsTrait(key string) bool {
+	def, ok := m.Definitions[key]
+	return ok && def.Type != types.TypeWorkload
+}
+
+// LoadTemplate returns the template of the definition
+func (m *LocalManager) LoadTemplate(key string) string {
+	if def, ok := m.Definitions[key]; ok {
+		return def.Template
+	}
+	return ""
+}
+
+// Get returns the definition used as the type, it returns false if there is no such definition
+func (m *LocalManager) Get(name string, capType types.CapType) (*LocalDefinition, bool) {
+	def, ok := m.Definitions[name]
+	if !ok || (def.Type != "" && def.Type != capType) {
+		return nil, false
+	}
+	return def, true
+}
+
+// Revision returns an ApplicationRevision with the templates of the definitions, so that an application parser
+// renders with the local definitions instead of the definitions in the cluster
+func (m *LocalManager) Revision() *v1alpha2.ApplicationRevision {
+	rev := &v1alpha2.ApplicationRevision{}
+	names := make([]string, 0, len(m.Definitions))
+	for name := range m.Definitions {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		def := m.Definitions[name]
+		t := v1alpha2.DefinitionTemplate{Name: def.Name, Template: def.Template, Health: def.Health}
+		if def.Type != types.TypeTrait {
+			rev.Spec.WorkloadTemplates = append(rev.Spec.WorkloadTemplates, t)
+		}
+		if def.Type != types.TypeWorkload {
+			rev.Spec.TraitTemplates = append(rev.Spec.TraitTemplates, t)
+		}
+	}
+	return rev
+}
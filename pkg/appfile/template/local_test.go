@@ -0,0 +1,76 @@
+package template
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/oam-dev/kubevela/apis/core.oam.dev/v1alpha2"
+	"github.com/oam-dev/kubevela/apis/types"
+)
+
+func TestLoadLocalDefinitions(t *testing.T) {
+	dir, err := ioutil.TempDir("", "definitions")
+	assert.NoError(t, err)
+	defer os.RemoveAll(dir)
+	for name, content := range map[string]string{
+		// the template of the trait is in the CUE file with the same base name
+		"definitions/manualscale.yaml": `apiVersion: core.oam.dev/v1alpha2
+kind: TraitDefinition
+metadata:
+  name: scaler
+spec:
+  extension:
+    template: ""`,
+		"cue/manualscale.cue": `output: spec: replicaCount: parameter.replicas`,
+		"worker.yaml": `apiVersion: core.oam.dev/v1alpha2
+kind: WorkloadDefinition
+metadata:
+  name: worker
+spec:
+  extension:
+    healthPolicy: "isHealth: true"
+    template: |
+      output: kind: "Deployment"
+---
+apiVersion: v1
+kind: ConfigMap
+metadata:
+  name: ignored`,
+		"cue/sidecar.cue": `patch: spec: containers: [{name: "sidecar"}]`,
+	} {
+		path := filepath.Join(dir, name)
+		assert.NoError(t, os.MkdirAll(filepath.Dir(path), 0750))
+		assert.NoError(t, ioutil.WriteFile(path, []byte(content), 0600))
+	}
+
+	m, err := LoadLocalDefinitions(dir)
+	assert.NoError(t, err)
+	assert.Equal(t, 3, len(m.Definitions))
+	assert.True(t, m.IsTrait("scaler"))
+	assert.True(t, m.IsTrait("sidecar"))
+	assert.False(t, m.IsTrait("worker"))
+	assert.Equal(t, `output: spec: replicaCount: parameter.replicas`, m.LoadTemplate("scaler"))
+	_, ok := m.Get("worker", types.TypeTrait)
+	assert.False(t, ok)
+
+	// the CUE file without a definition is used as either type
+	assert.Equal(t, &v1alpha2.ApplicationRevision{Spec: v1alpha2.ApplicationRevisionSpec{
+		WorkloadTemplates: []v1alpha2.DefinitionTemplate{
+			{Name: "sidecar", Template: `patch: spec: containers: [{name: "sidecar"}]`},
+			{Name: "worker", Template: `output: kind: "Deployment"`, Health: "isHealth: true"},
+		},
+		TraitTemplates: []v1alpha2.DefinitionTemplate{
+			{Name: "scaler", Template: `output: spec: replicaCount: parameter.replicas`},
+			{Name: "sidecar", Template: `patch: spec: containers: [{name: "sidecar"}]`},
+		},
+	}}, m.Revision())
+
+	// a definition must have a template
+	assert.NoError(t, os.Remove(filepath.Join(dir, "cue/manualscale.cue")))
+	_, err = LoadLocalDefinitions(dir)
+	assert.EqualError(t, err, "no template found in trait scaler of "+filepath.Join(dir, "definitions/manualscale.yaml"))
+}
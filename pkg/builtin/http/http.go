@@ -48,6 +48,7 @@ func (c *HTTPCmd) Run(meta *registry.Meta) (res interface{}, err error) {
 		}
 	}
 	if header == nil {
+		header = http.Header{}
 		header.Set("Content-Type", "application/json")
 	}
 	if meta.Err != nil {
@@ -0,0 +1,162 @@
+package kube
+
+import (
+	"context"
+	"encoding/base64"
+	"fmt"
+
+	"cuelang.org/go/cue"
+	"github.com/pkg/errors"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	ctypes "k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	"github.com/oam-dev/kubevela/pkg/builtin/registry"
+)
+
+func init() {
+	registry.RegisterRunner("kube", newKubeCmd)
+	registry.RegisterRunner("secret", newSecretCmd)
+	registry.RegisterRunner("configmap", newConfigMapCmd)
+}
+
+// KubeCmd reads an object in the cluster, the result is the object
+type KubeCmd struct{}
+
+func newKubeCmd(_ cue.Value) (registry.Runner, error) {
+	return &KubeCmd{}, nil
+}
+
+// Run gets the object by `apiVersion`, `kind`, `name` and the optional `namespace`
+func (c *KubeCmd) Run(meta *registry.Meta) (interface{}, error) {
+	var (
+		apiVersion = meta.String("apiVersion")
+		kind       = meta.String("kind")
+		name       = meta.String("name")
+	)
+	if meta.Err != nil {
+		return nil, meta.Err
+	}
+	obj, err := get(meta, apiVersion, kind, name)
+	if err != nil {
+		return nil, err
+	}
+	return obj.Object, nil
+}
+
+// SecretCmd reads a key of a Secret, the result is the decoded value
+type SecretCmd struct{}
+
+func newSecretCmd(_ cue.Value) (registry.Runner, error) {
+	return &SecretCmd{}, nil
+}
+
+// Run gets the value of `key` in the Secret by `name` and the optional `namespace`
+func (c *SecretCmd) Run(meta *registry.Meta) (interface{}, error) {
+	value, err := getKey(meta, "Secret")
+	if err != nil {
+		return nil, err
+	}
+	decoded, err := base64.StdEncoding.DecodeString(value)
+	if err != nil {
+		return nil, errors.Wrapf(err, "decode key %s of Secret %s", meta.String("key"), meta.String("name"))
+	}
+	return string(decoded), nil
+}
+
+// ConfigMapCmd reads a key of a ConfigMap, the result is the value
+type ConfigMapCmd struct{}
+
+func newConfigMapCmd(_ cue.Value) (registry.Runner, error) {
+	return &ConfigMapCmd{}, nil
+}
+
+// Run gets the value of `key` in the ConfigMap by `name` and the optional `namespace`
+func (c *ConfigMapCmd) Run(meta *registry.Meta) (interface{}, error) {
+	return getKey(meta, "ConfigMap")
+}
+
+// getKey gets the value of a key in the data of a Secret or ConfigMap, they're read as unstructured objects so that
+// the client reads them from the API server rather than caching all of them
+func getKey(meta *registry.Meta, kind string) (string, error) {
+	var (
+		name = meta.String("name")
+		key  = meta.String("key")
+	)
+	if meta.Err != nil {
+		return "", meta.Err
+	}
+	obj, err := get(meta, "v1", kind, name)
+	if err != nil {
+		return "", err
+	}
+	value, found, err := unstructured.NestedString(obj.Object, "data", key)
+	if err != nil {
+		return "", errors.Wrapf(err, "get key %s of %s %s", key, kind, name)
+	}
+	if !found {
+		return "", fmt.Errorf("there is no key %s in %s %s", key, kind, name)
+	}
+	return value, nil
+}
+
+func get(meta *registry.Meta, apiVersion, kind, name string) (*unstructured.Unstructured, error) {
+	if meta.Client == nil {
+		return nil, fmt.Errorf("can't get %s %s without the access to the cluster", kind, name)
+	}
+	namespace := meta.Namespace
+	if v := meta.Obj.Lookup("namespace"); v.Exists() {
+		ns, err := v.String()
+		if err != nil {
+			return nil, fmt.Errorf("invalid string argument, %w", err)
+		}
+		namespace = ns
+	}
+	ctx := meta.Context
+	if ctx == nil {
+		ctx = context.Background()
+	}
+	obj := &unstructured.Unstructured{}
+	obj.SetAPIVersion(apiVersion)
+	obj.SetKind(kind)
+	if err := meta.Client.Get(ctx, ctypes.NamespacedName{Namespace: namespace, Name: name}, obj); err != nil {
+		return nil, errors.Wrapf(err, "get %s %s", kind, name)
+	}
+	return obj, nil
+}
+
+// NamespacedReader returns a reader that only reads the objects in the namespace and the allowed namespaces, the
+// controller reads the cluster with it for the templates so that they can't read the Secrets of other namespaces
+// with the permissions of the controller. It returns nil if the client is nil.
+func NamespacedReader(c client.Reader, namespace string, allowed ...string) client.Reader {
+	if c == nil {
+		return nil
+	}
+	namespaces := map[string]bool{namespace: true}
+	for _, ns := range allowed {
+		namespaces[ns] = true
+	}
+	return &namespacedReader{Reader: c, namespaces: namespaces}
+}
+
+type namespacedReader struct {
+	client.Reader
+	namespaces map[string]bool
+}
+
+func (r *namespacedReader) Get(ctx context.Context, key client.ObjectKey, obj runtime.Object) error {
+	if !r.namespaces[key.Namespace] {
+		return fmt.Errorf("namespace %s isn't readable for the application", key.Namespace)
+	}
+	return r.Reader.Get(ctx, key, obj)
+}
+
+func (r *namespacedReader) List(ctx context.Context, list runtime.Object, opts ...client.ListOption) error {
+	listOpts := &client.ListOptions{}
+	listOpts.ApplyOptions(opts)
+	if !r.namespaces[listOpts.Namespace] {
+		return fmt.Errorf("namespace %s isn't readable for the application", listOpts.Namespace)
+	}
+	return r.Reader.List(ctx, list, opts...)
+}
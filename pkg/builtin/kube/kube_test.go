@@ -0,0 +1,102 @@
+package kube
+
+import (
+	"testing"
+
+	"cuelang.org/go/cue"
+	"github.com/stretchr/testify/assert"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	clientgoscheme "k8s.io/client-go/kubernetes/scheme"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+
+	"github.com/oam-dev/kubevela/pkg/builtin/registry"
+)
+
+func TestRun(t *testing.T) {
+	scheme := runtime.NewScheme()
+	assert.NoError(t, clientgoscheme.AddToScheme(scheme))
+	c := fake.NewFakeClientWithScheme(scheme,
+		&corev1.Service{ObjectMeta: metav1.ObjectMeta{Name: "ingress", Namespace: "vela-system"},
+			Status: corev1.ServiceStatus{LoadBalancer: corev1.LoadBalancerStatus{
+				Ingress: []corev1.LoadBalancerIngress{{IP: "10.0.0.1"}}}}},
+		&corev1.Secret{ObjectMeta: metav1.ObjectMeta{Name: "db", Namespace: "default"},
+			Data: map[string][]byte{"password": []byte("secret")}},
+		&corev1.ConfigMap{ObjectMeta: metav1.ObjectMeta{Name: "db", Namespace: "default"},
+			Data: map[string]string{"host": "db.default"}})
+
+	testCases := map[string]struct {
+		key    string
+		obj    string
+		client bool
+		want   interface{}
+		err    string
+	}{
+		"kube": {
+			key:    "kube",
+			obj:    `{apiVersion: "v1", kind: "Service", name: "ingress", namespace: "vela-system"}`,
+			client: true,
+			want:   "10.0.0.1",
+		},
+		"secret": {
+			key:    "secret",
+			obj:    `{name: "db", key: "password"}`,
+			client: true,
+			want:   "secret",
+		},
+		"configmap": {
+			key:    "configmap",
+			obj:    `{name: "db", key: "host"}`,
+			client: true,
+			want:   "db.default",
+		},
+		"missing key": {
+			key:    "configmap",
+			obj:    `{name: "db", key: "port"}`,
+			client: true,
+			err:    "there is no key port in ConfigMap db",
+		},
+		"not found": {
+			key:    "secret",
+			obj:    `{name: "db", namespace: "vela-system", key: "password"}`,
+			client: true,
+			err:    `get Secret db: secrets "db" not found`,
+		},
+		"namespace not allowed": {
+			key:    "secret",
+			obj:    `{name: "db", namespace: "kube-system", key: "password"}`,
+			client: true,
+			err:    "get Secret db: namespace kube-system isn't readable for the application",
+		},
+		"no cluster": {
+			key: "secret",
+			obj: `{name: "db", key: "password"}`,
+			err: "can't get Secret db without the access to the cluster",
+		},
+	}
+	for name, tc := range testCases {
+		t.Run(name, func(t *testing.T) {
+			var r cue.Runtime
+			inst, err := r.Compile("-", tc.obj)
+			assert.NoError(t, err)
+			meta := &registry.Meta{Obj: inst.Value(), Namespace: "default"}
+			if tc.client {
+				meta.Client = NamespacedReader(c, "default", "vela-system")
+			}
+			runner, err := registry.LookupRunner(tc.key)(inst.Value())
+			assert.NoError(t, err)
+			got, err := runner.Run(meta)
+			if tc.err != "" {
+				assert.EqualError(t, err, tc.err)
+				return
+			}
+			assert.NoError(t, err)
+			if obj, ok := got.(map[string]interface{}); ok {
+				ingress := obj["status"].(map[string]interface{})["loadBalancer"].(map[string]interface{})["ingress"]
+				got = ingress.([]interface{})[0].(map[string]interface{})["ip"]
+			}
+			assert.Equal(t, tc.want, got)
+		})
+	}
+}
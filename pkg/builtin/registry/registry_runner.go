@@ -8,6 +8,7 @@ import (
 
 	"cuelang.org/go/cue"
 	"cuelang.org/go/cue/errors"
+	"sigs.k8s.io/controller-runtime/pkg/client"
 )
 
 // Meta provides context for running a task.
@@ -18,6 +19,11 @@ type Meta struct {
 	Stderr  io.Writer
 	Obj     cue.Value
 	Err     error
+
+	// Client reads the cluster state for the tasks such as reading a Secret, it's nil if the cluster isn't available
+	Client client.Reader
+	// Namespace is the namespace of the objects that the tasks read if they don't specify one
+	Namespace string
 }
 
 // LookupRunner fetch the value of context by filed
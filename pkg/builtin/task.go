@@ -1,13 +1,14 @@
 package builtin
 
 import (
-	"errors"
+	"fmt"
 
 	"cuelang.org/go/cue"
 
 	// RegisterRunner all build jobs here, so the jobs will automatically registered before RunBuildInTasks run.
 	_ "github.com/oam-dev/kubevela/pkg/builtin/build"
 	_ "github.com/oam-dev/kubevela/pkg/builtin/http"
+	_ "github.com/oam-dev/kubevela/pkg/builtin/kube"
 
 	"github.com/oam-dev/kubevela/pkg/builtin/registry"
 	cmdutil "github.com/oam-dev/kubevela/pkg/commands/util"
@@ -23,7 +24,7 @@ func RunBuildInTasks(spec map[string]interface{}, io cmdutil.IOStreams) (map[str
 func RunTaskByKey(key string, v cue.Value, meta *registry.Meta) (interface{}, error) {
 	task := registry.LookupRunner(key)
 	if task == nil {
-		return nil, errors.New("there is no http task in task registry")
+		return nil, fmt.Errorf("there is no %s task in task registry", key)
 	}
 	runner, err := task(v)
 	if err != nil {
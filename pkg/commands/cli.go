@@ -64,6 +64,9 @@ func NewCommand() *cobra.Command {
 		NewDeleteCommand(commandArgs, ioStream),
 		NewAppShowCommand(commandArgs, ioStream),
 		NewAppStatusCommand(commandArgs, ioStream),
+		NewRolloutCommand(commandArgs, ioStream),
+		NewAppHistoryCommand(commandArgs, ioStream),
+		NewAppRollbackCommand(commandArgs, ioStream),
 		NewExecCommand(commandArgs, ioStream),
 		NewPortForwardCommand(commandArgs, ioStream),
 		NewLogsCommand(commandArgs, ioStream),
@@ -73,6 +76,7 @@ func NewCommand() *cobra.Command {
 		// Capabilities
 		CapabilityCommandGroup(commandArgs, ioStream),
 		NewTemplateCommand(ioStream),
+		DefinitionCommandGroup(ioStream),
 		NewTraitsCommand(commandArgs, ioStream),
 		NewWorkloadsCommand(commandArgs, ioStream),
 
@@ -0,0 +1,238 @@
+package commands
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/gosuri/uitable"
+	"github.com/spf13/cobra"
+
+	"github.com/oam-dev/kubevela/apis/types"
+	"github.com/oam-dev/kubevela/pkg/appfile/template"
+	cmdutil "github.com/oam-dev/kubevela/pkg/commands/util"
+	"github.com/oam-dev/kubevela/pkg/controller/core.oam.dev/v1alpha2/application"
+	"github.com/oam-dev/kubevela/pkg/dsl/definition"
+	"github.com/oam-dev/kubevela/pkg/dsl/model"
+	"github.com/oam-dev/kubevela/pkg/serverlib"
+)
+
+// DefinitionCommandGroup creates `def` command and its nested children command
+func DefinitionCommandGroup(ioStream cmdutil.IOStreams) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "def",
+		Short: "Develop definitions locally",
+		Long:  "Develop workload and trait definitions with the local definition files, no cluster is needed",
+		Annotations: map[string]string{
+			types.TagCommandType: types.TypeCap,
+		},
+	}
+	cmd.SetOut(ioStream.Out)
+	cmd.AddCommand(
+		NewDefinitionRenderCommand(ioStream),
+		NewDefinitionExplainCommand(ioStream),
+		NewDefinitionTestCommand(ioStream),
+	)
+	return cmd
+}
+
+// NewDefinitionRenderCommand creates `def render` command
+func NewDefinitionRenderCommand(ioStream cmdutil.IOStreams) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:     "render",
+		Short:   "Render an application with the local definitions",
+		Long:    "Render an Application or Appfile with the definition YAML and CUE files in a directory, and print the rendered Components and ApplicationConfiguration",
+		Example: `vela def render -f vela.yaml -d hack/vela-templates`,
+		Annotations: map[string]string{
+			types.TagCommandType: types.TypeCap,
+		},
+		RunE: func(cmd *cobra.Command, args []string) error {
+			appFile, err := cmd.Flags().GetString("file")
+			if err != nil {
+				return err
+			}
+			defDir, err := cmd.Flags().GetString("definitions")
+			if err != nil {
+				return err
+			}
+			namespace, err := cmd.Flags().GetString("namespace")
+			if err != nil {
+				return err
+			}
+			return renderOffline(appFile, defDir, namespace, false, ioStream)
+		},
+	}
+	cmd.SetOut(ioStream.Out)
+	addOfflineFlags(cmd)
+	return cmd
+}
+
+// NewDefinitionExplainCommand creates `def explain` command
+func NewDefinitionExplainCommand(ioStream cmdutil.IOStreams) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:     "explain",
+		Short:   "Explain which definitions render the fields of an application",
+		Long:    "Render an Application or Appfile with the local definitions, and print the definition and parameter that set each field of the workloads and traits",
+		Example: `vela def explain -f vela.yaml -d hack/vela-templates`,
+		Annotations: map[string]string{
+			types.TagCommandType: types.TypeCap,
+		},
+		RunE: func(cmd *cobra.Command, args []string) error {
+			appFile, err := cmd.Flags().GetString("file")
+			if err != nil {
+				return err
+			}
+			defDir, err := cmd.Flags().GetString("definitions")
+			if err != nil {
+				return err
+			}
+			namespace, err := cmd.Flags().GetString("namespace")
+			if err != nil {
+				return err
+			}
+			return renderOffline(appFile, defDir, namespace, true, ioStream)
+		},
+	}
+	cmd.SetOut(ioStream.Out)
+	addOfflineFlags(cmd)
+	return cmd
+}
+
+func addOfflineFlags(cmd *cobra.Command) {
+	cmd.Flags().StringP("file", "f", "vela.yaml", "specify the file path of the Application or Appfile")
+	cmd.Flags().StringP("definitions", "d", ".", "specify the directory of the definition YAML and CUE files")
+	cmd.Flags().StringP("namespace", "n", "default", "specify the namespace of the application")
+}
+
+func renderOffline(appFile, defDir, namespace string, explain bool, ioStream cmdutil.IOStreams) error {
+	defs, err := template.LoadLocalDefinitions(defDir)
+	if err != nil {
+		return err
+	}
+	app, err := serverlib.LoadApplication(appFile, namespace, defs, ioStream)
+	if err != nil {
+		return err
+	}
+	if serverlib.HasScopes(app) {
+		ioStream.Errorf("the scopes of application %s are not rendered without a cluster\n", app.Name)
+	}
+	if explain {
+		rendered, err := serverlib.ExplainOffline(app, defs)
+		if err != nil {
+			return err
+		}
+		printExplanations(rendered.Explanations, ioStream)
+		return nil
+	}
+	rendered, err := serverlib.RenderOffline(app, defs)
+	if err != nil {
+		return err
+	}
+	data, err := rendered.YAML()
+	if err != nil {
+		return err
+	}
+	ioStream.Info(string(data))
+	return nil
+}
+
+// NewDefinitionTestCommand creates `def test` command
+func NewDefinitionTestCommand(ioStream cmdutil.IOStreams) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "test",
+		Short: "Test the local definitions with golden files",
+		Long: "Render the cases in the " + serverlib.DefinitionTestSuffix + " files with the local definitions, " +
+			"and compare the outputs with the " + serverlib.DefinitionGoldenSuffix + " files next to them",
+		Example: `vela def test -d hack/vela-templates
+vela def test -d hack/vela-templates --update`,
+		Annotations: map[string]string{
+			types.TagCommandType: types.TypeCap,
+		},
+		RunE: func(cmd *cobra.Command, args []string) error {
+			defDir, err := cmd.Flags().GetString("definitions")
+			if err != nil {
+				return err
+			}
+			update, err := cmd.Flags().GetBool("update")
+			if err != nil {
+				return err
+			}
+			return testDefinitions(defDir, update, ioStream)
+		},
+	}
+	cmd.SetOut(ioStream.Out)
+	cmd.Flags().StringP("definitions", "d", ".", "specify the directory of the definitions and their tests")
+	cmd.Flags().Bool("update", false, "rewrite the golden files with the rendered outputs")
+	return cmd
+}
+
+func testDefinitions(defDir string, update bool, ioStream cmdutil.IOStreams) error {
+	defs, err := template.LoadLocalDefinitions(defDir)
+	if err != nil {
+		return err
+	}
+	tests, err := serverlib.LoadDefinitionTests(defDir)
+	if err != nil {
+		return err
+	}
+	results, err := serverlib.RunDefinitionTests(defs, tests, update)
+	if err != nil {
+		return err
+	}
+	failed := 0
+	for _, r := range results {
+		if r.Passed() {
+			ioStream.Infof("ok\t%s/%s\n", r.Definition, r.Case)
+			continue
+		}
+		failed++
+		ioStream.Infof("FAIL\t%s/%s (%s)\n", r.Definition, r.Case, r.File)
+		if r.Err != nil {
+			ioStream.Infof("\t%s\n", r.Err)
+			continue
+		}
+		ioStream.Infof("\tthe output differs from the golden file (-golden +rendered):\n\t%s\n",
+			strings.ReplaceAll(strings.TrimSpace(r.Diff), "\n", "\n\t"))
+	}
+	if failed > 0 {
+		return fmt.Errorf("%d of %d cases failed", failed, len(results))
+	}
+	if update {
+		ioStream.Infof("updated the golden files of %d cases\n", len(results))
+	}
+	return nil
+}
+
+// printExplanations prints the definitions and the parameters that set each field of the rendered objects
+func printExplanations(explanations []application.Explanation, ioStream cmdutil.IOStreams) {
+	table := newUITable()
+	table.AddRow("COMPONENT", "OBJECT", "FIELD", "DEFINITION", "PARAMETER")
+	for _, exp := range explanations {
+		addExplanationRows(table, exp.Component, "workload", exp.Workload)
+		for _, tr := range exp.Traits {
+			object := "trait " + tr.Type
+			switch {
+			case tr.Type == definition.AuxiliaryWorkload:
+				object = "workload outputs." + tr.Name
+			case tr.Name != "":
+				object = "trait " + tr.Type + " outputs." + tr.Name
+			}
+			addExplanationRows(table, exp.Component, object, tr.Fields)
+		}
+	}
+	ioStream.Info(table.String())
+}
+
+func addExplanationRows(table *uitable.Table, component, object string, fields model.Provenance) {
+	paths := make([]string, 0, len(fields))
+	for path := range fields {
+		paths = append(paths, path)
+	}
+	sort.Strings(paths)
+	for _, path := range paths {
+		// a field set by several definitions has a row for each of them, the last one sets the value
+		for _, source := range fields[path] {
+			table.AddRow(component, object, path, source.Definition, source.Parameter)
+		}
+	}
+}
@@ -0,0 +1,109 @@
+package commands
+
+import (
+	"context"
+	"errors"
+	"strconv"
+	"strings"
+
+	"github.com/spf13/cobra"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	"github.com/oam-dev/kubevela/apis/types"
+	cmdutil "github.com/oam-dev/kubevela/pkg/commands/util"
+	"github.com/oam-dev/kubevela/pkg/serverlib"
+)
+
+// NewAppHistoryCommand creates `history` command for listing the revisions of an application
+func NewAppHistoryCommand(c types.Args, ioStreams cmdutil.IOStreams) *cobra.Command {
+	ctx := context.Background()
+	cmd := &cobra.Command{
+		Use:                   "history APP_NAME",
+		DisableFlagsInUseLine: true,
+		Short:                 "List the revisions of an application",
+		Long:                  "List the revisions of an application, the current revision is marked with *",
+		Example:               "vela history frontend",
+		PersistentPreRunE: func(cmd *cobra.Command, args []string) error {
+			return c.SetConfig()
+		},
+		RunE: func(cmd *cobra.Command, args []string) error {
+			o, err := newRevisionOptions(c, cmd, args)
+			if err != nil {
+				return err
+			}
+			revisions, err := o.ListRevisions(ctx)
+			if err != nil {
+				return err
+			}
+			table := newUITable()
+			table.AddRow("REVISION", "NAME", "SERVICES", "CREATED-TIME")
+			for _, rev := range revisions {
+				revision := strconv.FormatInt(rev.Revision, 10)
+				if rev.Current {
+					revision += "*"
+				}
+				table.AddRow(revision, rev.Name, strings.Join(rev.Components, ","), rev.CreatedTime)
+			}
+			ioStreams.Info(table.String())
+			return nil
+		},
+		Annotations: map[string]string{
+			types.TagCommandType: types.TypeApp,
+		},
+	}
+	cmd.SetOut(ioStreams.Out)
+	return cmd
+}
+
+// NewAppRollbackCommand creates `rollback` command for rolling back an application to a revision
+func NewAppRollbackCommand(c types.Args, ioStreams cmdutil.IOStreams) *cobra.Command {
+	ctx := context.Background()
+	cmd := &cobra.Command{
+		Use:                   "rollback APP_NAME",
+		DisableFlagsInUseLine: true,
+		Short:                 "Roll back an application to a revision",
+		Long: "Roll back an application to a revision, the application is rendered with the definitions " +
+			"recorded in the revision",
+		Example: "vela rollback frontend --to 2",
+		PersistentPreRunE: func(cmd *cobra.Command, args []string) error {
+			return c.SetConfig()
+		},
+		RunE: func(cmd *cobra.Command, args []string) error {
+			o, err := newRevisionOptions(c, cmd, args)
+			if err != nil {
+				return err
+			}
+			to, err := cmd.Flags().GetInt64("to")
+			if err != nil {
+				return err
+			}
+			message, err := o.Rollback(ctx, to)
+			if err != nil {
+				return err
+			}
+			ioStreams.Info(message)
+			return nil
+		},
+		Annotations: map[string]string{
+			types.TagCommandType: types.TypeApp,
+		},
+	}
+	cmd.Flags().Int64("to", 0, "the revision to roll back to, it's the previous revision by default")
+	cmd.SetOut(ioStreams.Out)
+	return cmd
+}
+
+func newRevisionOptions(c types.Args, cmd *cobra.Command, args []string) (*serverlib.RevisionOptions, error) {
+	if len(args) < 1 {
+		return nil, errors.New("must specify name for the app")
+	}
+	newClient, err := client.New(c.Config, client.Options{Scheme: c.Schema})
+	if err != nil {
+		return nil, err
+	}
+	env, err := GetEnv(cmd)
+	if err != nil {
+		return nil, err
+	}
+	return &serverlib.RevisionOptions{AppName: args[0], Client: newClient, Env: env}, nil
+}
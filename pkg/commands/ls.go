@@ -109,7 +109,7 @@ func mergeStagingComponents(deployed []apis.ComponentMeta, env *types.EnvMeta, i
 			}
 			compMeta.TraitNames = traits
 			compMeta.WorkloadName = c.WorkloadType
-			if appl.Status.Phase != v1alpha2.ApplicationRunning {
+			if !isAppDeployed(appl) {
 				compMeta.Status = types.StatusStaging
 			}
 			all = append(all, compMeta)
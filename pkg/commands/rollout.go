@@ -0,0 +1,234 @@
+package commands
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"reflect"
+	"strconv"
+	"time"
+
+	runtimev1alpha1 "github.com/crossplane/crossplane-runtime/apis/core/v1alpha1"
+	"github.com/spf13/cobra"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	"github.com/oam-dev/kubevela/apis/standard.oam.dev/v1alpha1"
+	"github.com/oam-dev/kubevela/apis/types"
+	cmdutil "github.com/oam-dev/kubevela/pkg/commands/util"
+	"github.com/oam-dev/kubevela/pkg/server/apis"
+	"github.com/oam-dev/kubevela/pkg/serverlib"
+)
+
+// the interval to refresh the rollout status when watching it
+const rolloutTrackingInterval = 2 * time.Second
+
+// NewRolloutCommand creates `rollout` command and its nested children
+func NewRolloutCommand(c types.Args, ioStreams cmdutil.IOStreams) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:                   "rollout",
+		DisableFlagsInUseLine: true,
+		Short:                 "Manage the rollout of an application",
+		Long:                  "Show, pause, resume, promote or abort the rollout of an application",
+		Annotations: map[string]string{
+			types.TagCommandType: types.TypeApp,
+		},
+	}
+	cmd.SetOut(ioStreams.Out)
+	cmd.PersistentFlags().StringP(Service, "", "", "the service that has the rollout trait, "+
+		"required if more than one services of the app have rollout traits")
+	cmd.AddCommand(
+		NewRolloutStatusCommand(c, ioStreams),
+		newRolloutActionCommand(c, ioStreams, "pause", "Pause the rollout of an application",
+			(*serverlib.RolloutOptions).PauseRollout),
+		newRolloutActionCommand(c, ioStreams, "resume", "Resume the paused rollout of an application",
+			(*serverlib.RolloutOptions).ResumeRollout),
+		newRolloutActionCommand(c, ioStreams, "promote-batch", "Allow the rollout to continue with the next batch",
+			(*serverlib.RolloutOptions).PromoteBatch),
+		newRolloutActionCommand(c, ioStreams, "abort", "Abort the rollout, it's reverted if revertOnDelete is set",
+			(*serverlib.RolloutOptions).AbortRollout),
+	)
+	return cmd
+}
+
+// NewRolloutStatusCommand creates `rollout status` command for showing the progress of a rollout
+func NewRolloutStatusCommand(c types.Args, ioStreams cmdutil.IOStreams) *cobra.Command {
+	ctx := context.Background()
+	cmd := &cobra.Command{
+		Use:                   "status APP_NAME",
+		DisableFlagsInUseLine: true,
+		Short:                 "Show the rollout status of an application",
+		Long:                  "Show the batches, the ready replicas and the webhook and metric results of a rollout",
+		Example:               "vela rollout status frontend --watch",
+		PersistentPreRunE: func(cmd *cobra.Command, args []string) error {
+			return c.SetConfig()
+		},
+		RunE: func(cmd *cobra.Command, args []string) error {
+			o, err := newRolloutOptions(c, cmd, args)
+			if err != nil {
+				return err
+			}
+			watch, err := cmd.Flags().GetBool("watch")
+			if err != nil {
+				return err
+			}
+			rollout, err := o.GetRollout(ctx)
+			if err != nil {
+				return err
+			}
+			printRollout(ioStreams, rollout)
+			for watch && !rolloutCompleted(rollout.Status) {
+				time.Sleep(rolloutTrackingInterval)
+				latest, err := o.GetRollout(ctx)
+				if err != nil {
+					return err
+				}
+				// only print the rollout again if it has changed
+				if !reflect.DeepEqual(latest, rollout) {
+					ioStreams.Info()
+					printRollout(ioStreams, latest)
+				}
+				rollout = latest
+			}
+			return nil
+		},
+		Annotations: map[string]string{
+			types.TagCommandType: types.TypeApp,
+		},
+	}
+	cmd.Flags().BoolP("watch", "w", false, "keep watching the rollout until it succeeds or fails")
+	cmd.SetOut(ioStreams.Out)
+	return cmd
+}
+
+func newRolloutActionCommand(c types.Args, ioStreams cmdutil.IOStreams, action, short string,
+	run func(*serverlib.RolloutOptions, context.Context) (string, error)) *cobra.Command {
+	ctx := context.Background()
+	cmd := &cobra.Command{
+		Use:                   action + " APP_NAME",
+		DisableFlagsInUseLine: true,
+		Short:                 short,
+		Long:                  short,
+		Example:               fmt.Sprintf("vela rollout %s frontend", action),
+		PersistentPreRunE: func(cmd *cobra.Command, args []string) error {
+			return c.SetConfig()
+		},
+		RunE: func(cmd *cobra.Command, args []string) error {
+			o, err := newRolloutOptions(c, cmd, args)
+			if err != nil {
+				return err
+			}
+			message, err := run(o, ctx)
+			if err != nil {
+				return err
+			}
+			ioStreams.Info(message)
+			return nil
+		},
+		Annotations: map[string]string{
+			types.TagCommandType: types.TypeApp,
+		},
+	}
+	cmd.SetOut(ioStreams.Out)
+	return cmd
+}
+
+func newRolloutOptions(c types.Args, cmd *cobra.Command, args []string) (*serverlib.RolloutOptions, error) {
+	if len(args) < 1 {
+		return nil, errors.New("must specify name for the app")
+	}
+	newClient, err := client.New(c.Config, client.Options{Scheme: c.Schema})
+	if err != nil {
+		return nil, err
+	}
+	env, err := GetEnv(cmd)
+	if err != nil {
+		return nil, err
+	}
+	svcName, err := cmd.Flags().GetString(Service)
+	if err != nil {
+		return nil, err
+	}
+	return &serverlib.RolloutOptions{AppName: args[0], CompName: svcName, Client: newClient, Env: env}, nil
+}
+
+func rolloutCompleted(status v1alpha1.RolloutStatus) bool {
+	return status.RollingState == v1alpha1.Succeed || status.RollingState == v1alpha1.Failed
+}
+
+func printRollout(ioStreams cmdutil.IOStreams, rollout apis.RolloutMeta) {
+	status := rollout.Status
+	plan := rollout.RolloutPlan
+	state := string(status.RollingState)
+	if status.RollingState == v1alpha1.Rolling {
+		state = fmt.Sprintf("%s (%s)", state, status.BatchRollingState)
+	}
+	if plan.Paused {
+		state += " " + yellow.Sprint("paused")
+	}
+	switch status.RollingState {
+	case v1alpha1.Succeed:
+		state = green.Sprint(state)
+	case v1alpha1.Failed:
+		state = red.Sprint(state)
+	}
+	batch := fmt.Sprintf("%d/%d", status.CurrentBatch+1, serverlib.RolloutBatchCount(plan))
+	if plan.BatchPartition != nil {
+		batch += fmt.Sprintf(", stops after batch %d", *plan.BatchPartition+1)
+	}
+
+	table := newUITable()
+	table.AddRow("  Rollout:", fmt.Sprintf("%s/%s", rollout.Kind, rollout.Name))
+	table.AddRow("  State:", state)
+	table.AddRow("  Batch:", batch)
+	table.AddRow("  Replicas:", fmt.Sprintf("%d upgraded, %d ready, %d in total", status.UpgradedReplicas,
+		status.UpgradedReadyReplicas, status.RolloutTargetSize))
+	if cond := status.GetCondition(runtimev1alpha1.TypeSynced); len(cond.Message) != 0 {
+		table.AddRow("  Message:", cond.Message)
+	}
+	ioStreams.Info(table.String())
+
+	if len(rollout.ComponentStatuses) > 1 {
+		ioStreams.Info("\nServices:\n")
+		table = newUITable()
+		table.AddRow("  NAME", "STATE", "BATCH", "UPGRADED", "READY")
+		for _, comp := range rollout.ComponentStatuses {
+			table.AddRow("  "+comp.ComponentName, comp.RollingState, comp.CurrentBatch+1, comp.UpgradedReplicas,
+				comp.UpgradedReadyReplicas)
+		}
+		ioStreams.Info(table.String())
+	}
+	if len(status.CanaryMetricResults) != 0 {
+		ioStreams.Info("\nCanary metrics:\n")
+		table = newUITable()
+		table.AddRow("  NAME", "BATCH", "VALUE", "RESULT", "MESSAGE")
+		for _, result := range status.CanaryMetricResults {
+			table.AddRow("  "+result.Name, batchName(result.Batch), result.Value, passedMark(result.Passed), result.Message)
+		}
+		ioStreams.Info(table.String())
+	}
+	if len(status.WebhookResults) != 0 {
+		ioStreams.Info("\nWebhooks:\n")
+		table = newUITable()
+		table.AddRow("  NAME", "TYPE", "BATCH", "RESULT", "ATTEMPTS", "MESSAGE")
+		for _, result := range status.WebhookResults {
+			table.AddRow("  "+result.Name, result.Type, batchName(result.Batch), passedMark(result.Succeeded),
+				result.Attempts, result.Message)
+		}
+		ioStreams.Info(table.String())
+	}
+}
+
+// batchName shows the batch in 1-based index, a negative batch means the whole rollout
+func batchName(batch int32) string {
+	if batch < 0 {
+		return "-"
+	}
+	return strconv.Itoa(int(batch) + 1)
+}
+
+func passedMark(passed bool) string {
+	if passed {
+		return emojiSucceed
+	}
+	return emojiFail
+}
@@ -4,11 +4,10 @@ import (
 	"context"
 	"fmt"
 	"os"
-	"reflect"
+	"sort"
 	"strings"
 	"time"
 
-	runtimev1alpha1 "github.com/crossplane/crossplane-runtime/apis/core/v1alpha1"
 	"github.com/fatih/color"
 	"github.com/pkg/errors"
 	"github.com/spf13/cobra"
@@ -19,7 +18,6 @@ import (
 	"github.com/oam-dev/kubevela/pkg/appfile/storage/driver"
 	"github.com/oam-dev/kubevela/pkg/application"
 	cmdutil "github.com/oam-dev/kubevela/pkg/commands/util"
-	"github.com/oam-dev/kubevela/pkg/oam"
 	oam2 "github.com/oam-dev/kubevela/pkg/serverlib"
 )
 
@@ -46,10 +44,6 @@ type WorkloadHealthCondition = v1alpha2.WorkloadHealthCondition
 // ScopeHealthCondition holds health condition of a scope
 type ScopeHealthCondition = v1alpha2.ScopeHealthCondition
 
-var (
-	kindHealthScope = reflect.TypeOf(v1alpha2.HealthScope{}).Name()
-)
-
 // CompStatus represents the status of a component during "vela init"
 type CompStatus int
 
@@ -58,8 +52,6 @@ const (
 	compStatusDeploying CompStatus = iota
 	compStatusDeployFail
 	compStatusDeployed
-	compStatusHealthChecking
-	compStatusHealthCheckDone
 	compStatusUnknown
 )
 
@@ -146,11 +138,11 @@ func printAppStatus(ctx context.Context, c client.Client, ioStreams cmdutil.IOSt
 }
 
 func printComponentStatus(ctx context.Context, c client.Client, ioStreams cmdutil.IOStreams, compName, appName string, env *types.EnvMeta) error {
-	app, appConfig, err := getAppConfig(ctx, c, compName, appName, env)
+	app, appObj, err := getApp(ctx, c, compName, appName, env)
 	if err != nil {
 		return err
 	}
-	if app == nil || appConfig == nil {
+	if app == nil || appObj == nil {
 		return errors.New(ErrNotLoadAppConfig)
 	}
 	svc, ok := app.Services[compName]
@@ -159,104 +151,96 @@ func printComponentStatus(ctx context.Context, c client.Client, ioStreams cmduti
 	}
 	workloadType := svc.GetType()
 
-	healthStatus, healthInfo, err := healthCheckLoop(ctx, c, compName, appName, env)
+	health, err := healthCheckLoop(ctx, c, compName, appName, env)
 	if err != nil {
-		ioStreams.Info(healthInfo)
+		ioStreams.Info(red.Sprintf("Health checking failed!"))
 		return err
 	}
 	ioStreams.Infof(white.Sprintf("  - Name: %s\n", compName))
 	ioStreams.Infof("    Type: %s\n", workloadType)
 
+	var healthStatus HealthStatus = HealthStatusUnknown
+	healthInfo := "the health isn't checked yet"
+	if health != nil {
+		healthStatus, healthInfo = toHealthStatus(health.Healthy), health.Message
+	}
 	healthColor := getHealthStatusColor(healthStatus)
 	healthInfo = strings.ReplaceAll(healthInfo, "\n", "\n\t") // format healthInfo output
 	ioStreams.Infof("    %s %s\n", healthColor.Sprint(healthStatus), healthColor.Sprint(healthInfo))
+	if health != nil && len(health.Details) != 0 {
+		ioStreams.Infof("    Details: %s\n", formatHealthDetails(health.Details))
+	}
 
-	// workload Must found
 	ioStreams.Infof("    Traits:\n")
-	workloadStatus, _ := getWorkloadStatusFromAppConfig(appConfig, compName)
-	for _, tr := range workloadStatus.Traits {
-		traitType, traitInfo, err := traitCheckLoop(ctx, c, tr.Reference, compName, appConfig, app, 60*time.Second)
-		if err != nil {
-			ioStreams.Infof("      - %s%s: %s, err: %v", emojiFail, white.Sprint(traitType), traitInfo, err)
-			continue
+	if health != nil {
+		for _, tr := range health.Traits {
+			traitInfo := tr.Message
+			if traitInfo == "" {
+				traitInfo = string(toHealthStatus(tr.Healthy))
+			}
+			if len(tr.Details) != 0 {
+				traitInfo = fmt.Sprintf("%s (%s)", traitInfo, formatHealthDetails(tr.Details))
+			}
+			if tr.Healthy {
+				ioStreams.Infof("      - %s%s: %s", emojiSucceed, white.Sprint(tr.Type), traitInfo)
+				continue
+			}
+			ioStreams.Infof("      - %s%s: %s", emojiFail, white.Sprint(tr.Type), traitInfo)
 		}
-		ioStreams.Infof("      - %s%s: %s", emojiSucceed, white.Sprint(traitType), traitInfo)
 	}
 	ioStreams.Info("")
 	ioStreams.Infof("    Last Deployment:\n")
-	ioStreams.Infof("      Created at: %v\n", appConfig.CreationTimestamp)
+	ioStreams.Infof("      Created at: %v\n", appObj.CreationTimestamp)
 	ioStreams.Infof("      Updated at: %v\n", app.UpdateTime.Format(time.RFC3339))
 	return nil
 }
 
-func traitCheckLoop(ctx context.Context, c client.Client, reference runtimev1alpha1.TypedReference, compName string, appConfig *v1alpha2.ApplicationConfiguration, app *driver.Application, timeout time.Duration) (string, string, error) {
-	tr, err := oam2.GetUnstructured(ctx, c, appConfig.Namespace, reference)
-	if err != nil {
-		return "", "", err
-	}
-	traitType, ok := tr.GetLabels()[oam.TraitTypeLabel]
-	if !ok {
-		message, err := oam2.GetStatusFromObject(tr)
-		return traitType, message, err
-	}
-
-	checker := oam2.GetChecker(traitType, c)
-
-	// Health Check Loop For Trait
-	var message string
-	sHealthCheck := newTrackingSpinner(fmt.Sprintf("Checking %s status ...", traitType))
+// healthCheckLoop waits for the health of the component checked by the Application controller, an unhealthy
+// component is waited until the application is created for healthCheckBufferTime. It returns nil if the health
+// isn't checked in time.
+func healthCheckLoop(ctx context.Context, c client.Client, compName, appName string, env *types.EnvMeta) (
+	*v1alpha2.ApplicationComponentStatus, error) {
+	sHealthCheck := newTrackingSpinner("Checking health status ...")
 	sHealthCheck.Start()
 	defer sHealthCheck.Stop()
-CheckLoop:
 	for {
 		time.Sleep(trackingInterval)
-		var check oam2.CheckStatus
-		check, message, err = checker.Check(ctx, reference, compName, appConfig, app)
+		app, appObj, err := getApp(ctx, c, compName, appName, env)
 		if err != nil {
-			message = red.Sprintf("%s check failed!", traitType)
-			return traitType, message, err
+			return nil, err
 		}
-		if check == oam2.StatusDone {
-			break CheckLoop
+		if app == nil || appObj == nil {
+			return nil, errors.New(ErrNotLoadAppConfig)
 		}
-		if time.Since(tr.GetCreationTimestamp().Time) >= timeout {
-			return traitType, fmt.Sprintf("Checking timeout: %s", message), nil
+		health := oam2.GetComponentHealth(appObj, compName)
+		if health != nil && health.Healthy {
+			return health, nil
+		}
+		if time.Since(appObj.GetCreationTimestamp().Time) > healthCheckBufferTime {
+			return health, nil
 		}
 	}
-	return traitType, message, nil
 }
 
-func healthCheckLoop(ctx context.Context, c client.Client, compName, appName string, env *types.EnvMeta) (HealthStatus, string, error) {
-	// Health Check Loop For Workload
-	var healthInfo string
-	var healthStatus HealthStatus
-	var err error
-
-	sHealthCheck := newTrackingSpinner("Checking health status ...")
-	sHealthCheck.Start()
-	defer sHealthCheck.Stop()
-HealthCheckLoop:
-	for {
-		time.Sleep(trackingInterval)
-		var healthcheckStatus CompStatus
-		healthcheckStatus, healthStatus, healthInfo, err = trackHealthCheckingStatus(ctx, c, compName, appName, env)
-		if err != nil {
-			healthInfo = red.Sprintf("Health checking failed!")
-			return "", healthInfo, err
-		}
-		if healthcheckStatus == compStatusHealthCheckDone {
-			break HealthCheckLoop
-		}
+// formatHealthDetails formats the details given by the health policy in the order of the keys
+func formatHealthDetails(details map[string]string) string {
+	keys := make([]string, 0, len(details))
+	for k := range details {
+		keys = append(keys, k)
 	}
-	return healthStatus, healthInfo, nil
+	sort.Strings(keys)
+	items := make([]string, 0, len(keys))
+	for _, k := range keys {
+		items = append(items, fmt.Sprintf("%s=%s", k, details[k]))
+	}
+	return strings.Join(items, ", ")
 }
 
-func tryGetWorkloadStatus(ctx context.Context, c client.Client, ns string, wlRef runtimev1alpha1.TypedReference) (string, error) {
-	workload, err := oam2.GetUnstructured(ctx, c, ns, wlRef)
-	if err != nil {
-		return "", err
+func toHealthStatus(healthy bool) HealthStatus {
+	if healthy {
+		return HealthStatusHealthy
 	}
-	return oam2.GetStatusFromObject(workload)
+	return HealthStatusUnhealthy
 }
 
 func printTrackingDeployStatus(ctx context.Context, c client.Client, ioStreams cmdutil.IOStreams, compName, appName string, env *types.EnvMeta) (CompStatus, error) {
@@ -301,8 +285,8 @@ func TrackDeployStatus(ctx context.Context, c client.Client, compName, appName s
 		return compStatusDeploying, "", nil
 	}
 
-	// If condition is true, we can regard appConfig is deployed successfully
-	if appObj.Status.Phase == v1alpha2.ApplicationRunning {
+	// The health of the components is checked after the application is deployed successfully
+	if isAppDeployed(appObj) {
 		return compStatusDeployed, "", nil
 	}
 
@@ -314,86 +298,6 @@ func TrackDeployStatus(ctx context.Context, c client.Client, compName, appName s
 	return compStatusDeploying, "", nil
 }
 
-func trackHealthCheckingStatus(ctx context.Context, c client.Client, compName, appName string, env *types.EnvMeta) (CompStatus, HealthStatus, string, error) {
-	app, appConfig, err := getAppConfig(ctx, c, compName, appName, env)
-	if err != nil {
-		return compStatusUnknown, HealthStatusNotDiagnosed, "", err
-	}
-	if app == nil || appConfig == nil {
-		return compStatusUnknown, HealthStatusNotDiagnosed, "", errors.New(ErrNotLoadAppConfig)
-	}
-
-	wlStatus, foundWlStatus := getWorkloadStatusFromAppConfig(appConfig, compName)
-	// make sure component already initilized
-	if !foundWlStatus {
-		if len(appConfig.Status.Conditions) < 1 {
-			// still reconciling
-			return compStatusUnknown, HealthStatusUnknown, "", nil
-		}
-		appConfigConditionMsg := appConfig.Status.GetCondition(runtimev1alpha1.TypeSynced).Message
-		return compStatusUnknown, HealthStatusUnknown, "", fmt.Errorf(ErrFmtNotInitialized, appConfigConditionMsg)
-	}
-	// check whether referenced a HealthScope
-	var healthScopeName string
-	for _, v := range wlStatus.Scopes {
-		if v.Reference.Kind == kindHealthScope {
-			healthScopeName = v.Reference.Name
-		}
-	}
-	var healthStatus HealthStatus
-	if healthScopeName != "" {
-		var healthScope v1alpha2.HealthScope
-		if err = c.Get(ctx, client.ObjectKey{Namespace: env.Namespace, Name: healthScopeName}, &healthScope); err != nil {
-			return compStatusUnknown, HealthStatusUnknown, "", err
-		}
-		var wlhc *v1alpha2.WorkloadHealthCondition
-		for _, v := range healthScope.Status.WorkloadHealthConditions {
-			if v.ComponentName == compName {
-				wlhc = v
-			}
-		}
-		if wlhc == nil {
-			return compStatusUnknown, HealthStatusUnknown, "", fmt.Errorf("cannot get health condition from the health scope: %s", healthScope.Name)
-		}
-		healthStatus = wlhc.HealthStatus
-		if healthStatus == HealthStatusHealthy {
-			return compStatusHealthCheckDone, healthStatus, wlhc.Diagnosis, nil
-		}
-		if healthStatus == HealthStatusUnhealthy {
-			cTime := appConfig.GetCreationTimestamp()
-			if time.Since(cTime.Time) <= healthCheckBufferTime {
-				return compStatusHealthChecking, HealthStatusUnknown, "", nil
-			}
-			return compStatusHealthCheckDone, healthStatus, wlhc.Diagnosis, nil
-		}
-	}
-	// No health scope specified or health status is unknown , try get status from workload
-	statusInfo, err := tryGetWorkloadStatus(ctx, c, env.Namespace, wlStatus.Reference)
-	if err != nil {
-		return compStatusUnknown, HealthStatusUnknown, "", err
-	}
-	return compStatusHealthCheckDone, HealthStatusNotDiagnosed, statusInfo, nil
-}
-
-func getAppConfig(ctx context.Context, c client.Client, compName, appName string, env *types.EnvMeta) (*driver.Application, *v1alpha2.ApplicationConfiguration, error) {
-	var app *driver.Application
-	var err error
-	if appName != "" {
-		app, err = application.Load(env.Name, appName)
-	} else {
-		app, err = application.MatchAppByComp(env.Name, compName)
-	}
-	if err != nil {
-		return nil, nil, err
-	}
-
-	appConfig, err := application.GetAppConfig(ctx, c, app, env)
-	if err != nil {
-		return nil, nil, err
-	}
-	return app, appConfig, nil
-}
-
 func getApp(ctx context.Context, c client.Client, compName, appName string, env *types.EnvMeta) (*driver.Application, *v1alpha2.Application, error) {
 	var app *driver.Application
 	var err error
@@ -413,20 +317,14 @@ func getApp(ctx context.Context, c client.Client, compName, appName string, env
 	return app, appObj, nil
 }
 
-func getWorkloadStatusFromAppConfig(appConfig *v1alpha2.ApplicationConfiguration, compName string) (v1alpha2.WorkloadStatus, bool) {
-	foundWlStatus := false
-	wlStatus := v1alpha2.WorkloadStatus{}
-	if appConfig == nil {
-		return wlStatus, foundWlStatus
-	}
-	for _, v := range appConfig.Status.Workloads {
-		if v.ComponentName == compName {
-			wlStatus = v
-			foundWlStatus = true
-			break
-		}
+// isAppDeployed checks whether the application is applied to the cluster by the Application controller
+func isAppDeployed(app *v1alpha2.Application) bool {
+	switch app.Status.Phase {
+	case v1alpha2.ApplicationRunning, v1alpha2.ApplicationDegraded, v1alpha2.ApplicationUnhealthy:
+		return true
+	default:
+		return false
 	}
-	return wlStatus, foundWlStatus
 }
 
 func getHealthStatusColor(s HealthStatus) *color.Color {
@@ -6,6 +6,7 @@ import (
 	"fmt"
 
 	"github.com/spf13/cobra"
+	"github.com/spf13/pflag"
 	"sigs.k8s.io/controller-runtime/pkg/client"
 
 	"github.com/oam-dev/kubevela/apis/types"
@@ -37,6 +38,10 @@ func AddTraitCommands(parentCmd *cobra.Command, c types.Args, ioStreams cmdutil.
 		return err
 	}
 	ctx := context.Background()
+	builtinCommands := make(map[string]*cobra.Command)
+	for _, cmd := range parentCmd.Commands() {
+		builtinCommands[cmd.Name()] = cmd
+	}
 	for _, tmp := range templates {
 		tmp := tmp
 
@@ -84,11 +89,29 @@ func AddTraitCommands(parentCmd *cobra.Command, c types.Args, ioStreams cmdutil.
 		pluginCmd.Flags().BoolP(Staging, "s", false, "only save changes locally without real update application")
 		pluginCmd.Flags().BoolP(TraitDetach, "", false, "detach trait from service")
 
+		if builtin, ok := builtinCommands[name]; ok {
+			mergeTraitCommand(builtin, pluginCmd)
+			continue
+		}
 		parentCmd.AddCommand(pluginCmd)
 	}
 	return nil
 }
 
+// mergeTraitCommand makes a built-in command group attach the trait of the same name when it's called without
+// a sub command, e.g. `vela rollout frontend --replicas 5` still attaches the rollout trait
+func mergeTraitCommand(builtin, pluginCmd *cobra.Command) {
+	builtin.Long += ", or attach " + pluginCmd.Name() + " trait to an app if no sub command is given"
+	builtin.Example = pluginCmd.Example
+	builtin.PersistentPreRunE = pluginCmd.PersistentPreRunE
+	builtin.RunE = pluginCmd.RunE
+	pluginCmd.Flags().VisitAll(func(f *pflag.Flag) {
+		if builtin.Flags().Lookup(f.Name) == nil && builtin.PersistentFlags().Lookup(f.Name) == nil {
+			builtin.Flags().AddFlag(f)
+		}
+	})
+}
+
 // Prepare prepares data for constructing OAM entities
 func (o *commandOptions) Prepare(cmd *cobra.Command, args []string) error {
 	if len(args) < 1 {
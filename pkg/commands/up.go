@@ -11,10 +11,12 @@ import (
 
 	"github.com/pkg/errors"
 	"github.com/spf13/cobra"
+	corev1 "k8s.io/api/core/v1"
 	apierrors "k8s.io/apimachinery/pkg/api/errors"
 	k8sjson "k8s.io/apimachinery/pkg/runtime/serializer/json"
 	apitypes "k8s.io/apimachinery/pkg/types"
 	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/yaml"
 
 	"github.com/oam-dev/kubevela/apis/core.oam.dev/v1alpha2"
 	"github.com/oam-dev/kubevela/apis/types"
@@ -23,7 +25,9 @@ import (
 	"github.com/oam-dev/kubevela/pkg/appfile/template"
 	"github.com/oam-dev/kubevela/pkg/application"
 	cmdutil "github.com/oam-dev/kubevela/pkg/commands/util"
+	appcontroller "github.com/oam-dev/kubevela/pkg/controller/core.oam.dev/v1alpha2/application"
 	"github.com/oam-dev/kubevela/pkg/oam"
+	"github.com/oam-dev/kubevela/pkg/oam/discoverymapper"
 	"github.com/oam-dev/kubevela/pkg/utils/common"
 )
 
@@ -63,12 +67,32 @@ func NewUpCommand(c types.Args, ioStream cmdutil.IOStreams) *cobra.Command {
 			if err != nil {
 				return err
 			}
-			return o.Run(filePath)
+			dryRun, err := cmd.Flags().GetBool("dry-run")
+			if err != nil {
+				return err
+			}
+			explain, err := cmd.Flags().GetBool("explain")
+			if err != nil {
+				return err
+			}
+			dm, err := discoverymapper.New(c.Config)
+			if err != nil {
+				return err
+			}
+			if explain {
+				return o.Explain(filePath, dm)
+			}
+			if dryRun {
+				return o.DryRun(filePath, dm)
+			}
+			return o.Run(filePath, dm)
 		},
 	}
 	cmd.SetOut(ioStream.Out)
 
 	cmd.Flags().StringP(appFilePath, "f", "", "specify file path for appfile")
+	cmd.Flags().Bool("dry-run", false, "render the appfile with the definitions in the cluster and print the resources without applying them")
+	cmd.Flags().Bool("explain", false, "render the appfile with the definitions in the cluster and print the definition and parameter that set each field without applying it")
 	return cmd
 }
 
@@ -165,12 +189,16 @@ func (o *AppfileOptions) export(filePath string, quiet bool) (*buildResult, []by
 	return result, w.Bytes(), nil
 }
 
-// Run starts an application according to Appfile
-func (o *AppfileOptions) Run(filePath string) error {
+// Run starts an application according to Appfile, the settings and trait properties are validated against the
+// definitions in the cluster before the application is applied
+func (o *AppfileOptions) Run(filePath string, dm discoverymapper.DiscoveryMapper) error {
 	result, data, err := o.export(filePath, false)
 	if err != nil {
 		return err
 	}
+	if err := o.validate(result.application, dm); err != nil {
+		return err
+	}
 	deployFilePath := ".vela/deploy.yaml"
 	o.IO.Infof("Writing deploy config to (%s)\n", deployFilePath)
 	if err := os.MkdirAll(filepath.Dir(deployFilePath), 0700); err != nil {
@@ -189,6 +217,70 @@ func (o *AppfileOptions) Run(filePath string) error {
 	return o.ApplyApp(result.application, result.scopes)
 }
 
+// DryRun renders the application of the appfile with the definitions in the cluster the same way as the application
+// controller does, and prints the rendered resources instead of applying them
+func (o *AppfileOptions) DryRun(filePath string, dm discoverymapper.DiscoveryMapper) error {
+	result, _, err := o.export(filePath, true)
+	if err != nil {
+		return err
+	}
+	if err := o.validate(result.application, dm); err != nil {
+		return err
+	}
+	rendered, err := appcontroller.NewApplicationParser(o.Kubecli, dm).Render(result.application)
+	if err != nil {
+		return errors.Wrap(err, "render application failed")
+	}
+	data, err := rendered.YAML()
+	if err != nil {
+		return err
+	}
+	o.IO.Infonln(string(data))
+	// the scopes are rendered as the scopes of the application, only the config ConfigMaps are left to print
+	for _, obj := range result.scopes {
+		cm, ok := obj.(*corev1.ConfigMap)
+		if !ok {
+			continue
+		}
+		cm = cm.DeepCopy()
+		cm.SetGroupVersionKind(corev1.SchemeGroupVersion.WithKind("ConfigMap"))
+		data, err := yaml.Marshal(cm)
+		if err != nil {
+			return fmt.Errorf("yaml encode config (%s) failed: %w", cm.GetName(), err)
+		}
+		o.IO.Infonln("---\n" + string(data))
+	}
+	return nil
+}
+
+// Explain renders the application of the appfile with the definitions in the cluster in the same way as DryRun, and
+// prints the definition and parameter that set each field of the workloads and traits
+func (o *AppfileOptions) Explain(filePath string, dm discoverymapper.DiscoveryMapper) error {
+	result, _, err := o.export(filePath, true)
+	if err != nil {
+		return err
+	}
+	if err := o.validate(result.application, dm); err != nil {
+		return err
+	}
+	rendered, err := appcontroller.NewApplicationParser(o.Kubecli, dm).Explain(result.application)
+	if err != nil {
+		return errors.Wrap(err, "render application failed")
+	}
+	printExplanations(rendered.Explanations, o.IO)
+	return nil
+}
+
+// validate checks the settings and trait properties of the application against the parameters of the definitions in
+// the cluster, the same as the application webhook does
+func (o *AppfileOptions) validate(app *v1alpha2.Application, dm discoverymapper.DiscoveryMapper) error {
+	appfile, err := appcontroller.NewApplicationParser(o.Kubecli, dm).GenerateAppFile(app.Name, app)
+	if err != nil {
+		return errors.Wrap(err, "parse application failed")
+	}
+	return errors.Wrap(appfile.ValidateParameters(), "validate application failed")
+}
+
 func (o *AppfileOptions) saveToAppDir(f *appfile.AppFile) error {
 	app := &driver.Application{AppFile: f}
 	return application.Save(app, o.Env.Name)
@@ -0,0 +1,65 @@
+package rollout
+
+import (
+	"fmt"
+
+	"k8s.io/apimachinery/pkg/util/intstr"
+
+	"github.com/oam-dev/kubevela/apis/standard.oam.dev/v1alpha1"
+)
+
+// calculateBatchSizes returns the number of target pods that should be upgraded by the end of each batch.
+// The last batch always takes all the remaining pods so that the rollout ends with the total size.
+func calculateBatchSizes(plan *v1alpha1.RolloutPlan, totalSize int32) ([]int32, error) {
+	if len(plan.RolloutBatches) != 0 {
+		sizes := make([]int32, len(plan.RolloutBatches))
+		var upgraded int32
+		for i, batch := range plan.RolloutBatches {
+			if len(batch.PodList) != 0 {
+				return nil, fmt.Errorf("the podList of batch %d is not supported yet", i)
+			}
+			replicas, err := intstr.GetValueFromIntOrPercent(&batch.Replicas, int(totalSize), true)
+			if err != nil {
+				return nil, fmt.Errorf("invalid replicas of batch %d: %w", i, err)
+			}
+			upgraded += int32(replicas)
+			if upgraded > totalSize {
+				return nil, fmt.Errorf("the batches upgrade %d pods which exceeds the target size %d", upgraded, totalSize)
+			}
+			sizes[i] = upgraded
+		}
+		sizes[len(sizes)-1] = totalSize
+		return sizes, nil
+	}
+
+	numBatches := int32(1)
+	if plan.NumBatches != nil {
+		numBatches = *plan.NumBatches
+	}
+	if numBatches < 1 {
+		return nil, fmt.Errorf("the number of batches %d must be positive", numBatches)
+	}
+	sizes := make([]int32, numBatches)
+	var upgraded int32
+	for i := int32(0); i < numBatches; i++ {
+		// spread the remainder over the first few batches
+		upgraded += totalSize / numBatches
+		if i < totalSize%numBatches {
+			upgraded++
+		}
+		sizes[i] = upgraded
+	}
+	return sizes, nil
+}
+
+// maxUnavailable returns the number of pods allowed to be unavailable when checking if a batch is ready
+func maxUnavailable(plan *v1alpha1.RolloutPlan, batch int32, batchSize int32) int32 {
+	if int(batch) >= len(plan.RolloutBatches) || plan.RolloutBatches[batch].MaxUnavailable == nil {
+		return 0
+	}
+	unavailable, err := intstr.GetValueFromIntOrPercent(plan.RolloutBatches[batch].MaxUnavailable, int(batchSize), false)
+	if err != nil {
+		return 0
+	}
+	return int32(unavailable)
+}
@@ -0,0 +1,80 @@
+package rollout
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"k8s.io/apimachinery/pkg/util/intstr"
+	"k8s.io/utils/pointer"
+
+	"github.com/oam-dev/kubevela/apis/standard.oam.dev/v1alpha1"
+)
+
+func TestCalculateBatchSizes(t *testing.T) {
+	testCases := map[string]struct {
+		plan      v1alpha1.RolloutPlan
+		totalSize int32
+		expect    []int32
+		hasError  bool
+	}{
+		"default to one batch": {
+			plan:      v1alpha1.RolloutPlan{},
+			totalSize: 5,
+			expect:    []int32{5},
+		},
+		"spread evenly with remainder": {
+			plan:      v1alpha1.RolloutPlan{NumBatches: pointer.Int32Ptr(3)},
+			totalSize: 10,
+			expect:    []int32{4, 7, 10},
+		},
+		"invalid number of batches": {
+			plan:      v1alpha1.RolloutPlan{NumBatches: pointer.Int32Ptr(0)},
+			totalSize: 10,
+			hasError:  true,
+		},
+		"exact batches with percentage": {
+			plan: v1alpha1.RolloutPlan{RolloutBatches: []v1alpha1.RolloutBatch{
+				{Replicas: intstr.FromInt(1)},
+				{Replicas: intstr.FromString("50%")},
+				{Replicas: intstr.FromInt(1)},
+			}},
+			totalSize: 10,
+			expect:    []int32{1, 6, 10},
+		},
+		"batches exceed the total size": {
+			plan: v1alpha1.RolloutPlan{RolloutBatches: []v1alpha1.RolloutBatch{
+				{Replicas: intstr.FromInt(6)},
+				{Replicas: intstr.FromInt(6)},
+			}},
+			totalSize: 10,
+			hasError:  true,
+		},
+		"pod list is not supported": {
+			plan: v1alpha1.RolloutPlan{RolloutBatches: []v1alpha1.RolloutBatch{
+				{PodList: []string{"pod-a"}},
+			}},
+			totalSize: 10,
+			hasError:  true,
+		},
+	}
+	for name, tc := range testCases {
+		sizes, err := calculateBatchSizes(&tc.plan, tc.totalSize)
+		if tc.hasError {
+			assert.Error(t, err, name)
+			continue
+		}
+		assert.NoError(t, err, name)
+		assert.Equal(t, tc.expect, sizes, name)
+	}
+}
+
+func TestMaxUnavailable(t *testing.T) {
+	unavailable := intstr.FromString("50%")
+	plan := &v1alpha1.RolloutPlan{RolloutBatches: []v1alpha1.RolloutBatch{
+		{Replicas: intstr.FromInt(4), MaxUnavailable: &unavailable},
+		{Replicas: intstr.FromInt(4)},
+	}}
+	assert.Equal(t, int32(2), maxUnavailable(plan, 0, 4))
+	assert.Equal(t, int32(0), maxUnavailable(plan, 1, 4))
+	assert.Equal(t, int32(0), maxUnavailable(&v1alpha1.RolloutPlan{}, 0, 4))
+}
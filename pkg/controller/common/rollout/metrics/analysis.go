@@ -0,0 +1,128 @@
+package metrics
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"strconv"
+	"text/template"
+
+	"github.com/pkg/errors"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/apimachinery/pkg/util/intstr"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	"github.com/oam-dev/kubevela/apis/standard.oam.dev/v1alpha1"
+)
+
+// The keys of the ConfigMap that is referenced by the templateRef of a canary metric
+const (
+	// TemplateProviderKey is the type of the metric provider, default is prometheus
+	TemplateProviderKey = "provider"
+	// TemplateAddressKey is the address of the metric provider
+	TemplateAddressKey = "address"
+	// TemplateQueryKey is the query to run, it's a go template that can use the QueryVariables
+	TemplateQueryKey = "query"
+)
+
+// defaultInterval is the window size of a metric if the interval is not set
+const defaultInterval = "1m"
+
+// QueryVariables are the variables that can be referenced by the query template, e.g. {{ .Workload }}
+type QueryVariables struct {
+	// Name is the name of the canary metric
+	Name string
+	// Namespace is the namespace of the workload
+	Namespace string
+	// Workload is the name of the workload being rolled out
+	Workload string
+	// Interval is the window size of the metric
+	Interval string
+}
+
+// Check runs the query of a canary metric and compares the observed value with the expected range.
+// It returns error only if the value cannot be observed, a value out of range is recorded in the result.
+func Check(ctx context.Context, c client.Reader, metric v1alpha1.CanaryMetric, vars QueryVariables) (
+	*v1alpha1.CanaryMetricResult, error) {
+	if metric.TemplateRef == nil {
+		return nil, fmt.Errorf("canary metric %s has no template", metric.Name)
+	}
+	var cm corev1.ConfigMap
+	if err := c.Get(ctx, types.NamespacedName{Namespace: vars.Namespace, Name: metric.TemplateRef.Name}, &cm); err != nil {
+		return nil, errors.Wrapf(err, "cannot get the template of canary metric %s", metric.Name)
+	}
+	provider, err := NewProvider(cm.Data[TemplateProviderKey], cm.Data[TemplateAddressKey])
+	if err != nil {
+		return nil, err
+	}
+
+	vars.Name = metric.Name
+	vars.Interval = metric.Interval
+	if len(vars.Interval) == 0 {
+		vars.Interval = defaultInterval
+	}
+	query, err := renderQuery(cm.Data[TemplateQueryKey], vars)
+	if err != nil {
+		return nil, errors.Wrapf(err, "invalid query of canary metric %s", metric.Name)
+	}
+	value, err := provider.RunQuery(ctx, query)
+	if err != nil {
+		return nil, errors.Wrapf(err, "cannot run the query of canary metric %s", metric.Name)
+	}
+
+	result := &v1alpha1.CanaryMetricResult{
+		Name:          metric.Name,
+		Value:         strconv.FormatFloat(value, 'f', -1, 64),
+		Passed:        true,
+		LastCheckTime: metav1.Now(),
+	}
+	if metric.MetricsRange == nil {
+		return result, nil
+	}
+	if metric.MetricsRange.Min != nil {
+		min, err := rangeValue(metric.MetricsRange.Min)
+		if err != nil {
+			return nil, errors.Wrapf(err, "invalid min of canary metric %s", metric.Name)
+		}
+		if value < min {
+			result.Passed = false
+			result.Message = fmt.Sprintf("%s is less than the min %s", result.Value, metric.MetricsRange.Min.String())
+		}
+	}
+	if metric.MetricsRange.Max != nil {
+		max, err := rangeValue(metric.MetricsRange.Max)
+		if err != nil {
+			return nil, errors.Wrapf(err, "invalid max of canary metric %s", metric.Name)
+		}
+		if value > max {
+			result.Passed = false
+			result.Message = fmt.Sprintf("%s is greater than the max %s", result.Value, metric.MetricsRange.Max.String())
+		}
+	}
+	return result, nil
+}
+
+func renderQuery(query string, vars QueryVariables) (string, error) {
+	if len(query) == 0 {
+		return "", errors.New("query is empty")
+	}
+	t, err := template.New("query").Option("missingkey=error").Parse(query)
+	if err != nil {
+		return "", err
+	}
+	var buf bytes.Buffer
+	if err := t.Execute(&buf, vars); err != nil {
+		return "", err
+	}
+	return buf.String(), nil
+}
+
+// rangeValue parses the bound of a metrics range, a string bound can be a float such as "0.99"
+func rangeValue(v *intstr.IntOrString) (float64, error) {
+	if v.Type == intstr.Int {
+		return float64(v.IntVal), nil
+	}
+	return strconv.ParseFloat(v.StrVal, 64)
+}
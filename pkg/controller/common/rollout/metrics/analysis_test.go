@@ -0,0 +1,122 @@
+package metrics
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	runtimev1alpha1 "github.com/crossplane/crossplane-runtime/apis/core/v1alpha1"
+	"github.com/stretchr/testify/assert"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/intstr"
+	"k8s.io/client-go/kubernetes/scheme"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+
+	"github.com/oam-dev/kubevela/apis/standard.oam.dev/v1alpha1"
+)
+
+// newFakePrometheus starts a server that answers every query with the given body and records the last query
+func newFakePrometheus(body string, lastQuery *string) *httptest.Server {
+	return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		*lastQuery = r.URL.Query().Get("query")
+		_, _ = fmt.Fprint(w, body)
+	}))
+}
+
+func TestPrometheusProvider(t *testing.T) {
+	testCases := map[string]struct {
+		body     string
+		expect   float64
+		hasError bool
+	}{
+		"vector": {
+			body:   `{"status":"success","data":{"resultType":"vector","result":[{"metric":{},"value":[1608000000.1,"0.99"]}]}}`,
+			expect: 0.99,
+		},
+		"scalar": {
+			body:   `{"status":"success","data":{"resultType":"scalar","result":[1608000000.1,"12"]}}`,
+			expect: 12,
+		},
+		"empty vector": {
+			body:     `{"status":"success","data":{"resultType":"vector","result":[]}}`,
+			hasError: true,
+		},
+		"NaN": {
+			body:     `{"status":"success","data":{"resultType":"scalar","result":[1608000000.1,"NaN"]}}`,
+			hasError: true,
+		},
+		"query error": {
+			body:     `{"status":"error","errorType":"bad_data","error":"parse error"}`,
+			hasError: true,
+		},
+		"too many samples": {
+			body:     `{"status":"success","data":{"resultType":"vector","result":[{"value":[1,"1"]},{"value":[1,"2"]}]}}`,
+			hasError: true,
+		},
+	}
+	for name, tc := range testCases {
+		var query string
+		server := newFakePrometheus(tc.body, &query)
+		p, err := NewPrometheusProvider(server.URL)
+		assert.NoError(t, err, name)
+		v, err := p.RunQuery(context.Background(), "up")
+		server.Close()
+		assert.Equal(t, "up", query, name)
+		if tc.hasError {
+			assert.Error(t, err, name)
+			continue
+		}
+		assert.NoError(t, err, name)
+		assert.Equal(t, tc.expect, v, name)
+	}
+
+	_, err := NewProvider("datadog", "http://datadog")
+	assert.Error(t, err)
+	_, err = NewProvider("", "not a url")
+	assert.Error(t, err)
+}
+
+func TestCheck(t *testing.T) {
+	var query string
+	server := newFakePrometheus(
+		`{"status":"success","data":{"resultType":"vector","result":[{"metric":{},"value":[1608000000.1,"0.95"]}]}}`, &query)
+	defer server.Close()
+	cm := &corev1.ConfigMap{
+		ObjectMeta: metav1.ObjectMeta{Name: "success-rate", Namespace: "default"},
+		Data: map[string]string{
+			TemplateAddressKey: server.URL,
+			TemplateQueryKey:   `sum(rate(requests{namespace="{{ .Namespace }}",workload="{{ .Workload }}",code!~"5.."}[{{ .Interval }}]))`,
+		},
+	}
+	c := fake.NewFakeClientWithScheme(scheme.Scheme, cm)
+	vars := QueryVariables{Namespace: "default", Workload: "v2"}
+	min := intstr.FromString("0.9")
+	metric := v1alpha1.CanaryMetric{
+		Name:         "success-rate",
+		TemplateRef:  &runtimev1alpha1.TypedReference{APIVersion: "v1", Kind: "ConfigMap", Name: "success-rate"},
+		MetricsRange: &v1alpha1.MetricsExpectedRange{Min: &min},
+	}
+
+	result, err := Check(context.Background(), c, metric, vars)
+	assert.NoError(t, err)
+	assert.True(t, result.Passed)
+	assert.Equal(t, "0.95", result.Value)
+	assert.Equal(t, `sum(rate(requests{namespace="default",workload="v2",code!~"5.."}[1m]))`, query)
+
+	max := intstr.FromInt(0)
+	metric.Interval = "5m"
+	metric.MetricsRange.Max = &max
+	result, err = Check(context.Background(), c, metric, vars)
+	assert.NoError(t, err)
+	assert.False(t, result.Passed)
+	assert.Contains(t, result.Message, "greater than the max")
+	assert.Contains(t, query, "[5m]")
+
+	// metrics without template can't be checked
+	metric.TemplateRef = nil
+	_, err = Check(context.Background(), c, metric, vars)
+	assert.Error(t, err)
+}
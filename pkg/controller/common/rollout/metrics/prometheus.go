@@ -0,0 +1,115 @@
+package metrics
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"math"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// the time to wait for the prometheus server to answer a query
+var prometheusQueryTimeout = 10 * time.Second
+
+// PrometheusProvider queries a server that implements the Prometheus HTTP query API
+type PrometheusProvider struct {
+	address string
+	client  *http.Client
+}
+
+type prometheusResponse struct {
+	Status    string `json:"status"`
+	ErrorType string `json:"errorType"`
+	Error     string `json:"error"`
+	Data      struct {
+		ResultType string          `json:"resultType"`
+		Result     json.RawMessage `json:"result"`
+	} `json:"data"`
+}
+
+type prometheusSample struct {
+	Value []interface{} `json:"value"`
+}
+
+// NewPrometheusProvider creates a prometheus provider
+func NewPrometheusProvider(address string) (*PrometheusProvider, error) {
+	if _, err := url.ParseRequestURI(address); err != nil {
+		return nil, fmt.Errorf("invalid prometheus address %q: %w", address, err)
+	}
+	return &PrometheusProvider{
+		address: strings.TrimSuffix(address, "/"),
+		client:  &http.Client{Timeout: prometheusQueryTimeout},
+	}, nil
+}
+
+// RunQuery runs an instant query, the query must result in a scalar or a vector with exactly one sample
+func (p *PrometheusProvider) RunQuery(ctx context.Context, query string) (float64, error) {
+	u := fmt.Sprintf("%s/api/v1/query?query=%s", p.address, url.QueryEscape(query))
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, u, nil)
+	if err != nil {
+		return 0, err
+	}
+	r, err := p.client.Do(req)
+	if err != nil {
+		return 0, err
+	}
+	defer func() {
+		_ = r.Body.Close()
+	}()
+	b, err := ioutil.ReadAll(r.Body)
+	if err != nil {
+		return 0, fmt.Errorf("error reading body: %w", err)
+	}
+
+	var resp prometheusResponse
+	if err := json.Unmarshal(b, &resp); err != nil {
+		return 0, fmt.Errorf("cannot decode the response of status %d: %w", r.StatusCode, err)
+	}
+	if resp.Status != "success" {
+		return 0, fmt.Errorf("query %q failed with %s: %s", query, resp.ErrorType, resp.Error)
+	}
+
+	var value []interface{}
+	switch resp.Data.ResultType {
+	case "scalar":
+		if err := json.Unmarshal(resp.Data.Result, &value); err != nil {
+			return 0, err
+		}
+	case "vector":
+		var samples []prometheusSample
+		if err := json.Unmarshal(resp.Data.Result, &samples); err != nil {
+			return 0, err
+		}
+		if len(samples) == 0 {
+			return 0, ErrNoValues
+		}
+		if len(samples) > 1 {
+			return 0, fmt.Errorf("query %q returns %d samples, only one is expected", query, len(samples))
+		}
+		value = samples[0].Value
+	default:
+		return 0, fmt.Errorf("result type %s of query %q is not supported", resp.Data.ResultType, query)
+	}
+
+	// a sample is a pair of the timestamp and the value in string
+	if len(value) != 2 {
+		return 0, ErrNoValues
+	}
+	s, ok := value[1].(string)
+	if !ok {
+		return 0, fmt.Errorf("invalid sample value %v", value[1])
+	}
+	f, err := strconv.ParseFloat(s, 64)
+	if err != nil {
+		return 0, err
+	}
+	if math.IsNaN(f) {
+		return 0, ErrNoValues
+	}
+	return f, nil
+}
@@ -0,0 +1,29 @@
+package metrics
+
+import (
+	"context"
+	"errors"
+	"fmt"
+)
+
+// PrometheusProviderType is the provider that speaks the Prometheus HTTP query API
+const PrometheusProviderType = "prometheus"
+
+// ErrNoValues is returned when the query returns no data
+var ErrNoValues = errors.New("no values found")
+
+// Provider runs the query of a canary metric against a metric backend
+type Provider interface {
+	// RunQuery runs the query and returns the single value it results in
+	RunQuery(ctx context.Context, query string) (float64, error)
+}
+
+// NewProvider creates the provider of the given type, the prometheus provider is used if the type is empty
+func NewProvider(providerType, address string) (Provider, error) {
+	switch providerType {
+	case "", PrometheusProviderType:
+		return NewPrometheusProvider(address)
+	default:
+		return nil, fmt.Errorf("metric provider %s is not supported", providerType)
+	}
+}
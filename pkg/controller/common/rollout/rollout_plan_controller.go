@@ -0,0 +1,556 @@
+package rollout
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"time"
+
+	cpv1alpha1 "github.com/crossplane/crossplane-runtime/apis/core/v1alpha1"
+	"github.com/crossplane/crossplane-runtime/pkg/event"
+	"github.com/pkg/errors"
+	"k8s.io/apimachinery/pkg/api/meta"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/klog/v2"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+
+	"github.com/oam-dev/kubevela/apis/standard.oam.dev/v1alpha1"
+	"github.com/oam-dev/kubevela/pkg/controller/common/rollout/metrics"
+	"github.com/oam-dev/kubevela/pkg/controller/common/rollout/workloads"
+	"github.com/oam-dev/kubevela/pkg/oam"
+)
+
+// the time to wait between two reconciles of a rollout that is still in progress
+var rolloutWaitInterval = 5 * time.Second
+
+// event reasons emitted by the rollout plan controller
+const (
+	reasonRolloutVerified    = "RolloutVerified"
+	reasonRolloutInitialized = "RolloutInitialized"
+	reasonBatchReady         = "RolloutBatchReady"
+	reasonBatchStarted       = "RolloutBatchStarted"
+	reasonRolloutSucceed     = "RolloutSucceed"
+	reasonRolloutFailed      = "RolloutFailed"
+	reasonRolloutError       = "RolloutError"
+	reasonWebhookFailed      = "RolloutWebhookFailed"
+)
+
+// Controller drives a rollout plan through its states. It scales the target workload up and the source workload
+// down batch by batch, all the progress is kept in the rollout status so that a rollout can always be resumed.
+type Controller struct {
+	client   client.Client
+	recorder event.Recorder
+	// parent is the object that owns the rollout, events are recorded on it
+	parent runtime.Object
+
+	rolloutSpec   *v1alpha1.RolloutPlan
+	rolloutStatus *v1alpha1.RolloutStatus
+
+	targetWorkload *unstructured.Unstructured
+	sourceWorkload *unstructured.Unstructured
+}
+
+// NewRolloutPlanController creates a rollout plan controller, the source workload can be nil if it's the first time
+// to deploy the target
+func NewRolloutPlanController(client client.Client, recorder event.Recorder, parent runtime.Object,
+	rolloutSpec *v1alpha1.RolloutPlan, rolloutStatus *v1alpha1.RolloutStatus,
+	targetWorkload, sourceWorkload *unstructured.Unstructured) *Controller {
+	return &Controller{
+		client:         client,
+		recorder:       recorder,
+		parent:         parent,
+		rolloutSpec:    rolloutSpec.DeepCopy(),
+		rolloutStatus:  rolloutStatus.DeepCopy(),
+		targetWorkload: targetWorkload,
+		sourceWorkload: sourceWorkload,
+	}
+}
+
+// RestartOnChange resets the rollout status if the target or the source has changed since the rollout started.
+// It returns true if a rollout that has already started is restarted.
+func RestartOnChange(status *v1alpha1.RolloutStatus, targetGeneration, sourceGeneration string) bool {
+	if status.TargetGeneration == targetGeneration && status.SourceGeneration == sourceGeneration {
+		return false
+	}
+	started := len(status.RollingState) != 0
+	*status = v1alpha1.RolloutStatus{
+		TargetGeneration: targetGeneration,
+		SourceGeneration: sourceGeneration,
+		RollingState:     v1alpha1.Verifying,
+	}
+	return started
+}
+
+// Reconcile moves the rollout forward by at most one step and returns the new rollout status
+func (r *Controller) Reconcile(ctx context.Context) (reconcile.Result, *v1alpha1.RolloutStatus) {
+	status := r.rolloutStatus
+	if status.RollingState == "" {
+		status.RollingState = v1alpha1.Verifying
+	}
+	if status.RollingState == v1alpha1.Succeed || status.RollingState == v1alpha1.Failed {
+		// the notifications keep being retried after the rollout completes
+		r.retryNotifications(ctx)
+		return r.requeueAfter(0), status
+	}
+
+	target, err := workloads.NewWorkloadController(r.client, r.targetWorkload)
+	if err != nil {
+		r.fail(errors.Wrap(err, "cannot locate the target workload"))
+		return reconcile.Result{}, status
+	}
+	var source workloads.WorkloadController
+	if r.sourceWorkload != nil {
+		if source, err = workloads.NewWorkloadController(r.client, r.sourceWorkload); err != nil {
+			r.fail(errors.Wrap(err, "cannot locate the source workload"))
+			return reconcile.Result{}, status
+		}
+	}
+
+	if r.rolloutSpec.Paused {
+		klog.InfoS("the rollout is paused", "rolling state", status.RollingState, "batch", status.CurrentBatch)
+		if status.RollingState == v1alpha1.Rolling {
+			status.BatchRollingState = v1alpha1.BatchStopped
+		}
+		return reconcile.Result{RequeueAfter: rolloutWaitInterval}, status
+	}
+
+	r.retryNotifications(ctx)
+	switch status.RollingState {
+	case v1alpha1.Verifying:
+		r.verify(target, source)
+	case v1alpha1.Initializing:
+		err = r.initialize(ctx, target, source)
+	case v1alpha1.Rolling:
+		err = r.rollBatch(ctx, target, source)
+	case v1alpha1.Finalising:
+		err = r.finalize(ctx, target, source)
+	default:
+		r.fail(fmt.Errorf("unknown rolling state %s", status.RollingState))
+	}
+	if err != nil {
+		klog.ErrorS(err, "rollout step failed", "rolling state", status.RollingState, "batch", status.CurrentBatch)
+		r.recorder.Event(r.parent, event.Warning(reasonRolloutError, err))
+		status.SetConditions(cpv1alpha1.ReconcileError(err))
+		return r.requeueAfter(rolloutWaitInterval), status
+	}
+	if status.RollingState == v1alpha1.Succeed || status.RollingState == v1alpha1.Failed {
+		return r.requeueAfter(0), status
+	}
+	status.SetConditions(cpv1alpha1.ReconcileSuccess())
+	return r.requeueAfter(rolloutWaitInterval), status
+}
+
+// verify makes sure that the plan can be applied to the workloads and decides the size of the rollout
+func (r *Controller) verify(target, source workloads.WorkloadController) {
+	var totalSize int32
+	var err error
+	switch {
+	case r.rolloutSpec.TargetSize != nil:
+		totalSize = *r.rolloutSpec.TargetSize
+	case source != nil:
+		totalSize, err = sizeBeforeRollout(source)
+	default:
+		totalSize, err = sizeBeforeRollout(target)
+	}
+	if err != nil {
+		r.fail(err)
+		return
+	}
+	if _, err := calculateBatchSizes(r.rolloutSpec, totalSize); err != nil {
+		r.fail(errors.Wrap(err, "the rollout plan is invalid"))
+		return
+	}
+	r.rolloutStatus.RolloutTargetSize = totalSize
+	r.rolloutStatus.RollingState = v1alpha1.Initializing
+	r.recorder.Event(r.parent, event.Normal(reasonRolloutVerified,
+		fmt.Sprintf("rollout plan verified, %d pods will be upgraded", totalSize)))
+}
+
+// initialize marks the workloads under the rollout and scales the target workload to zero so that the batches can
+// bring it up step by step
+func (r *Controller) initialize(ctx context.Context, target, source workloads.WorkloadController) error {
+	if passed, err := r.gateOnWebhooks(ctx, v1alpha1.InitializeRolloutHook, -1); err != nil || !passed {
+		return err
+	}
+	for _, w := range []workloads.WorkloadController{target, source} {
+		if w == nil {
+			continue
+		}
+		if err := r.markRollout(ctx, w); err != nil {
+			return err
+		}
+	}
+	if err := target.Scale(ctx, 0); err != nil {
+		return err
+	}
+	r.rolloutStatus.RollingState = v1alpha1.Rolling
+	r.rolloutStatus.BatchRollingState = v1alpha1.BatchRolling
+	r.rolloutStatus.CurrentBatch = 0
+	r.rolloutStatus.UpgradedReplicas = 0
+	r.rolloutStatus.UpgradedReadyReplicas = 0
+	r.recorder.Event(r.parent, event.Normal(reasonRolloutInitialized, "rollout initialized"))
+	return nil
+}
+
+// rollBatch drives the current batch through its sub states
+func (r *Controller) rollBatch(ctx context.Context, target, source workloads.WorkloadController) error {
+	status := r.rolloutStatus
+	sizes, err := calculateBatchSizes(r.rolloutSpec, status.RolloutTargetSize)
+	if err != nil {
+		r.fail(errors.Wrap(err, "the rollout plan is invalid"))
+		return nil
+	}
+	if int(status.CurrentBatch) >= len(sizes) {
+		r.fail(fmt.Errorf("the current batch %d is beyond the %d batches of the plan", status.CurrentBatch, len(sizes)))
+		return nil
+	}
+	if status.BatchRollingState == "" || status.BatchRollingState == v1alpha1.BatchStopped {
+		status.BatchRollingState = v1alpha1.BatchRolling
+	}
+
+	switch status.BatchRollingState {
+	case v1alpha1.BatchRolling:
+		if passed, err := r.gateOnWebhooks(ctx, v1alpha1.PreBatchRolloutHook, status.CurrentBatch); err != nil || !passed {
+			return err
+		}
+		ready, err := r.upgradeBatch(ctx, sizes, target, source)
+		if err != nil || !ready {
+			return err
+		}
+		status.BatchRollingState = v1alpha1.BatchReady
+		r.recorder.Event(r.parent, event.Normal(reasonBatchReady, fmt.Sprintf("batch %d is ready", status.CurrentBatch)))
+
+	case v1alpha1.BatchReady:
+		status.BatchRollingState = v1alpha1.BatchVerifying
+
+	case v1alpha1.BatchVerifying:
+		var batchMetrics []v1alpha1.CanaryMetric
+		if int(status.CurrentBatch) < len(r.rolloutSpec.RolloutBatches) {
+			batchMetrics = r.rolloutSpec.RolloutBatches[status.CurrentBatch].CanaryMetric
+		}
+		passed, err := r.checkCanaryMetrics(ctx, batchMetrics, status.CurrentBatch)
+		if err != nil || !passed {
+			return err
+		}
+		status.BatchRollingState = v1alpha1.BatchAvailable
+		r.notifyWebhooks(ctx, v1alpha1.PostBatchRolloutHook, status.CurrentBatch)
+
+	case v1alpha1.BatchAvailable:
+		if int(status.CurrentBatch) == len(sizes)-1 {
+			status.RollingState = v1alpha1.Finalising
+			return nil
+		}
+		if r.rolloutSpec.BatchPartition != nil && status.CurrentBatch >= *r.rolloutSpec.BatchPartition {
+			klog.InfoS("the rollout is waiting for the batch partition to move", "batch", status.CurrentBatch,
+				"partition", *r.rolloutSpec.BatchPartition)
+			return nil
+		}
+		status.CurrentBatch++
+		status.BatchRollingState = v1alpha1.BatchRolling
+		r.recorder.Event(r.parent, event.Normal(reasonBatchStarted, fmt.Sprintf("batch %d started", status.CurrentBatch)))
+
+	default:
+		r.fail(fmt.Errorf("unknown batch rolling state %s", status.BatchRollingState))
+	}
+	return nil
+}
+
+// upgradeBatch scales both workloads to the size of the current batch, it returns true once the batch is ready
+func (r *Controller) upgradeBatch(ctx context.Context, sizes []int32, target, source workloads.WorkloadController) (bool, error) {
+	status := r.rolloutStatus
+	targetSize := sizes[status.CurrentBatch]
+	sourceSize := status.RolloutTargetSize - targetSize
+	decreaseFirst := r.rolloutSpec.RolloutStrategy != nil &&
+		*r.rolloutSpec.RolloutStrategy == v1alpha1.DecreaseFirstRolloutStrategyType
+
+	if decreaseFirst && source != nil {
+		if err := source.Scale(ctx, sourceSize); err != nil {
+			return false, err
+		}
+	}
+	if err := target.Scale(ctx, targetSize); err != nil {
+		return false, err
+	}
+	status.UpgradedReplicas = targetSize
+
+	readySize, err := target.ReadySize()
+	if err != nil {
+		return false, err
+	}
+	status.UpgradedReadyReplicas = readySize
+	var batchSize = targetSize
+	if status.CurrentBatch > 0 {
+		batchSize -= sizes[status.CurrentBatch-1]
+	}
+	if readySize < targetSize-maxUnavailable(r.rolloutSpec, status.CurrentBatch, batchSize) {
+		klog.InfoS("the batch is not ready yet", "batch", status.CurrentBatch, "ready", readySize, "desired", targetSize)
+		return false, nil
+	}
+
+	if !decreaseFirst && source != nil {
+		if err := source.Scale(ctx, sourceSize); err != nil {
+			return false, err
+		}
+	}
+	return true, nil
+}
+
+// checkCanaryMetrics checks all the metrics and records the observed values in the status. It fails the rollout
+// and returns false as soon as one of the metrics is out of its expected range.
+func (r *Controller) checkCanaryMetrics(ctx context.Context, canaryMetrics []v1alpha1.CanaryMetric, batch int32) (bool, error) {
+	vars := metrics.QueryVariables{
+		Namespace: r.targetWorkload.GetNamespace(),
+		Workload:  r.targetWorkload.GetName(),
+	}
+	for _, metric := range canaryMetrics {
+		result, err := metrics.Check(ctx, r.client, metric, vars)
+		if err != nil {
+			return false, err
+		}
+		result.Batch = batch
+		r.recordCanaryMetricResult(*result)
+		if !result.Passed {
+			r.fail(fmt.Errorf("canary metric %s failed: %s", metric.Name, result.Message))
+			return false, nil
+		}
+	}
+	return true, nil
+}
+
+// recordCanaryMetricResult keeps the latest result of each metric of each batch in the status
+func (r *Controller) recordCanaryMetricResult(result v1alpha1.CanaryMetricResult) {
+	results := r.rolloutStatus.CanaryMetricResults
+	for i := range results {
+		if results[i].Name == result.Name && results[i].Batch == result.Batch {
+			results[i] = result
+			return
+		}
+	}
+	r.rolloutStatus.CanaryMetricResults = append(results, result)
+}
+
+// webhooks returns the webhooks of the type, the batch hooks come from both the plan and the batch
+func (r *Controller) webhooks(hookType v1alpha1.HookType, batch int32) []v1alpha1.RolloutWebhook {
+	hooks := r.rolloutSpec.RolloutWebhooks
+	if batch >= 0 && int(batch) < len(r.rolloutSpec.RolloutBatches) {
+		hooks = append(append([]v1alpha1.RolloutWebhook{}, hooks...), r.rolloutSpec.RolloutBatches[batch].BatchRolloutWebhooks...)
+	}
+	var selected []v1alpha1.RolloutWebhook
+	for _, hook := range hooks {
+		if hook.Type == hookType {
+			selected = append(selected, hook)
+		}
+	}
+	return selected
+}
+
+// gateOnWebhooks calls the webhooks that haven't succeeded yet, it returns false until all of them succeed so that
+// the rollout won't move forward. A failed webhook is called again once it's time to retry, the rollout fails once
+// the webhook uses up all its attempts.
+func (r *Controller) gateOnWebhooks(ctx context.Context, hookType v1alpha1.HookType, batch int32) (bool, error) {
+	for _, hook := range r.webhooks(hookType, batch) {
+		last := r.webhookResult(hook.Name, hook.Type, batch)
+		if last != nil && last.Succeeded {
+			continue
+		}
+		if exhaustedWebhook(hook, last) {
+			r.fail(fmt.Errorf("the rollout is rejected by the %s webhook %s: %s", hookType, hook.Name, last.Message))
+			return false, nil
+		}
+		if !retryDue(last) {
+			return false, nil
+		}
+		result := r.callWebhook(ctx, hook, batch)
+		if exhaustedWebhook(hook, &result) {
+			r.fail(fmt.Errorf("the rollout is rejected by the %s webhook %s: %s", hookType, hook.Name, result.Message))
+			return false, nil
+		}
+		if !result.Succeeded {
+			return false, fmt.Errorf("the rollout is blocked by the %s webhook %s: %s", hookType, hook.Name, result.Message)
+		}
+	}
+	return true, nil
+}
+
+// notifyWebhooks calls all the webhooks, a failed call is recorded and retried later but doesn't stop the rollout
+func (r *Controller) notifyWebhooks(ctx context.Context, hookType v1alpha1.HookType, batch int32) {
+	for _, hook := range r.webhooks(hookType, batch) {
+		r.notifyWebhook(ctx, hook, batch)
+	}
+}
+
+// retryNotifications calls the notification webhooks that failed before and are due to retry
+func (r *Controller) retryNotifications(ctx context.Context) {
+	for _, result := range append([]v1alpha1.RolloutWebhookResult{}, r.rolloutStatus.WebhookResults...) {
+		if result.Type != v1alpha1.PostBatchRolloutHook && result.Type != v1alpha1.FinalizeRolloutHook {
+			continue
+		}
+		if result.Succeeded || result.NextRetryTime == nil || !retryDue(&result) {
+			continue
+		}
+		for _, hook := range r.webhooks(result.Type, result.Batch) {
+			if hook.Name == result.Name {
+				r.notifyWebhook(ctx, hook, result.Batch)
+			}
+		}
+	}
+}
+
+func (r *Controller) notifyWebhook(ctx context.Context, hook v1alpha1.RolloutWebhook, batch int32) {
+	result := r.callWebhook(ctx, hook, batch)
+	if !result.Succeeded {
+		klog.InfoS("failed to notify the webhook", "webhook", hook.Name, "type", hook.Type, "message", result.Message)
+		r.recorder.Event(r.parent, event.Warning(reasonWebhookFailed, errors.New(result.Message)))
+	}
+}
+
+func (r *Controller) callWebhook(ctx context.Context, hook v1alpha1.RolloutWebhook, batch int32) v1alpha1.RolloutWebhookResult {
+	payload := v1alpha1.RolloutWebhookPayload{
+		ResourceRef: &cpv1alpha1.TypedReference{
+			APIVersion: r.targetWorkload.GetAPIVersion(),
+			Kind:       r.targetWorkload.GetKind(),
+			Name:       r.targetWorkload.GetName(),
+			UID:        r.targetWorkload.GetUID(),
+		},
+		Type:  hook.Type,
+		Batch: batch,
+	}
+	if parent, err := meta.Accessor(r.parent); err == nil {
+		apiVersion, kind := r.parent.GetObjectKind().GroupVersionKind().ToAPIVersionAndKind()
+		payload.RolloutRef = &cpv1alpha1.TypedReference{
+			APIVersion: apiVersion,
+			Kind:       kind,
+			Name:       parent.GetName(),
+			UID:        parent.GetUID(),
+		}
+	}
+	result := CallWebhook(ctx, r.client, r.targetWorkload.GetNamespace(), hook, payload,
+		r.webhookResult(hook.Name, hook.Type, batch))
+	r.recordWebhookResult(result)
+	return result
+}
+
+// webhookResult returns the latest result of the webhook of the batch, it returns nil if it's never called
+func (r *Controller) webhookResult(name string, hookType v1alpha1.HookType, batch int32) *v1alpha1.RolloutWebhookResult {
+	for i, result := range r.rolloutStatus.WebhookResults {
+		if result.Name == name && result.Type == hookType && result.Batch == batch {
+			return &r.rolloutStatus.WebhookResults[i]
+		}
+	}
+	return nil
+}
+
+// retryDue returns true if the webhook can be called now
+func retryDue(last *v1alpha1.RolloutWebhookResult) bool {
+	return last == nil || last.NextRetryTime == nil || !time.Now().Before(last.NextRetryTime.Time)
+}
+
+// recordWebhookResult keeps the latest result of each webhook of each batch in the status
+func (r *Controller) recordWebhookResult(result v1alpha1.RolloutWebhookResult) {
+	if last := r.webhookResult(result.Name, result.Type, result.Batch); last != nil {
+		*last = result
+		return
+	}
+	r.rolloutStatus.WebhookResults = append(r.rolloutStatus.WebhookResults, result)
+}
+
+// requeueAfter returns the time to wait before the next reconcile, it's shortened so that the failed webhooks are
+// retried in time. A zero interval means the rollout doesn't need another reconcile except for the retries.
+func (r *Controller) requeueAfter(interval time.Duration) reconcile.Result {
+	now := time.Now()
+	for _, result := range r.rolloutStatus.WebhookResults {
+		if result.Succeeded || result.NextRetryTime == nil || !now.Before(result.NextRetryTime.Time) {
+			continue
+		}
+		if wait := result.NextRetryTime.Sub(now); interval == 0 || wait < interval {
+			interval = wait
+		}
+	}
+	return reconcile.Result{RequeueAfter: interval}
+}
+
+// finalize makes sure that the target has all the pods and the source has none
+func (r *Controller) finalize(ctx context.Context, target, source workloads.WorkloadController) error {
+	// the metrics of the plan are checked before the rollout is completed
+	passed, err := r.checkCanaryMetrics(ctx, r.rolloutSpec.CanaryMetric, -1)
+	if err != nil || !passed {
+		return err
+	}
+	if err := target.Scale(ctx, r.rolloutStatus.RolloutTargetSize); err != nil {
+		return err
+	}
+	if source != nil {
+		if err := source.Scale(ctx, 0); err != nil {
+			return err
+		}
+	}
+	// the replicas of the target are managed by its ApplicationConfiguration again, the source is kept marked so that
+	// it's not scaled up again
+	if err := r.unmarkRollout(ctx, target); err != nil {
+		return err
+	}
+	r.rolloutStatus.RollingState = v1alpha1.Succeed
+	r.notifyWebhooks(ctx, v1alpha1.FinalizeRolloutHook, -1)
+	r.recorder.Event(r.parent, event.Normal(reasonRolloutSucceed,
+		fmt.Sprintf("rollout succeed, %d pods are upgraded", r.rolloutStatus.RolloutTargetSize)))
+	return nil
+}
+
+// sizeBeforeRollout returns the size of the workload before the rollout, which is recorded when the rollout is
+// initialized as the workload is scaled by the rollout afterwards, e.g. when the rollout is restarted
+func sizeBeforeRollout(w workloads.WorkloadController) (int32, error) {
+	workload := w.GetWorkload()
+	recorded, ok := workload.GetAnnotations()[oam.AnnotationRolloutReplicas]
+	if !ok {
+		return w.Size()
+	}
+	size, err := strconv.ParseInt(recorded, 10, 32)
+	if err != nil {
+		return 0, errors.Wrapf(err, "invalid annotation %s of workload %s", oam.AnnotationRolloutReplicas,
+			workload.GetName())
+	}
+	return int32(size), nil
+}
+
+// markRollout records the size of the workload before the rollout in its annotation, the ApplicationConfiguration
+// of the workload doesn't change its replicas until the annotation is removed
+func (r *Controller) markRollout(ctx context.Context, w workloads.WorkloadController) error {
+	workload := w.GetWorkload()
+	if _, ok := workload.GetAnnotations()[oam.AnnotationRolloutReplicas]; ok {
+		return nil
+	}
+	size, err := w.Size()
+	if err != nil {
+		return err
+	}
+	patch := client.MergeFrom(workload.DeepCopyObject())
+	annotations := workload.GetAnnotations()
+	if annotations == nil {
+		annotations = map[string]string{}
+	}
+	annotations[oam.AnnotationRolloutReplicas] = strconv.Itoa(int(size))
+	workload.SetAnnotations(annotations)
+	return errors.Wrapf(r.client.Patch(ctx, workload, patch), "cannot mark workload %s", workload.GetName())
+}
+
+func (r *Controller) unmarkRollout(ctx context.Context, w workloads.WorkloadController) error {
+	workload := w.GetWorkload()
+	annotations := workload.GetAnnotations()
+	if _, ok := annotations[oam.AnnotationRolloutReplicas]; !ok {
+		return nil
+	}
+	patch := client.MergeFrom(workload.DeepCopyObject())
+	delete(annotations, oam.AnnotationRolloutReplicas)
+	workload.SetAnnotations(annotations)
+	return errors.Wrapf(r.client.Patch(ctx, workload, patch), "cannot unmark workload %s", workload.GetName())
+}
+
+// fail marks the rollout as failed, we won't move forward anymore and leave it to the user to decide what's next
+func (r *Controller) fail(err error) {
+	klog.ErrorS(err, "rollout failed", "batch", r.rolloutStatus.CurrentBatch)
+	r.rolloutStatus.RollingState = v1alpha1.Failed
+	r.rolloutStatus.SetConditions(cpv1alpha1.ReconcileError(err))
+	r.recorder.Event(r.parent, event.Warning(reasonRolloutFailed, err))
+}
@@ -0,0 +1,397 @@
+package rollout
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	runtimev1alpha1 "github.com/crossplane/crossplane-runtime/apis/core/v1alpha1"
+	"github.com/crossplane/crossplane-runtime/pkg/event"
+	"github.com/stretchr/testify/assert"
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/apimachinery/pkg/util/intstr"
+	"k8s.io/client-go/kubernetes/scheme"
+	"k8s.io/utils/pointer"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+
+	"github.com/oam-dev/kubevela/apis/standard.oam.dev/v1alpha1"
+	"github.com/oam-dev/kubevela/pkg/controller/common/rollout/metrics"
+	"github.com/oam-dev/kubevela/pkg/oam"
+)
+
+func newDeployment(name string, replicas int32) *unstructured.Unstructured {
+	u := &unstructured.Unstructured{}
+	u.SetGroupVersionKind(appsv1.SchemeGroupVersion.WithKind("Deployment"))
+	u.SetName(name)
+	u.SetNamespace("default")
+	_ = unstructured.SetNestedField(u.Object, int64(replicas), "spec", "replicas")
+	return u
+}
+
+func getDeployment(t *testing.T, c client.Client, name string) *unstructured.Unstructured {
+	u := &unstructured.Unstructured{}
+	u.SetGroupVersionKind(appsv1.SchemeGroupVersion.WithKind("Deployment"))
+	assert.NoError(t, c.Get(context.Background(), types.NamespacedName{Namespace: "default", Name: name}, u))
+	return u
+}
+
+// markReady pretends that all the pods of the deployment are ready
+func markReady(t *testing.T, c client.Client, name string) {
+	u := getDeployment(t, c, name)
+	replicas, _, _ := unstructured.NestedInt64(u.Object, "spec", "replicas")
+	_ = unstructured.SetNestedField(u.Object, replicas, "status", "readyReplicas")
+	assert.NoError(t, c.Update(context.Background(), u))
+}
+
+func replicasOf(t *testing.T, c client.Client, name string) int64 {
+	replicas, _, _ := unstructured.NestedInt64(getDeployment(t, c, name).Object, "spec", "replicas")
+	return replicas
+}
+
+func TestRolloutPlanController(t *testing.T) {
+	ctx := context.Background()
+	c := fake.NewFakeClientWithScheme(scheme.Scheme, newDeployment("target", 4), newDeployment("source", 4))
+	plan := &v1alpha1.RolloutPlan{NumBatches: pointer.Int32Ptr(2), BatchPartition: pointer.Int32Ptr(0)}
+	status := &v1alpha1.RolloutStatus{}
+	parent := newDeployment("parent", 0)
+
+	reconcileOnce := func() {
+		r := NewRolloutPlanController(c, event.NewNopRecorder(), parent, plan, status,
+			getDeployment(t, c, "target"), getDeployment(t, c, "source"))
+		_, status = r.Reconcile(ctx)
+	}
+
+	reconcileOnce()
+	assert.Equal(t, v1alpha1.Initializing, status.RollingState)
+	assert.Equal(t, int32(4), status.RolloutTargetSize)
+
+	reconcileOnce()
+	assert.Equal(t, v1alpha1.Rolling, status.RollingState)
+	assert.Equal(t, int64(0), replicasOf(t, c, "target"))
+
+	// the first batch waits for the target pods to be ready before scaling down the source
+	reconcileOnce()
+	assert.Equal(t, v1alpha1.BatchRolling, status.BatchRollingState)
+	assert.Equal(t, int64(2), replicasOf(t, c, "target"))
+	assert.Equal(t, int64(4), replicasOf(t, c, "source"))
+
+	markReady(t, c, "target")
+	reconcileOnce()
+	assert.Equal(t, v1alpha1.BatchReady, status.BatchRollingState)
+	assert.Equal(t, int64(2), replicasOf(t, c, "source"))
+	assert.Equal(t, int32(2), status.UpgradedReadyReplicas)
+
+	reconcileOnce()
+	assert.Equal(t, v1alpha1.BatchVerifying, status.BatchRollingState)
+	reconcileOnce()
+	assert.Equal(t, v1alpha1.BatchAvailable, status.BatchRollingState)
+
+	// the partition stops the rollout after the first batch
+	reconcileOnce()
+	assert.Equal(t, v1alpha1.BatchAvailable, status.BatchRollingState)
+	assert.Equal(t, int32(0), status.CurrentBatch)
+
+	// pausing the rollout stops the batch
+	plan.BatchPartition = nil
+	plan.Paused = true
+	reconcileOnce()
+	assert.Equal(t, v1alpha1.BatchStopped, status.BatchRollingState)
+
+	// resuming the rollout checks the stopped batch again
+	plan.Paused = false
+	reconcileOnce()
+	assert.Equal(t, v1alpha1.BatchReady, status.BatchRollingState)
+	reconcileOnce()
+	reconcileOnce()
+	assert.Equal(t, v1alpha1.BatchAvailable, status.BatchRollingState)
+	reconcileOnce()
+	assert.Equal(t, int32(1), status.CurrentBatch)
+	assert.Equal(t, v1alpha1.BatchRolling, status.BatchRollingState)
+
+	reconcileOnce()
+	markReady(t, c, "target")
+	reconcileOnce()
+	reconcileOnce()
+	reconcileOnce()
+	reconcileOnce()
+	assert.Equal(t, v1alpha1.Finalising, status.RollingState)
+	reconcileOnce()
+	assert.Equal(t, v1alpha1.Succeed, status.RollingState)
+	assert.Equal(t, int64(4), replicasOf(t, c, "target"))
+	assert.Equal(t, int64(0), replicasOf(t, c, "source"))
+	assert.Equal(t, int32(4), status.UpgradedReplicas)
+}
+
+func TestRolloutPlanControllerSizeBeforeRollout(t *testing.T) {
+	ctx := context.Background()
+	c := fake.NewFakeClientWithScheme(scheme.Scheme, newDeployment("target", 3))
+	plan := &v1alpha1.RolloutPlan{NumBatches: pointer.Int32Ptr(1)}
+	status := &v1alpha1.RolloutStatus{}
+
+	reconcileOnce := func() {
+		r := NewRolloutPlanController(c, event.NewNopRecorder(), newDeployment("parent", 0), plan, status,
+			getDeployment(t, c, "target"), nil)
+		_, status = r.Reconcile(ctx)
+	}
+
+	reconcileOnce()
+	reconcileOnce()
+	assert.Equal(t, v1alpha1.Rolling, status.RollingState)
+	assert.Equal(t, int64(0), replicasOf(t, c, "target"))
+	assert.Equal(t, "3", getDeployment(t, c, "target").GetAnnotations()[oam.AnnotationRolloutReplicas])
+
+	// the restarted rollout takes the size before the rollout instead of the scaled down workload
+	status = &v1alpha1.RolloutStatus{}
+	reconcileOnce()
+	assert.Equal(t, int32(3), status.RolloutTargetSize)
+	reconcileOnce()
+	reconcileOnce()
+	markReady(t, c, "target")
+	for i := 0; i < 5 && status.RollingState != v1alpha1.Succeed; i++ {
+		reconcileOnce()
+	}
+	assert.Equal(t, v1alpha1.Succeed, status.RollingState)
+	assert.Equal(t, int64(3), replicasOf(t, c, "target"))
+	assert.NotContains(t, getDeployment(t, c, "target").GetAnnotations(), oam.AnnotationRolloutReplicas)
+}
+
+func TestRolloutPlanControllerFail(t *testing.T) {
+	c := fake.NewFakeClientWithScheme(scheme.Scheme, newDeployment("target", 4))
+	plan := &v1alpha1.RolloutPlan{NumBatches: pointer.Int32Ptr(-1)}
+	r := NewRolloutPlanController(c, event.NewNopRecorder(), newDeployment("parent", 0), plan,
+		&v1alpha1.RolloutStatus{}, getDeployment(t, c, "target"), nil)
+	_, status := r.Reconcile(context.Background())
+	assert.Equal(t, v1alpha1.Failed, status.RollingState)
+
+	// unsupported workloads fail the rollout
+	target := getDeployment(t, c, "target")
+	target.SetKind("Pod")
+	r = NewRolloutPlanController(c, event.NewNopRecorder(), newDeployment("parent", 0), &v1alpha1.RolloutPlan{},
+		&v1alpha1.RolloutStatus{}, target, nil)
+	_, status = r.Reconcile(context.Background())
+	assert.Equal(t, v1alpha1.Failed, status.RollingState)
+}
+
+func TestRolloutPlanControllerCanaryMetric(t *testing.T) {
+	ctx := context.Background()
+	value := "0.5"
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_, _ = fmt.Fprintf(w, `{"status":"success","data":{"resultType":"scalar","result":[1608000000.1,"%s"]}}`, value)
+	}))
+	defer server.Close()
+	cm := &corev1.ConfigMap{
+		ObjectMeta: metav1.ObjectMeta{Name: "success-rate", Namespace: "default"},
+		Data:       map[string]string{metrics.TemplateAddressKey: server.URL, metrics.TemplateQueryKey: "success_rate"},
+	}
+	c := fake.NewFakeClientWithScheme(scheme.Scheme, newDeployment("target", 2), cm)
+	min := intstr.FromString("0.9")
+	plan := &v1alpha1.RolloutPlan{RolloutBatches: []v1alpha1.RolloutBatch{{
+		Replicas: intstr.FromInt(2),
+		CanaryMetric: []v1alpha1.CanaryMetric{{
+			Name:         "success-rate",
+			TemplateRef:  &runtimev1alpha1.TypedReference{APIVersion: "v1", Kind: "ConfigMap", Name: "success-rate"},
+			MetricsRange: &v1alpha1.MetricsExpectedRange{Min: &min},
+		}},
+	}}}
+	status := &v1alpha1.RolloutStatus{
+		RollingState:      v1alpha1.Rolling,
+		BatchRollingState: v1alpha1.BatchVerifying,
+		RolloutTargetSize: 2,
+	}
+	reconcileOnce := func() {
+		r := NewRolloutPlanController(c, event.NewNopRecorder(), newDeployment("parent", 0), plan, status,
+			getDeployment(t, c, "target"), nil)
+		_, status = r.Reconcile(ctx)
+	}
+
+	// the batch becomes available once the metric is in range
+	value = "0.95"
+	reconcileOnce()
+	assert.Equal(t, v1alpha1.BatchAvailable, status.BatchRollingState)
+	assert.Equal(t, 1, len(status.CanaryMetricResults))
+	assert.Equal(t, "0.95", status.CanaryMetricResults[0].Value)
+
+	// the rollout fails if the metric is out of range
+	status.BatchRollingState = v1alpha1.BatchVerifying
+	value = "0.5"
+	reconcileOnce()
+	assert.Equal(t, v1alpha1.Failed, status.RollingState)
+	assert.Equal(t, 1, len(status.CanaryMetricResults))
+	assert.False(t, status.CanaryMetricResults[0].Passed)
+}
+
+func TestRolloutPlanControllerWebhooks(t *testing.T) {
+	ctx := context.Background()
+	approved := false
+	var notified []v1alpha1.HookType
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var payload v1alpha1.RolloutWebhookPayload
+		_ = json.NewDecoder(r.Body).Decode(&payload)
+		switch payload.Type {
+		case v1alpha1.PreBatchRolloutHook:
+			if !approved {
+				w.WriteHeader(http.StatusForbidden)
+			}
+		default:
+			notified = append(notified, payload.Type)
+			w.WriteHeader(http.StatusInternalServerError)
+		}
+	}))
+	defer server.Close()
+	c := fake.NewFakeClientWithScheme(scheme.Scheme, newDeployment("target", 0))
+	retry := &v1alpha1.RolloutWebhookRetry{Attempts: pointer.Int32Ptr(1)}
+	plan := &v1alpha1.RolloutPlan{
+		RolloutWebhooks: []v1alpha1.RolloutWebhook{
+			{Type: v1alpha1.FinalizeRolloutHook, Name: "notify", URL: server.URL, Retry: retry},
+		},
+		RolloutBatches: []v1alpha1.RolloutBatch{{
+			Replicas: intstr.FromInt(2),
+			BatchRolloutWebhooks: []v1alpha1.RolloutWebhook{
+				{Type: v1alpha1.PreBatchRolloutHook, Name: "approve", URL: server.URL,
+					Retry: &v1alpha1.RolloutWebhookRetry{Attempts: pointer.Int32Ptr(2)}},
+				{Type: v1alpha1.PostBatchRolloutHook, Name: "notify", URL: server.URL, Retry: retry},
+			},
+		}},
+	}
+	status := &v1alpha1.RolloutStatus{
+		RollingState:      v1alpha1.Rolling,
+		BatchRollingState: v1alpha1.BatchRolling,
+		RolloutTargetSize: 2,
+	}
+	reconcileOnce := func() {
+		r := NewRolloutPlanController(c, event.NewNopRecorder(), newDeployment("parent", 0), plan, status,
+			getDeployment(t, c, "target"), nil)
+		_, status = r.Reconcile(ctx)
+	}
+
+	// the pre-batch hook blocks the batch until it's approved
+	reconcileOnce()
+	assert.Equal(t, v1alpha1.BatchRolling, status.BatchRollingState)
+	assert.Equal(t, int64(0), replicasOf(t, c, "target"))
+	assert.Equal(t, 1, len(status.WebhookResults))
+	assert.False(t, status.WebhookResults[0].Succeeded)
+	assert.Equal(t, http.StatusForbidden, status.WebhookResults[0].StatusCode)
+
+	// the approval is checked again after the backoff
+	approved = true
+	past := metav1.NewTime(time.Now().Add(-time.Second))
+	status.WebhookResults[0].NextRetryTime = &past
+	reconcileOnce()
+	assert.Equal(t, int64(2), replicasOf(t, c, "target"))
+	assert.True(t, status.WebhookResults[0].Succeeded)
+
+	// the failed post-batch and finalize hooks don't stop the rollout
+	markReady(t, c, "target")
+	for i := 0; i < 5; i++ {
+		reconcileOnce()
+	}
+	assert.Equal(t, v1alpha1.Succeed, status.RollingState)
+	assert.Equal(t, []v1alpha1.HookType{v1alpha1.PostBatchRolloutHook, v1alpha1.FinalizeRolloutHook}, notified)
+	assert.Equal(t, 3, len(status.WebhookResults))
+	assert.False(t, status.WebhookResults[2].Succeeded)
+	assert.Equal(t, int32(-1), status.WebhookResults[2].Batch)
+}
+
+func TestRolloutPlanControllerWebhookRetry(t *testing.T) {
+	ctx := context.Background()
+	var calls int
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		calls++
+		if calls == 1 {
+			w.WriteHeader(http.StatusServiceUnavailable)
+		}
+	}))
+	defer server.Close()
+	c := fake.NewFakeClientWithScheme(scheme.Scheme, newDeployment("target", 2))
+	plan := &v1alpha1.RolloutPlan{
+		RolloutWebhooks: []v1alpha1.RolloutWebhook{{
+			Type: v1alpha1.FinalizeRolloutHook, Name: "notify", URL: server.URL,
+			Retry: &v1alpha1.RolloutWebhookRetry{Attempts: pointer.Int32Ptr(2), Backoff: "1m"},
+		}},
+		RolloutBatches: []v1alpha1.RolloutBatch{{Replicas: intstr.FromInt(2)}},
+	}
+	status := &v1alpha1.RolloutStatus{RollingState: v1alpha1.Finalising, RolloutTargetSize: 2}
+	reconcileOnce := func() reconcile.Result {
+		r := NewRolloutPlanController(c, event.NewNopRecorder(), newDeployment("parent", 0), plan, status,
+			getDeployment(t, c, "target"), nil)
+		var result reconcile.Result
+		result, status = r.Reconcile(ctx)
+		return result
+	}
+
+	// the failed notification is retried after the backoff instead of blocking the reconcile
+	result := reconcileOnce()
+	assert.Equal(t, v1alpha1.Succeed, status.RollingState)
+	assert.Equal(t, 1, calls)
+	assert.False(t, status.WebhookResults[0].Succeeded)
+	assert.NotNil(t, status.WebhookResults[0].NextRetryTime)
+	assert.True(t, result.RequeueAfter > 59*time.Second && result.RequeueAfter <= time.Minute, result.RequeueAfter)
+
+	// it's not called again before the retry time
+	reconcileOnce()
+	assert.Equal(t, 1, calls)
+
+	past := metav1.NewTime(time.Now().Add(-time.Second))
+	status.WebhookResults[0].NextRetryTime = &past
+	result = reconcileOnce()
+	assert.Equal(t, 2, calls)
+	assert.True(t, status.WebhookResults[0].Succeeded)
+	assert.Equal(t, int32(2), status.WebhookResults[0].Attempts)
+	assert.Equal(t, reconcile.Result{}, result)
+}
+
+func TestRolloutPlanControllerWebhookExhausted(t *testing.T) {
+	ctx := context.Background()
+	var calls int
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		calls++
+		w.WriteHeader(http.StatusForbidden)
+	}))
+	defer server.Close()
+	c := fake.NewFakeClientWithScheme(scheme.Scheme, newDeployment("target", 2))
+	plan := &v1alpha1.RolloutPlan{
+		RolloutWebhooks: []v1alpha1.RolloutWebhook{{
+			Type: v1alpha1.InitializeRolloutHook, Name: "approve", URL: server.URL,
+			Retry: &v1alpha1.RolloutWebhookRetry{Attempts: pointer.Int32Ptr(2), Backoff: "1m"},
+		}},
+		RolloutBatches: []v1alpha1.RolloutBatch{{Replicas: intstr.FromInt(2)}},
+	}
+	status := &v1alpha1.RolloutStatus{RollingState: v1alpha1.Initializing, RolloutTargetSize: 2}
+	reconcileOnce := func() {
+		r := NewRolloutPlanController(c, event.NewNopRecorder(), newDeployment("parent", 0), plan, status,
+			getDeployment(t, c, "target"), nil)
+		_, status = r.Reconcile(ctx)
+	}
+
+	reconcileOnce()
+	assert.Equal(t, v1alpha1.Initializing, status.RollingState)
+	assert.Equal(t, 1, calls)
+	past := metav1.NewTime(time.Now().Add(-time.Second))
+	status.WebhookResults[0].NextRetryTime = &past
+
+	// the rollout fails once the webhook uses up all its attempts
+	reconcileOnce()
+	assert.Equal(t, v1alpha1.Failed, status.RollingState)
+	assert.Equal(t, 2, calls)
+	assert.Nil(t, status.WebhookResults[0].NextRetryTime)
+
+	// the exhausted webhook is never called again
+	for i := 0; i < 3; i++ {
+		reconcileOnce()
+	}
+	status.RollingState = v1alpha1.Initializing
+	reconcileOnce()
+	assert.Equal(t, v1alpha1.Failed, status.RollingState)
+	assert.Equal(t, 2, calls)
+	assert.Equal(t, int64(2), replicasOf(t, c, "target"))
+}
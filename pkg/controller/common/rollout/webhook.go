@@ -0,0 +1,214 @@
+package rollout
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"net/url"
+	"time"
+
+	"github.com/pkg/errors"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	"github.com/oam-dev/kubevela/apis/standard.oam.dev/v1alpha1"
+)
+
+// SignatureHeader is the header that carries the HMAC-SHA256 signature of the request body, in the form of
+// sha256=<hex encoded signature>
+const SignatureHeader = "X-Rollout-Signature"
+
+// the default settings of a webhook call
+const (
+	defaultWebhookTimeout  = "10s"
+	defaultWebhookAttempts = 3
+	defaultWebhookBackoff  = "1s"
+	maxWebhookAttempts     = 10
+	// maxWebhookBackoff caps the time to wait before the next attempt, the backoff doubles after each attempt
+	maxWebhookBackoff = 5 * time.Minute
+)
+
+// webhookCaller sends a request to the webhook, it returns the status code of the response
+type webhookCaller func(ctx context.Context, webhook string, body []byte, signature string) (int, error)
+
+func callWebhook(ctx context.Context, webhook string, body []byte, signature string) (int, error) {
+	hook, err := url.Parse(webhook)
+	if err != nil {
+		return 0, err
+	}
+
+	req, err := http.NewRequestWithContext(ctx, "POST", hook.String(), bytes.NewBuffer(body))
+	if err != nil {
+		return 0, err
+	}
+
+	req.Header.Set("Content-Type", "application/json")
+	if len(signature) != 0 {
+		req.Header.Set(SignatureHeader, "sha256="+signature)
+	}
+
+	r, err := http.DefaultClient.Do(req)
+	if err != nil {
+		return 0, err
+	}
+	defer func() {
+		_ = r.Body.Close()
+	}()
+
+	b, err := ioutil.ReadAll(r.Body)
+	if err != nil {
+		return r.StatusCode, fmt.Errorf("error reading body: %w", err)
+	}
+
+	if r.StatusCode < 200 || r.StatusCode > 299 {
+		return r.StatusCode, fmt.Errorf("unexpected status code %d: %s", r.StatusCode, b)
+	}
+
+	return r.StatusCode, nil
+}
+
+// Sign returns the hex encoded HMAC-SHA256 signature of the body, receivers can use it to verify the request
+func Sign(body, secret []byte) string {
+	mac := hmac.New(sha256.New, secret)
+	_, _ = mac.Write(body)
+	return hex.EncodeToString(mac.Sum(nil))
+}
+
+// webhookSecret reads the shared secret of the webhook from the Secret in the namespace
+func webhookSecret(ctx context.Context, c client.Reader, namespace string, ref *corev1.SecretKeySelector) ([]byte, error) {
+	var secret corev1.Secret
+	if err := c.Get(ctx, types.NamespacedName{Namespace: namespace, Name: ref.Name}, &secret); err != nil {
+		return nil, errors.Wrapf(err, "cannot get the secret %s of the webhook", ref.Name)
+	}
+	key, ok := secret.Data[ref.Key]
+	if !ok || len(key) == 0 {
+		return nil, fmt.Errorf("key %s is not found in the secret %s of the webhook", ref.Key, ref.Name)
+	}
+	return key, nil
+}
+
+// webhookRetry returns the max number of attempts of the webhook and the time to wait before the first retry
+func webhookRetry(w v1alpha1.RolloutWebhook) (int32, time.Duration, error) {
+	attempts, backoff := int32(defaultWebhookAttempts), defaultWebhookBackoff
+	if w.Retry != nil {
+		if w.Retry.Attempts != nil {
+			attempts = *w.Retry.Attempts
+		}
+		if len(w.Retry.Backoff) != 0 {
+			backoff = w.Retry.Backoff
+		}
+	}
+	if attempts < 1 || attempts > maxWebhookAttempts {
+		return 0, 0, fmt.Errorf("the attempts of a webhook must be between 1 and %d", maxWebhookAttempts)
+	}
+	duration, err := time.ParseDuration(backoff)
+	if err != nil {
+		return 0, 0, errors.Wrap(err, "invalid backoff of the webhook")
+	}
+	return attempts, duration, nil
+}
+
+// exhaustedWebhook returns true if the last call of the webhook failed and all its attempts are used up
+func exhaustedWebhook(w v1alpha1.RolloutWebhook, last *v1alpha1.RolloutWebhookResult) bool {
+	if last == nil || last.Succeeded || last.NextRetryTime != nil {
+		return false
+	}
+	attempts, _, err := webhookRetry(w)
+	return err == nil && last.Attempts >= attempts
+}
+
+// retryDelay returns the time to wait after the given number of failed attempts
+func retryDelay(backoff time.Duration, attempts int32) time.Duration {
+	delay := backoff
+	for i := int32(1); i < attempts && delay < maxWebhookBackoff; i++ {
+		delay *= 2
+	}
+	if delay > maxWebhookBackoff {
+		delay = maxWebhookBackoff
+	}
+	return delay
+}
+
+// CallWebhook does a HTTP POST to an external service, the call succeeds if the response status code is 2xx. The
+// body is signed if the webhook has a secret. It never returns an error, every failure is recorded in the result.
+//
+// The webhook is called only once, last is the result of the previous call of the same webhook. A failed call is
+// retried by the next reconcile after the NextRetryTime of the result, the attempts are counted from the last result
+// until the webhook succeeds or all the attempts are used up. The webhook is not called again once all the attempts
+// are used up, the last result is returned instead.
+func CallWebhook(ctx context.Context, c client.Reader, namespace string, w v1alpha1.RolloutWebhook,
+	payload v1alpha1.RolloutWebhookPayload, last *v1alpha1.RolloutWebhookResult) v1alpha1.RolloutWebhookResult {
+	return invokeWebhook(ctx, c, namespace, w, payload, last, callWebhook)
+}
+
+func invokeWebhook(ctx context.Context, c client.Reader, namespace string, w v1alpha1.RolloutWebhook,
+	payload v1alpha1.RolloutWebhookPayload, last *v1alpha1.RolloutWebhookResult, call webhookCaller) v1alpha1.RolloutWebhookResult {
+	result := v1alpha1.RolloutWebhookResult{
+		Name:         w.Name,
+		Type:         w.Type,
+		Batch:        payload.Batch,
+		Attempts:     1,
+		LastCallTime: metav1.Now(),
+	}
+	if exhaustedWebhook(w, last) {
+		return *last
+	}
+	// the attempts of the previous calls count until the webhook gives up retrying
+	if last != nil && !last.Succeeded && last.NextRetryTime != nil {
+		result.Attempts = last.Attempts + 1
+	}
+	fail := func(err error) v1alpha1.RolloutWebhookResult {
+		result.Message = err.Error()
+		return result
+	}
+
+	if w.Metadata != nil {
+		payload.Metadata = *w.Metadata
+	}
+	body, err := json.Marshal(payload)
+	if err != nil {
+		return fail(err)
+	}
+	var signature string
+	if w.SecretRef != nil {
+		secret, err := webhookSecret(ctx, c, namespace, w.SecretRef)
+		if err != nil {
+			return fail(err)
+		}
+		signature = Sign(body, secret)
+	}
+	if len(w.Timeout) < 2 {
+		w.Timeout = defaultWebhookTimeout
+	}
+	timeout, err := time.ParseDuration(w.Timeout)
+	if err != nil {
+		return fail(errors.Wrap(err, "invalid timeout of the webhook"))
+	}
+	attempts, backoff, err := webhookRetry(w)
+	if err != nil {
+		return fail(err)
+	}
+
+	callCtx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+	result.StatusCode, err = call(callCtx, w.URL, body, signature)
+	result.LastCallTime = metav1.Now()
+	if err == nil {
+		result.Succeeded = true
+		return result
+	}
+	if result.Attempts >= attempts {
+		return fail(errors.Wrapf(err, "webhook %s failed after %d attempts", w.Name, result.Attempts))
+	}
+	next := metav1.NewTime(result.LastCallTime.Add(retryDelay(backoff, result.Attempts)))
+	result.NextRetryTime = &next
+	return fail(errors.Wrapf(err, "webhook %s failed in attempt %d of %d", w.Name, result.Attempts, attempts))
+}
@@ -0,0 +1,123 @@
+package rollout
+
+import (
+	"context"
+	"encoding/json"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes/scheme"
+	"k8s.io/utils/pointer"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+
+	"github.com/oam-dev/kubevela/apis/standard.oam.dev/v1alpha1"
+)
+
+func TestCallWebhook(t *testing.T) {
+	ctx := context.Background()
+	var calls int
+	var failures int
+	var signature string
+	var payload v1alpha1.RolloutWebhookPayload
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		calls++
+		signature = r.Header.Get(SignatureHeader)
+		body, _ := ioutil.ReadAll(r.Body)
+		assert.Equal(t, "sha256="+Sign(body, []byte("secret")), signature)
+		_ = json.Unmarshal(body, &payload)
+		if calls <= failures {
+			w.WriteHeader(http.StatusServiceUnavailable)
+		}
+	}))
+	defer server.Close()
+	secret := &corev1.Secret{
+		ObjectMeta: metav1.ObjectMeta{Name: "hook-secret", Namespace: "default"},
+		Data:       map[string][]byte{"token": []byte("secret")},
+	}
+	c := fake.NewFakeClientWithScheme(scheme.Scheme, secret)
+	hook := v1alpha1.RolloutWebhook{
+		Type:      v1alpha1.PreBatchRolloutHook,
+		Name:      "approve",
+		URL:       server.URL,
+		Metadata:  &map[string]string{"env": "prod"},
+		SecretRef: &corev1.SecretKeySelector{LocalObjectReference: corev1.LocalObjectReference{Name: "hook-secret"}, Key: "token"},
+		Retry:     &v1alpha1.RolloutWebhookRetry{Attempts: pointer.Int32Ptr(3), Backoff: "1ms"},
+	}
+
+	// the request is signed and retried until it succeeds
+	failures = 2
+	payload = v1alpha1.RolloutWebhookPayload{}
+	var result v1alpha1.RolloutWebhookResult
+	for i := 1; i <= 3; i++ {
+		var last *v1alpha1.RolloutWebhookResult
+		if i > 1 {
+			last = &result
+		}
+		result = CallWebhook(ctx, c, "default", hook, v1alpha1.RolloutWebhookPayload{Type: hook.Type, Batch: 1}, last)
+		assert.Equal(t, int32(i), result.Attempts)
+		assert.Equal(t, i, calls)
+	}
+	assert.True(t, result.Succeeded, result.Message)
+	assert.Nil(t, result.NextRetryTime)
+	assert.Equal(t, http.StatusOK, result.StatusCode)
+	assert.Equal(t, int32(1), result.Batch)
+	assert.Equal(t, "prod", payload.Metadata["env"])
+	assert.Equal(t, v1alpha1.PreBatchRolloutHook, payload.Type)
+
+	// a failed call is retried after the backoff until all the attempts are used up
+	calls, failures = 0, 5
+	result = CallWebhook(ctx, c, "default", hook, v1alpha1.RolloutWebhookPayload{}, nil)
+	assert.False(t, result.Succeeded)
+	assert.Equal(t, http.StatusServiceUnavailable, result.StatusCode)
+	assert.Equal(t, result.LastCallTime.Add(time.Millisecond), result.NextRetryTime.Time)
+	result = CallWebhook(ctx, c, "default", hook, v1alpha1.RolloutWebhookPayload{}, &result)
+	assert.Equal(t, result.LastCallTime.Add(2*time.Millisecond), result.NextRetryTime.Time)
+	result = CallWebhook(ctx, c, "default", hook, v1alpha1.RolloutWebhookPayload{}, &result)
+	assert.False(t, result.Succeeded)
+	assert.Equal(t, int32(3), result.Attempts)
+	assert.Nil(t, result.NextRetryTime)
+	assert.Contains(t, result.Message, "failed after 3 attempts")
+	// the webhook is not called again once it gives up retrying
+	exhausted := CallWebhook(ctx, c, "default", hook, v1alpha1.RolloutWebhookPayload{}, &result)
+	assert.Equal(t, result, exhausted)
+	assert.Equal(t, 3, calls)
+
+	// the webhook is not called if the secret can't be found
+	calls = 0
+	hook.SecretRef.Key = "unknown"
+	result = CallWebhook(ctx, c, "default", hook, v1alpha1.RolloutWebhookPayload{}, nil)
+	assert.False(t, result.Succeeded)
+	assert.Equal(t, 0, calls)
+	assert.Contains(t, result.Message, "key unknown is not found")
+}
+
+func TestWebhookStatusCode(t *testing.T) {
+	for code, succeeded := range map[int]bool{
+		http.StatusOK:                   true,
+		http.StatusNoContent:            true,
+		http.StatusNonAuthoritativeInfo: true,
+		http.StatusMultipleChoices:      false,
+		http.StatusBadRequest:           false,
+	} {
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			w.WriteHeader(code)
+		}))
+		statusCode, err := callWebhook(context.Background(), server.URL, nil, "")
+		server.Close()
+		assert.Equal(t, code, statusCode)
+		assert.Equal(t, succeeded, err == nil, code)
+	}
+}
+
+func TestRetryDelay(t *testing.T) {
+	assert.Equal(t, time.Second, retryDelay(time.Second, 1))
+	assert.Equal(t, 4*time.Second, retryDelay(time.Second, 3))
+	assert.Equal(t, maxWebhookBackoff, retryDelay(time.Minute, 9))
+	assert.Equal(t, maxWebhookBackoff, retryDelay(time.Hour, 1))
+}
@@ -0,0 +1,48 @@
+package workloads
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/pkg/errors"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+// WorkloadController is the interface that all the workloads that can be rolled out implement
+type WorkloadController interface {
+	// Size returns the number of pods the workload is asked to run
+	Size() (int32, error)
+
+	// ReadySize returns the number of pods of the workload that are ready
+	ReadySize() (int32, error)
+
+	// Scale sets the number of pods the workload should run
+	Scale(ctx context.Context, size int32) error
+
+	// GetWorkload returns the workload object this controller works on
+	GetWorkload() *unstructured.Unstructured
+}
+
+// supportedWorkloads are the workloads that expose their size through `spec.replicas` and
+// report the ready pods through `status.readyReplicas`
+var supportedWorkloads = map[schema.GroupKind]bool{
+	{Group: "apps", Kind: "Deployment"}:         true,
+	{Group: "apps", Kind: "StatefulSet"}:        true,
+	{Group: "apps", Kind: "ReplicaSet"}:         true,
+	{Group: "apps.kruise.io", Kind: "CloneSet"}: true,
+}
+
+// NewWorkloadController creates a WorkloadController for the workload, it returns error if the kind of the workload
+// is not supported
+func NewWorkloadController(c client.Client, workload *unstructured.Unstructured) (WorkloadController, error) {
+	if workload == nil {
+		return nil, errors.New("workload is nil")
+	}
+	gk := workload.GroupVersionKind().GroupKind()
+	if !supportedWorkloads[gk] {
+		return nil, fmt.Errorf("rollout of workload kind %s is not supported", gk.String())
+	}
+	return &replicaController{client: c, workload: workload}, nil
+}
@@ -0,0 +1,54 @@
+package workloads
+
+import (
+	"context"
+
+	"github.com/pkg/errors"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+// replicaController controls the workloads that are scaled through `spec.replicas`
+type replicaController struct {
+	client   client.Client
+	workload *unstructured.Unstructured
+}
+
+// Size returns `spec.replicas` of the workload, kubernetes treats a missing value as 1
+func (c *replicaController) Size() (int32, error) {
+	replicas, found, err := unstructured.NestedInt64(c.workload.Object, "spec", "replicas")
+	if err != nil {
+		return 0, errors.Wrapf(err, "cannot read the replicas of workload %s", c.workload.GetName())
+	}
+	if !found {
+		return 1, nil
+	}
+	return int32(replicas), nil
+}
+
+// ReadySize returns `status.readyReplicas` of the workload
+func (c *replicaController) ReadySize() (int32, error) {
+	ready, _, err := unstructured.NestedInt64(c.workload.Object, "status", "readyReplicas")
+	if err != nil {
+		return 0, errors.Wrapf(err, "cannot read the ready replicas of workload %s", c.workload.GetName())
+	}
+	return int32(ready), nil
+}
+
+// Scale patches `spec.replicas` of the workload, it's a no-op if the workload already has the size
+func (c *replicaController) Scale(ctx context.Context, size int32) error {
+	current, found, err := unstructured.NestedInt64(c.workload.Object, "spec", "replicas")
+	if err == nil && found && int32(current) == size {
+		return nil
+	}
+	patch := client.MergeFrom(c.workload.DeepCopyObject())
+	if err := unstructured.SetNestedField(c.workload.Object, int64(size), "spec", "replicas"); err != nil {
+		return err
+	}
+	return errors.Wrapf(c.client.Patch(ctx, c.workload, patch), "cannot scale workload %s to %d", c.workload.GetName(), size)
+}
+
+// GetWorkload returns the workload
+func (c *replicaController) GetWorkload() *unstructured.Unstructured {
+	return c.workload
+}
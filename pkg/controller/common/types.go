@@ -15,6 +15,8 @@ const (
 	PodspecWorkloadControllerName = "podspecworkload"
 	// RouteControllerName is the controller name of Trait route
 	RouteControllerName = "route"
+	// RolloutControllerName is the controller name of Trait rollout
+	RolloutControllerName = "rollout"
 
 	// DisableAllCaps disable all capabilities
 	DisableAllCaps = "all"
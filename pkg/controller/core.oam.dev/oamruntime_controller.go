@@ -22,6 +22,10 @@ type Args struct {
 	// The default value is 50.
 	RevisionLimit int
 
+	// AppRevisionLimit is the maximum number of ApplicationRevisions that will be maintained for each Application.
+	// The default value is 10.
+	AppRevisionLimit int
+
 	// ApplyOnceOnly indicates whether workloads and traits should be
 	// affected if no spec change is made in the ApplicationConfiguration.
 	ApplyOnceOnly bool
@@ -29,4 +33,8 @@ type Args struct {
 	// CustomRevisionHookURL is a webhook which will let oam-runtime to call with AC+Component info
 	// The webhook server will return a customized component revision for oam-runtime
 	CustomRevisionHookURL string
+
+	// ReadableNamespaces are the namespaces that the processing steps of the templates can read besides the namespace
+	// of the application.
+	ReadableNamespaces []string
 }
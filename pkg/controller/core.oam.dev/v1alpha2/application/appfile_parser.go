@@ -1,11 +1,17 @@
 package application
 
 import (
+	"fmt"
+
 	"github.com/crossplane/crossplane-runtime/apis/core/v1alpha1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
 	"k8s.io/apimachinery/pkg/runtime"
 
 	"github.com/oam-dev/kubevela/apis/core.oam.dev/v1alpha2"
+	"github.com/oam-dev/kubevela/apis/types"
 	"github.com/oam-dev/kubevela/pkg/appfile/config"
+	"github.com/oam-dev/kubevela/pkg/builtin/kube"
+	"github.com/oam-dev/kubevela/pkg/dsl/definition"
 	"github.com/oam-dev/kubevela/pkg/dsl/process"
 	"github.com/oam-dev/kubevela/pkg/oam"
 )
@@ -18,6 +24,14 @@ const (
 // GenerateApplicationConfiguration converts an appFile to applicationConfig & Components
 func (p *Parser) GenerateApplicationConfiguration(app *Appfile, ns string) (*v1alpha2.ApplicationConfiguration,
 	[]*v1alpha2.Component, error) {
+	ac, comps, _, err := p.generateApplicationConfiguration(app, ns, false)
+	return ac, comps, err
+}
+
+// generateApplicationConfiguration renders the appfile, and explains where the fields of the rendered objects come
+// from if explain is true
+func (p *Parser) generateApplicationConfiguration(app *Appfile, ns string, explain bool) (
+	*v1alpha2.ApplicationConfiguration, []*v1alpha2.Component, []Explanation, error) {
 	appconfig := &v1alpha2.ApplicationConfiguration{}
 	appconfig.SetGroupVersionKind(v1alpha2.ApplicationConfigurationGroupVersionKind)
 	appconfig.Name = app.Name
@@ -30,11 +44,25 @@ func (p *Parser) GenerateApplicationConfiguration(app *Appfile, ns string) (*v1a
 	appconfig.Labels[OAMApplicationLabel] = app.Name
 
 	var components []*v1alpha2.Component
+	var explanations []Explanation
 	for _, wl := range app.Workloads {
 
 		pCtx := process.NewContext(wl.Name)
+		pCtx.SetCluster(kube.NamespacedReader(p.client, ns, p.readableNamespaces...), ns)
+		pCtx.SetApplication(process.Application{
+			Name:          app.Name,
+			Revision:      app.RevisionName,
+			ComponentType: wl.Type,
+			Labels:        app.Labels,
+			Annotations:   app.Annotations,
+		})
+		pCtx.SetExplain(explain)
 		userConfig := wl.GetUserConfigName()
 		if userConfig != "" {
+			if p.client == nil {
+				return nil, nil, nil, fmt.Errorf("can't load the config %s of %s without the access to the cluster", userConfig,
+					wl.Name)
+			}
 			cg := config.Configmap{Client: p.client}
 
 			// TODO(wonderflow): envName should not be namespace when we have serverside env
@@ -42,25 +70,30 @@ func (p *Parser) GenerateApplicationConfiguration(app *Appfile, ns string) (*v1a
 
 			data, err := cg.GetConfigData(config.GenConfigMapName(app.Name, wl.Name, userConfig), envName)
 			if err != nil {
-				return nil, nil, err
+				return nil, nil, nil, err
 			}
 			pCtx.SetConfigs(data)
 		}
 
 		if err := wl.EvalContext(pCtx); err != nil {
-			return nil, nil, err
+			cueEvalFailures.WithLabelValues(wl.Type, string(types.TypeWorkload)).Inc()
+			return nil, nil, nil, err
 		}
 		for _, tr := range wl.Traits {
 			if err := tr.EvalContext(pCtx); err != nil {
-				return nil, nil, err
+				cueEvalFailures.WithLabelValues(tr.Name, string(types.TypeTrait)).Inc()
+				return nil, nil, nil, err
 			}
 		}
 		comp, acComp, err := evalWorkloadWithContext(pCtx, wl)
 		if err != nil {
-			return nil, nil, err
+			return nil, nil, nil, err
 		}
 		comp.Name = wl.Name
 		acComp.ComponentName = comp.Name
+		if explain {
+			explanations = append(explanations, explainComponent(pCtx, wl))
+		}
 
 		for _, sc := range wl.Scopes {
 			acComp.Scopes = append(acComp.Scopes, v1alpha2.ComponentScope{ScopeReference: v1alpha1.TypedReference{
@@ -69,6 +102,17 @@ func (p *Parser) GenerateApplicationConfiguration(app *Appfile, ns string) (*v1a
 				Name:       sc.Name,
 			}})
 		}
+		for _, sc := range app.Scopes {
+			if !sc.Match(wl.Name, comp.Spec.Workload.Object.(*unstructured.Unstructured).GetLabels()) ||
+				hasScope(acComp.Scopes, sc.Scope) {
+				continue
+			}
+			acComp.Scopes = append(acComp.Scopes, v1alpha2.ComponentScope{ScopeReference: v1alpha1.TypedReference{
+				APIVersion: sc.GVK.GroupVersion().String(),
+				Kind:       sc.GVK.Kind,
+				Name:       sc.Name,
+			}})
+		}
 
 		comp.Namespace = ns
 		if comp.Labels == nil {
@@ -80,7 +124,35 @@ func (p *Parser) GenerateApplicationConfiguration(app *Appfile, ns string) (*v1a
 		components = append(components, comp)
 		appconfig.Spec.Components = append(appconfig.Spec.Components, *acComp)
 	}
-	return appconfig, components, nil
+	compileDependencies(app, appconfig.Spec.Components)
+	return appconfig, components, explanations, nil
+}
+
+// GenerateScopes renders the application-level scopes into the scope instances to be created
+func (p *Parser) GenerateScopes(app *Appfile, ns string) []*unstructured.Unstructured {
+	var scopes []*unstructured.Unstructured
+	for _, sc := range app.Scopes {
+		scope := &unstructured.Unstructured{Object: map[string]interface{}{}}
+		scope.SetGroupVersionKind(sc.GVK)
+		scope.SetName(sc.Name)
+		scope.SetNamespace(ns)
+		scope.SetLabels(map[string]string{OAMApplicationLabel: app.Name})
+		if sc.Properties != nil {
+			scope.Object["spec"] = sc.Properties
+		}
+		scopes = append(scopes, scope)
+	}
+	return scopes
+}
+
+func hasScope(scopes []v1alpha2.ComponentScope, sc Scope) bool {
+	for _, s := range scopes {
+		if s.ScopeReference.APIVersion == sc.GVK.GroupVersion().String() && s.ScopeReference.Kind == sc.GVK.Kind &&
+			s.ScopeReference.Name == sc.Name {
+			return true
+		}
+	}
+	return false
 }
 
 // evalWorkloadWithContext evaluate the workload's template to generate component and ACComponent
@@ -88,6 +160,7 @@ func evalWorkloadWithContext(pCtx process.Context, wl *Workload) (*v1alpha2.Comp
 	base, assists := pCtx.Output()
 	componentWorkload, err := base.Unstructured()
 	if err != nil {
+		cueEvalFailures.WithLabelValues(wl.Type, string(types.TypeWorkload)).Inc()
 		return nil, nil, err
 	}
 	workloadType := wl.Type
@@ -107,9 +180,18 @@ func evalWorkloadWithContext(pCtx process.Context, wl *Workload) (*v1alpha2.Comp
 	for _, assist := range assists {
 		tr, err := assist.Ins.Unstructured()
 		if err != nil {
+			cueEvalFailures.WithLabelValues(assist.Type, string(types.TypeTrait)).Inc()
 			return nil, nil, err
 		}
-		tr.SetLabels(map[string]string{oam.TraitTypeLabel: assist.Type})
+		if assist.Type == definition.AuxiliaryWorkload {
+			setAuxiliaryWorkload(tr, wl, assist.Name)
+		} else {
+			labels := map[string]string{oam.TraitTypeLabel: assist.Type}
+			if assist.Name != "" {
+				labels[oam.TraitResourceLabel] = assist.Name
+			}
+			tr.SetLabels(labels)
+		}
 		acComponent.Traits = append(acComponent.Traits, v1alpha2.ComponentTrait{
 			Trait: runtime.RawExtension{
 				Object: tr,
@@ -118,3 +200,18 @@ func evalWorkloadWithContext(pCtx process.Context, wl *Workload) (*v1alpha2.Comp
 	}
 	return component, acComponent, nil
 }
+
+// setAuxiliaryWorkload labels an object in the outputs of the workload template with the workload type, the object
+// is named after the component and its key in the outputs unless the template names it
+func setAuxiliaryWorkload(obj *unstructured.Unstructured, wl *Workload, name string) {
+	labels := obj.GetLabels()
+	if labels == nil {
+		labels = map[string]string{}
+	}
+	labels[oam.TraitTypeLabel] = definition.AuxiliaryWorkload
+	labels[oam.WorkloadTypeLabel] = wl.Type
+	obj.SetLabels(labels)
+	if obj.GetName() == "" {
+		obj.SetName(wl.Name + "-" + name)
+	}
+}
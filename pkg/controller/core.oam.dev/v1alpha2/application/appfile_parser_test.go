@@ -19,11 +19,15 @@ package application
 import (
 	"context"
 	"fmt"
+	"io/ioutil"
+	"path/filepath"
+	"testing"
 
 	. "github.com/onsi/ginkgo"
 	. "github.com/onsi/gomega"
 
 	"github.com/crossplane/crossplane-runtime/apis/core/v1alpha1"
+	"github.com/crossplane/crossplane-runtime/pkg/test"
 	"github.com/google/go-cmp/cmp"
 	"github.com/stretchr/testify/assert"
 	corev1 "k8s.io/api/core/v1"
@@ -32,8 +36,13 @@ import (
 	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
 	"k8s.io/apimachinery/pkg/runtime"
 	"k8s.io/apimachinery/pkg/runtime/schema"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
 
+	core "github.com/oam-dev/kubevela/apis/core.oam.dev"
 	"github.com/oam-dev/kubevela/apis/core.oam.dev/v1alpha2"
+	"github.com/oam-dev/kubevela/pkg/oam"
+	"github.com/oam-dev/kubevela/pkg/oam/mock"
 	"github.com/oam-dev/kubevela/pkg/oam/util"
 	// +kubebuilder:scaffold:imports
 )
@@ -235,3 +244,248 @@ var _ = Describe("Test appFile parser", func() {
 	})
 
 })
+
+func TestApplicationScopes(t *testing.T) {
+	s := runtime.NewScheme()
+	assert.NoError(t, core.AddToScheme(s))
+	sd := &v1alpha2.ScopeDefinition{
+		ObjectMeta: metav1.ObjectMeta{Name: "healthscopes.core.oam.dev"},
+		Spec: v1alpha2.ScopeDefinitionSpec{
+			Reference: v1alpha2.DefinitionReference{Name: "healthscopes.core.oam.dev", Version: "v1alpha2"},
+		},
+	}
+	dm := mock.NewMockDiscoveryMapper()
+	dm.MockKindsFor = mock.NewMockKindsFor("HealthScope", "v1alpha2")
+	p := NewApplicationParser(fake.NewFakeClientWithScheme(s, sd), dm)
+
+	app := &v1alpha2.Application{ObjectMeta: metav1.ObjectMeta{Name: "myapp", Namespace: "default"}}
+	app.Spec.Scopes = []v1alpha2.ApplicationScope{{
+		Name:       "myapp-health",
+		Type:       "healthscopes.core.oam.dev",
+		Properties: runtime.RawExtension{Raw: []byte(`{"probe-timeout":10}`)},
+	}, {
+		Name:              "backend-health",
+		Type:              "healthscopes.core.oam.dev",
+		ComponentSelector: &metav1.LabelSelector{MatchLabels: map[string]string{"tier": "backend"}},
+	}}
+	appfile, err := p.GenerateAppFile(app.Name, app)
+	assert.NoError(t, err)
+	assert.Equal(t, 2, len(appfile.Scopes))
+
+	template := `
+output: {
+	apiVersion: "apps/v1"
+	kind:       "Deployment"
+	metadata: labels: tier: parameter.tier
+}
+parameter: tier: string
+`
+	appfile.Workloads = []*Workload{
+		{Name: "frontend", Type: "webservice", Template: template, Params: map[string]interface{}{"tier": "frontend"}},
+		{Name: "backend", Type: "webservice", Template: template, Params: map[string]interface{}{"tier": "backend"},
+			Scopes: []Scope{appfile.Scopes[0].Scope}},
+	}
+	ac, _, err := p.GenerateApplicationConfiguration(appfile, "default")
+	assert.NoError(t, err)
+	scopeRef := func(name string) v1alpha2.ComponentScope {
+		return v1alpha2.ComponentScope{ScopeReference: v1alpha1.TypedReference{
+			APIVersion: "core.oam.dev/v1alpha2", Kind: "HealthScope", Name: name}}
+	}
+	assert.Equal(t, []v1alpha2.ComponentScope{scopeRef("myapp-health")}, ac.Spec.Components[0].Scopes)
+	// the scope declared by both the component and the application is attached only once
+	assert.Equal(t, []v1alpha2.ComponentScope{scopeRef("myapp-health"), scopeRef("backend-health")},
+		ac.Spec.Components[1].Scopes)
+
+	scopes := p.GenerateScopes(appfile, "default")
+	assert.Equal(t, 2, len(scopes))
+	assert.Equal(t, "HealthScope", scopes[0].GetKind())
+	assert.Equal(t, "myapp-health", scopes[0].GetName())
+	assert.Equal(t, map[string]interface{}{"probe-timeout": float64(10)}, scopes[0].Object["spec"])
+	assert.Nil(t, scopes[1].Object["spec"])
+}
+
+func TestAuxiliaryWorkloads(t *testing.T) {
+	appfile := &Appfile{Name: "myapp", Workloads: []*Workload{{
+		Name: "myweb",
+		Type: "webservice",
+		Template: `
+output: {
+	apiVersion: "apps/v1"
+	kind:       "Deployment"
+}
+outputs: {
+	service: {
+		apiVersion: "v1"
+		kind:       "Service"
+		metadata: labels: tier: "frontend"
+	}
+	pdb: {
+		apiVersion: "policy/v1beta1"
+		kind:       "PodDisruptionBudget"
+		metadata: name: "myweb-budget"
+	}
+}`,
+		Traits: []*Trait{{Name: "scaler", Template: `output: {
+	apiVersion: "core.oam.dev/v1alpha2"
+	kind:       "ManualScalerTrait"
+}`}},
+	}}}
+	ac, _, err := NewApplicationParser(nil, nil).GenerateApplicationConfiguration(appfile, "default")
+	assert.NoError(t, err)
+	traits := ac.Spec.Components[0].Traits
+	assert.Equal(t, 3, len(traits))
+
+	svc := traits[0].Trait.Object.(*unstructured.Unstructured)
+	assert.Equal(t, "myweb-service", svc.GetName())
+	assert.Equal(t, map[string]string{"tier": "frontend", oam.TraitTypeLabel: "AuxiliaryWorkload",
+		oam.WorkloadTypeLabel: "webservice"}, svc.GetLabels())
+	pdb := traits[1].Trait.Object.(*unstructured.Unstructured)
+	assert.Equal(t, "myweb-budget", pdb.GetName())
+	assert.Equal(t, "webservice", pdb.GetLabels()[oam.WorkloadTypeLabel])
+	scaler := traits[2].Trait.Object.(*unstructured.Unstructured)
+	assert.Equal(t, map[string]string{oam.TraitTypeLabel: "scaler"}, scaler.GetLabels())
+}
+
+func TestTemplateContext(t *testing.T) {
+	s := runtime.NewScheme()
+	assert.NoError(t, core.AddToScheme(s))
+	app := &v1alpha2.Application{ObjectMeta: metav1.ObjectMeta{Name: "myapp", Namespace: "prod",
+		Labels: map[string]string{"team": "vela"}}}
+	app.Spec.Components = []v1alpha2.ApplicationComponent{{
+		Name:         "myweb",
+		WorkloadType: "webservice",
+		Traits: []v1alpha2.ApplicationTrait{{Name: "scaler", Properties: runtime.RawExtension{Raw: []byte(`{}`)}},
+			{Name: "route", Properties: runtime.RawExtension{Raw: []byte(`{}`)}}},
+	}}
+	p := NewApplicationParser(fake.NewFakeClientWithScheme(s), nil)
+	p.UseRevisionTemplates(&v1alpha2.ApplicationRevision{Spec: v1alpha2.ApplicationRevisionSpec{
+		WorkloadTemplates: []v1alpha2.DefinitionTemplate{{Name: "webservice", Template: `output: {
+	apiVersion: "apps/v1"
+	kind:       "Deployment"
+	metadata: labels: {
+		app:      context.appName
+		revision: context.appRevision
+		type:     context.componentType
+		team:     context.appLabels.team
+	}
+}
+outputs: service: {
+	apiVersion: "v1"
+	kind:       "Service"
+	metadata: namespace: context.namespace
+}`}},
+		TraitTemplates: []v1alpha2.DefinitionTemplate{{Name: "scaler", Template: `output: {
+	apiVersion: "core.oam.dev/v1alpha2"
+	kind:       "ManualScalerTrait"
+	spec: replicaCount: 2
+}`}, {Name: "route", Template: `output: {
+	apiVersion: "standard.oam.dev/v1alpha1"
+	kind:       "Route"
+	spec: {
+		workloadKind: context.input.kind
+		service:      context.outputs.service.kind
+		replicas:     context.traits.scaler.spec.replicaCount
+	}
+}`}},
+	}})
+	rendered, err := p.Render(app)
+	assert.NoError(t, err)
+
+	workload := rendered.Components[0].Spec.Workload.Object.(*unstructured.Unstructured)
+	assert.Equal(t, map[string]string{"app": "myapp", "revision": "myapp-v1", "type": "webservice", "team": "vela",
+		oam.WorkloadTypeLabel: "webservice"}, workload.GetLabels())
+	traits := rendered.AppConfig.Spec.Components[0].Traits
+	assert.Equal(t, 3, len(traits))
+	assert.Equal(t, "prod", traits[0].Trait.Object.(*unstructured.Unstructured).GetNamespace())
+	route := traits[2].Trait.Object.(*unstructured.Unstructured)
+	assert.Equal(t, map[string]interface{}{"workloadKind": "Deployment", "service": "Service", "replicas": int64(2)},
+		route.Object["spec"])
+}
+
+// builtinDefinitions returns a client that reads the built-in webservice and scaler definitions, and an application
+// of three webservice components with a scaler trait. The number of the definitions read by the client is counted.
+func builtinDefinitions(tb testing.TB) (client.Client, *int, *v1alpha2.Application) {
+	dir := "../../../../../charts/vela-core/templates/defwithtemplate"
+	data, err := ioutil.ReadFile(filepath.Join(dir, "webservice.yaml"))
+	if err != nil {
+		tb.Fatal(err)
+	}
+	wd, err := util.UnMarshalStringToWorkloadDefinition(string(data))
+	if err != nil {
+		tb.Fatal(err)
+	}
+	data, err = ioutil.ReadFile(filepath.Join(dir, "manualscale.yaml"))
+	if err != nil {
+		tb.Fatal(err)
+	}
+	td, err := util.UnMarshalStringToTraitDefinition(string(data))
+	if err != nil {
+		tb.Fatal(err)
+	}
+	wd.Generation, td.Generation = 1, 1
+	// the definitions are copied from the memory in the same way as the client reads them from the informers
+	var reads int
+	c := &test.MockClient{
+		MockGet: func(ctx context.Context, key client.ObjectKey, obj runtime.Object) error {
+			reads++
+			switch o := obj.(type) {
+			case *v1alpha2.WorkloadDefinition:
+				wd.DeepCopyInto(o)
+			case *v1alpha2.TraitDefinition:
+				td.DeepCopyInto(o)
+			}
+			return nil
+		},
+	}
+
+	app := &v1alpha2.Application{ObjectMeta: metav1.ObjectMeta{Name: "myapp", Namespace: "default"}}
+	for i := 0; i < 3; i++ {
+		app.Spec.Components = append(app.Spec.Components, v1alpha2.ApplicationComponent{
+			Name:         fmt.Sprintf("myweb%d", i),
+			WorkloadType: wd.Name,
+			Settings:     runtime.RawExtension{Raw: []byte(`{"image":"nginx","env":[{"name":"LOG","value":"debug"}]}`)},
+			Traits: []v1alpha2.ApplicationTrait{{Name: td.Name,
+				Properties: runtime.RawExtension{Raw: []byte(`{"replicas":2}`)}}},
+		})
+	}
+	return c, &reads, app
+}
+
+func TestLoadTemplateOnce(t *testing.T) {
+	c, reads, app := builtinDefinitions(t)
+	templates := util.NewTemplateCache()
+	for i := 1; i <= 2; i++ {
+		p := NewApplicationParser(c, nil)
+		p.UseTemplateCache(templates)
+		appfile, err := p.GenerateAppFile(app.Name, app)
+		assert.NoError(t, err)
+		_, _, err = p.GenerateApplicationConfiguration(appfile, app.Namespace)
+		assert.NoError(t, err)
+		// a parser reads each definition once no matter how many components refer to it
+		assert.Equal(t, 2*i, *reads)
+	}
+}
+
+func BenchmarkGenerateApplicationConfiguration(b *testing.B) {
+	c, _, app := builtinDefinitions(b)
+	run := func(b *testing.B, templates *util.TemplateCache) {
+		for i := 0; i < b.N; i++ {
+			// a parser is created for every reconciliation
+			p := NewApplicationParser(c, nil)
+			p.UseTemplateCache(templates)
+			appfile, err := p.GenerateAppFile(app.Name, app)
+			if err != nil {
+				b.Fatal(err)
+			}
+			if _, _, err := p.GenerateApplicationConfiguration(appfile, app.Namespace); err != nil {
+				b.Fatal(err)
+			}
+		}
+	}
+	b.Run("parse definitions every time", func(b *testing.B) {
+		run(b, nil)
+	})
+	b.Run("template cache", func(b *testing.B) {
+		run(b, util.NewTemplateCache())
+	})
+}
@@ -18,11 +18,17 @@ package application
 
 import (
 	"context"
+	"errors"
 	"fmt"
+	"strings"
+	"time"
 
 	"github.com/crossplane/crossplane-runtime/apis/core/v1alpha1"
+	"github.com/crossplane/crossplane-runtime/pkg/event"
 	"github.com/crossplane/crossplane-runtime/pkg/logging"
+	"github.com/crossplane/crossplane-runtime/pkg/meta"
 	"github.com/go-logr/logr"
+	corev1 "k8s.io/api/core/v1"
 	kerrors "k8s.io/apimachinery/pkg/api/errors"
 	"k8s.io/apimachinery/pkg/runtime"
 	ctrl "sigs.k8s.io/controller-runtime"
@@ -31,18 +37,48 @@ import (
 	"github.com/oam-dev/kubevela/apis/core.oam.dev/v1alpha2"
 	core "github.com/oam-dev/kubevela/pkg/controller/core.oam.dev"
 	"github.com/oam-dev/kubevela/pkg/oam/discoverymapper"
+	"github.com/oam-dev/kubevela/pkg/oam/util"
+	"github.com/oam-dev/kubevela/pkg/utils/apply"
 )
 
+// the conditions of an application, an event is emitted with the condition type as the reason when the condition
+// transitions
+const (
+	conditionParsed      = "Parsed"
+	conditionBuilt       = "Built"
+	conditionApplied     = "Applied"
+	conditionHealthCheck = "HealthCheck"
+	conditionDryRun      = "DryRun"
+)
+
+// the messages of the events emitted when the conditions become ready
+var readyMessages = map[string]string{
+	conditionParsed:      "the application is parsed",
+	conditionBuilt:       "the ApplicationConfiguration and Components are built",
+	conditionApplied:     "the rendered resources are applied",
+	conditionHealthCheck: "all the components are healthy",
+	conditionDryRun:      "the rendered resources are saved for review without being applied",
+}
+
 // Reconciler reconciles a Application object
 type Reconciler struct {
 	dm discoverymapper.DiscoveryMapper
 	client.Client
-	Log    logr.Logger
-	Scheme *runtime.Scheme
+	Log           logr.Logger
+	Scheme        *runtime.Scheme
+	record        event.Recorder
+	revisionLimit int
+	// templates caches the templates parsed from the definitions for all the applications
+	templates *util.TemplateCache
+	// readableNamespaces are the namespaces that the templates can read besides the namespace of the application
+	readableNamespaces []string
 }
 
 // +kubebuilder:rbac:groups=core.oam.dev,resources=applications,verbs=get;list;watch;create;update;patch;delete
 // +kubebuilder:rbac:groups=core.oam.dev,resources=applications/status,verbs=get;update;patch
+// +kubebuilder:rbac:groups=core.oam.dev,resources=applicationrevisions,verbs=get;list;watch;create;delete
+// +kubebuilder:rbac:groups="",resources=events,verbs=create;patch
+// +kubebuilder:rbac:groups="",resources=configmaps,verbs=get;list;watch;create;update;patch;delete
 
 // Reconcile process app event
 func (r *Reconciler) Reconcile(req ctrl.Request) (ctrl.Result, error) {
@@ -61,59 +97,120 @@ func (r *Reconciler) Reconcile(req ctrl.Request) (ctrl.Result, error) {
 	}
 
 	if app.DeletionTimestamp != nil {
-		return ctrl.Result{}, nil
+		return r.finalize(ctx, app)
+	}
+	if !meta.FinalizerExists(&app.ObjectMeta, resourceCleanupFinalizer) {
+		meta.AddFinalizer(&app.ObjectMeta, resourceCleanupFinalizer)
+		return ctrl.Result{}, r.Update(ctx, app)
 	}
 
 	applog.Info("Start Rendering")
 
 	app.Status.Phase = v1alpha2.ApplicationRendering
-	handler := &reter{r.Client, app, applog}
+	handler := &reter{c: r.Client, applicator: apply.NewAPIApplicator(r.Client), app: app, l: applog}
 
+	lastConditions := app.Status.Conditions
 	app.Status.Conditions = []v1alpha1.Condition{}
+	defer r.recordTransitions(app, lastConditions)
 
 	applog.Info("parse template")
 	// parse template
+	renderStart := time.Now()
 	appParser := NewApplicationParser(r.Client, r.dm)
+	appParser.UseTemplateCache(r.templates)
+	appParser.UseReadableNamespaces(r.readableNamespaces)
+	rollback, err := r.rollbackRevision(ctx, app)
+	if err != nil {
+		handler.l.Error(err, "[Handle rollbackRevision]")
+		app.Status.SetConditions(errorCondition(conditionParsed, err))
+		return handler.Err(err)
+	}
+	if rollback != nil {
+		// render the application with the templates of the revision it's rolled back to
+		appParser.UseRevisionTemplates(rollback)
+	}
 
 	appfile, err := appParser.GenerateAppFile(app.Name, app)
 	if err != nil {
 		handler.l.Error(err, "[Handle Parse]")
-		app.Status.SetConditions(errorCondition("Parsed", err))
+		app.Status.SetConditions(errorCondition(conditionParsed, err))
+		return handler.Err(err)
+	}
+
+	// the revision is resolved before rendering as it's in the context of the templates
+	if appfile.RevisionName, err = ResolveRevisionName(ctx, r, app, appfile); err != nil {
+		handler.l.Error(err, "[Handle ResolveRevisionName]")
+		app.Status.SetConditions(errorCondition(conditionParsed, err))
 		return handler.Err(err)
 	}
 
-	app.Status.SetConditions(readyCondition("Parsed"))
+	app.Status.SetConditions(readyCondition(conditionParsed))
 
 	applog.Info("build template")
 	// build template to applicationconfig & component
 	ac, comps, err := appParser.GenerateApplicationConfiguration(appfile, app.Namespace)
 	if err != nil {
 		handler.l.Error(err, "[Handle GenerateApplicationConfiguration]")
-		app.Status.SetConditions(errorCondition("Built", err))
+		app.Status.SetConditions(errorCondition(conditionBuilt, err))
 		return handler.Err(err)
 	}
 
-	app.Status.SetConditions(readyCondition("Built"))
+	app.Status.SetConditions(readyCondition(conditionBuilt))
+	renderDuration.Observe(time.Since(renderStart).Seconds())
+	scopes := appParser.GenerateScopes(appfile, app.Namespace)
+
+	if IsDryRun(app) {
+		applog.Info("save the rendered resources for review instead of applying them")
+		rendered := &RenderedResources{Scopes: scopes, Components: comps, AppConfig: ac}
+		if err := handler.saveDryRun(ctx, rendered); err != nil {
+			handler.l.Error(err, "[Handle saveDryRun]")
+			app.Status.SetConditions(errorCondition(conditionDryRun, err))
+			return handler.Err(err)
+		}
+		app.Status.SetConditions(readyCondition(conditionDryRun))
+		app.Status.Phase = v1alpha2.ApplicationRendered
+		return handler.Err(nil)
+	}
 
-	applog.Info("apply applicationconfig & component to the cluster")
-	// apply applicationconfig & component to the cluster
-	if err := handler.apply(ctx, ac, comps); err != nil {
+	applog.Info("apply applicationconfig, component & scope to the cluster")
+	// apply applicationconfig, component & scope to the cluster
+	if err := handler.apply(ctx, ac, comps, scopes); err != nil {
 		handler.l.Error(err, "[Handle apply]")
-		app.Status.SetConditions(errorCondition("Applied", err))
+		applyErrors.Inc()
+		app.Status.SetConditions(errorCondition(conditionApplied, err))
+		return handler.Err(err)
+	}
+	// record the rendered resources to clean them up when the application is deleted
+	if err := handler.recordResources(ctx, appfile, ac, comps, scopes); err != nil {
+		handler.l.Error(err, "[Handle recordResources]")
+		app.Status.SetConditions(errorCondition(conditionApplied, err))
+		return handler.Err(err)
+	}
+	if err := r.applyRevision(ctx, app, appfile); err != nil {
+		handler.l.Error(err, "[Handle applyRevision]")
+		app.Status.SetConditions(errorCondition(conditionApplied, err))
 		return handler.Err(err)
 	}
 
-	app.Status.SetConditions(readyCondition("Applied"))
+	applied := readyCondition(conditionApplied)
+	if len(handler.drifted) > 0 {
+		applied.Message = fmt.Sprintf("overwrote the drift of %s", strings.Join(handler.drifted, ", "))
+	}
+	app.Status.SetConditions(applied)
 
 	applog.Info("check application health status")
 	// check application health status
-	if err := handler.healthCheck(appfile); err != nil {
-		app.Status.SetConditions(errorCondition("HealthCheck", err))
+	latest := new(v1alpha2.ApplicationConfiguration)
+	if err := r.Get(ctx, client.ObjectKey{Namespace: ac.Namespace, Name: ac.Name}, latest); err != nil {
+		handler.l.Error(err, "[Handle healthCheck]")
+		app.Status.SetConditions(errorCondition(conditionHealthCheck, err))
 		return handler.Err(err)
 	}
-
-	app.Status.SetConditions(readyCondition("HealthCheck"))
-	app.Status.Phase = v1alpha2.ApplicationRunning
+	app.Status.Dependency = latest.Status.Dependency
+	app.Status.Services = handler.healthCheck(ctx, appfile, latest)
+	app.Status.SetConditions(healthCondition(app.Status.Services))
+	app.Status.Phase = aggregateHealth(app.Status.Services)
+	healthChecks.WithLabelValues(string(app.Status.Phase)).Inc()
 	// Gather status of components
 	var refComps []v1alpha1.TypedReference
 	for _, comp := range comps {
@@ -125,11 +222,42 @@ func (r *Reconciler) Reconcile(req ctrl.Request) (ctrl.Result, error) {
 		})
 	}
 	app.Status.Components = refComps
-	return ctrl.Result{}, r.Status().Update(ctx, app)
+	if err := r.Status().Update(ctx, app); err != nil {
+		return ctrl.Result{}, err
+	}
+	return ctrl.Result{RequeueAfter: healthCheckInterval}, nil
+}
+
+// recordTransitions emits an event for every condition of the application whose status or message is changed since
+// the last reconciliation
+func (r *Reconciler) recordTransitions(app *v1alpha2.Application, lastConditions []v1alpha1.Condition) {
+	last := v1alpha1.ConditionedStatus{Conditions: lastConditions}
+	for _, tpy := range []string{conditionParsed, conditionBuilt, conditionApplied, conditionHealthCheck,
+		conditionDryRun} {
+		cond := app.Status.GetCondition(v1alpha1.ConditionType(tpy))
+		if cond.Status == corev1.ConditionUnknown {
+			continue
+		}
+		lastCond := last.GetCondition(v1alpha1.ConditionType(tpy))
+		if cond.Status == lastCond.Status && cond.Message == lastCond.Message {
+			continue
+		}
+		if cond.Status == corev1.ConditionTrue {
+			msg := readyMessages[tpy]
+			if cond.Message != "" {
+				msg = cond.Message
+			}
+			r.record.Event(app, event.Normal(event.Reason(tpy), msg))
+			continue
+		}
+		r.record.Event(app, event.Warning(event.Reason(tpy), errors.New(cond.Message)))
+	}
 }
 
 // SetupWithManager install to manager
 func (r *Reconciler) SetupWithManager(mgr ctrl.Manager) error {
+	r.record = event.NewAPIRecorder(mgr.GetEventRecorderFor("Application")).
+		WithAnnotations("controller", "Application")
 	// If Application Own these two child objects, AC status change will notify application controller and recursively update AC again, and trigger application event again...
 	return ctrl.NewControllerManagedBy(mgr).
 		For(&v1alpha2.Application{}).
@@ -137,16 +265,19 @@ func (r *Reconciler) SetupWithManager(mgr ctrl.Manager) error {
 }
 
 // Setup adds a controller that reconciles ApplicationDeployment.
-func Setup(mgr ctrl.Manager, _ core.Args, _ logging.Logger) error {
+func Setup(mgr ctrl.Manager, args core.Args, _ logging.Logger) error {
 	dm, err := discoverymapper.New(mgr.GetConfig())
 	if err != nil {
 		return fmt.Errorf("create discovery dm fail %w", err)
 	}
 	reconciler := Reconciler{
-		Client: mgr.GetClient(),
-		Log:    ctrl.Log.WithName("Application"),
-		Scheme: mgr.GetScheme(),
-		dm:     dm,
+		Client:             mgr.GetClient(),
+		Log:                ctrl.Log.WithName("Application"),
+		Scheme:             mgr.GetScheme(),
+		dm:                 dm,
+		revisionLimit:      args.AppRevisionLimit,
+		templates:          util.NewTemplateCache(),
+		readableNamespaces: args.ReadableNamespaces,
 	}
 	return reconciler.SetupWithManager(mgr)
 }
@@ -649,6 +649,18 @@ var _ = Describe("Test Application Controller", func() {
 		}
 		reconcileRetry(reconciler, reconcile.Request{NamespacedName: appKey})
 
+		By("record the rendered workload and trait in the status of the AppConfig")
+		appConfig := &v1alpha2.ApplicationConfiguration{}
+		Expect(k8sClient.Get(ctx, appKey, appConfig)).Should(BeNil())
+		appConfig.Status.Workloads = []v1alpha2.WorkloadStatus{{
+			ComponentName: app.Spec.Components[0].Name,
+			Reference:     v1alpha1.TypedReference{APIVersion: "apps/v1", Kind: "Deployment", Name: app.Name},
+			Traits: []v1alpha2.WorkloadTrait{{Reference: v1alpha1.TypedReference{
+				APIVersion: "core.oam.dev/v1alpha2", Kind: "ManualScalerTrait", Name: app.Name}}},
+		}}
+		Expect(k8sClient.Status().Update(ctx, appConfig)).Should(BeNil())
+		reconcileRetry(reconciler, reconcile.Request{NamespacedName: appKey})
+
 		By("Check App running successfully")
 		checkApp := &v1alpha2.Application{}
 		Expect(k8sClient.Get(ctx, appKey, checkApp)).Should(BeNil())
@@ -1,8 +1,13 @@
 package application
 
 import (
+	"fmt"
+	"strings"
+
 	"github.com/pkg/errors"
 	kerrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
 	"k8s.io/apimachinery/pkg/runtime/schema"
 	"sigs.k8s.io/controller-runtime/pkg/client"
 
@@ -10,6 +15,7 @@ import (
 	"github.com/oam-dev/kubevela/apis/types"
 	"github.com/oam-dev/kubevela/pkg/dsl/definition"
 	"github.com/oam-dev/kubevela/pkg/dsl/process"
+	"github.com/oam-dev/kubevela/pkg/oam"
 	"github.com/oam-dev/kubevela/pkg/oam/discoverymapper"
 	"github.com/oam-dev/kubevela/pkg/oam/util"
 )
@@ -26,6 +32,10 @@ type Workload struct {
 	Health   string
 	Traits   []*Trait
 	Scopes   []Scope
+
+	DependsOn []string
+	Outputs   []v1alpha2.ApplicationComponentOutput
+	Inputs    []v1alpha2.ApplicationComponentInput
 }
 
 // GetUserConfigName get user config from AppFile, it will contain config file in it.
@@ -49,9 +59,9 @@ func (wl *Workload) EvalContext(ctx process.Context) error {
 	return definition.NewWDTemplater(wl.Name, wl.Template, "").Params(wl.Params).Complete(ctx)
 }
 
-// EvalHealth eval workload health check
-func (wl *Workload) EvalHealth(ctx process.Context, client client.Client, name string) error {
-	return definition.NewWDTemplater(wl.Name, "", wl.Health).Output(ctx, client, name).HealthCheck()
+// EvalHealth eval workload health check against the workload object rendered in the cluster
+func (wl *Workload) EvalHealth(output map[string]interface{}) (definition.HealthStatus, error) {
+	return definition.NewWDTemplater(wl.Name, "", wl.Health).Output(output).HealthCheck()
 }
 
 // Scope defines the scope of workload
@@ -60,6 +70,23 @@ type Scope struct {
 	GVK  schema.GroupVersionKind
 }
 
+// AppScope is an application-level scope, the workloads selected by it are put into the scope
+type AppScope struct {
+	Scope
+	Properties map[string]interface{}
+	Selector   labels.Selector
+}
+
+// Match checks whether the workload of the component is selected by the scope
+func (sc *AppScope) Match(compName string, workloadLabels map[string]string) bool {
+	set := labels.Set{}
+	for k, v := range workloadLabels {
+		set[k] = v
+	}
+	set[oam.LabelAppComponent] = compName
+	return sc.Selector.Matches(set)
+}
+
 // Trait is ComponentTrait
 type Trait struct {
 	Name     string
@@ -73,15 +100,23 @@ func (trait *Trait) EvalContext(ctx process.Context) error {
 	return definition.NewTDTemplater(trait.Name, trait.Template, "").Params(trait.Params).Complete(ctx)
 }
 
-// EvalHealth eval trait health check
-func (trait *Trait) EvalHealth(ctx process.Context, client client.Client, name string) error {
-	return definition.NewTDTemplater(trait.Name, "", trait.Health).Output(ctx, client, name).HealthCheck()
+// EvalHealth eval trait health check against the trait object rendered in the cluster
+func (trait *Trait) EvalHealth(output map[string]interface{}) (definition.HealthStatus, error) {
+	return definition.NewTDTemplater(trait.Name, "", trait.Health).Output(output).HealthCheck()
 }
 
 // Appfile describes application
 type Appfile struct {
 	Name      string
 	Workloads []*Workload
+	Scopes    []*AppScope
+
+	// Labels and Annotations are of the application, they're in the `context` of the templates
+	Labels      map[string]string
+	Annotations map[string]string
+	// RevisionName is the name of the ApplicationRevision that the application is recorded as, it's resolved by
+	// ResolveRevisionName before rendering the templates
+	RevisionName string
 }
 
 // TemplateValidate validate Template format
@@ -89,10 +124,51 @@ func (af *Appfile) TemplateValidate() error {
 	return nil
 }
 
+// ValidateParameters checks the settings of the workloads and the properties of the traits against the `parameter`
+// of their templates, the invalid fields of all the components are reported together
+func (af *Appfile) ValidateParameters() error {
+	var msgs []string
+	for _, wl := range af.Workloads {
+		// the builtin config isn't a parameter of the template
+		settings := make(map[string]interface{}, len(wl.Params))
+		for k, v := range wl.Params {
+			if k != AppfileBuiltinConfig {
+				settings[k] = v
+			}
+		}
+		if err := definition.ValidateParameters(wl.Template, "settings", settings); err != nil {
+			msgs = append(msgs, fmt.Sprintf("component %s: %s", wl.Name, err))
+		}
+		for _, tr := range wl.Traits {
+			if err := definition.ValidateParameters(tr.Template, "properties", tr.Params); err != nil {
+				msgs = append(msgs, fmt.Sprintf("component %s trait %s: %s", wl.Name, tr.Name, err))
+			}
+		}
+	}
+	if len(msgs) == 0 {
+		return nil
+	}
+	return errors.New(strings.Join(msgs, "\n"))
+}
+
 // Parser is an application parser
 type Parser struct {
 	client client.Client
 	dm     discoverymapper.DiscoveryMapper
+	// revision provides the templates instead of the definitions in the cluster if it's set
+	revision *v1alpha2.ApplicationRevision
+	// templates caches the templates parsed from the definitions in the cluster if it's set
+	templates *util.TemplateCache
+	// loaded keeps the templates loaded from the definitions in the cluster by the parser, so that a definition is
+	// read only once no matter how many components and traits refer to it
+	loaded map[string]loadedTemplate
+	// readableNamespaces are the namespaces that the templates can read besides the namespace of the application
+	readableNamespaces []string
+}
+
+type loadedTemplate struct {
+	template string
+	health   string
 }
 
 // NewApplicationParser create appfile parser
@@ -103,10 +179,70 @@ func NewApplicationParser(cli client.Client, dm discoverymapper.DiscoveryMapper)
 	}
 }
 
+// UseRevisionTemplates makes the parser load the templates from the revision, the types that are not in the
+// revision are still loaded from the definitions in the cluster
+func (p *Parser) UseRevisionTemplates(rev *v1alpha2.ApplicationRevision) {
+	p.revision = rev
+}
+
+// UseTemplateCache makes the parser reuse the templates parsed from the definitions unless they're changed, the
+// cache is shared by the parsers of all the applications
+func (p *Parser) UseTemplateCache(templates *util.TemplateCache) {
+	p.templates = templates
+}
+
+// UseReadableNamespaces allows the processing steps of the templates to read the objects in the namespaces besides
+// the namespace of the application
+func (p *Parser) UseReadableNamespaces(namespaces []string) {
+	p.readableNamespaces = namespaces
+}
+
+// loadTemplate loads the template and the health check of a workload type or a trait
+func (p *Parser) loadTemplate(name string, kind types.CapType) (string, string, error) {
+	if p.revision != nil {
+		templates := p.revision.Spec.WorkloadTemplates
+		if kind == types.TypeTrait {
+			templates = p.revision.Spec.TraitTemplates
+		}
+		for _, t := range templates {
+			if t.Name == name {
+				return t.Template, t.Health, nil
+			}
+		}
+	}
+	if p.client == nil {
+		// the definitions can't be loaded without the access to the cluster
+		resource := "workloaddefinitions"
+		if kind == types.TypeTrait {
+			resource = "traitdefinitions"
+		}
+		return "", "", kerrors.NewNotFound(schema.GroupResource{Group: v1alpha2.Group, Resource: resource}, name)
+	}
+	key := string(kind) + "/" + name
+	if loaded, ok := p.loaded[key]; ok {
+		return loaded.template, loaded.health, nil
+	}
+	load := util.LoadTemplate
+	if p.templates != nil {
+		load = p.templates.LoadTemplate
+	}
+	templ, health, err := load(p.client, name, kind)
+	if err != nil {
+		return "", "", err
+	}
+	if p.loaded == nil {
+		p.loaded = map[string]loadedTemplate{}
+	}
+	p.loaded[key] = loadedTemplate{template: templ, health: health}
+	return templ, health, nil
+}
+
 // GenerateAppFile converts an application to an Appfile
 func (p *Parser) GenerateAppFile(name string, app *v1alpha2.Application) (*Appfile, error) {
 	appfile := new(Appfile)
 	appfile.Name = name
+	appfile.Labels = app.GetLabels()
+	appfile.Annotations = app.GetAnnotations()
 	var wds []*Workload
 	for _, comp := range app.Spec.Components {
 		wd, err := p.parseWorkload(comp)
@@ -117,6 +253,17 @@ func (p *Parser) GenerateAppFile(name string, app *v1alpha2.Application) (*Appfi
 	}
 	appfile.Workloads = wds
 
+	for _, scope := range app.Spec.Scopes {
+		sc, err := p.parseScope(scope)
+		if err != nil {
+			return nil, err
+		}
+		appfile.Scopes = append(appfile.Scopes, sc)
+	}
+
+	if err := validateDependencies(appfile); err != nil {
+		return nil, err
+	}
 	return appfile, nil
 }
 
@@ -125,7 +272,7 @@ func (p *Parser) parseWorkload(comp v1alpha2.ApplicationComponent) (*Workload, e
 	workload.Traits = []*Trait{}
 	workload.Name = comp.Name
 	workload.Type = comp.WorkloadType
-	templ, health, err := util.LoadTemplate(p.client, workload.Type, types.TypeWorkload)
+	templ, health, err := p.loadTemplate(workload.Type, types.TypeWorkload)
 	if err != nil && !kerrors.IsNotFound(err) {
 		return nil, errors.WithMessagef(err, "fetch type of %s", comp.Name)
 	}
@@ -136,6 +283,9 @@ func (p *Parser) parseWorkload(comp v1alpha2.ApplicationComponent) (*Workload, e
 		return nil, errors.WithMessagef(err, "fail to parse settings for %s", comp.Name)
 	}
 	workload.Params = settings
+	workload.DependsOn = comp.DependsOn
+	workload.Outputs = comp.Outputs
+	workload.Inputs = comp.Inputs
 	for _, traitValue := range comp.Traits {
 		properties, err := util.RawExtension2Map(&traitValue.Properties)
 		if err != nil {
@@ -161,8 +311,31 @@ func (p *Parser) parseWorkload(comp v1alpha2.ApplicationComponent) (*Workload, e
 	return workload, nil
 }
 
+func (p *Parser) parseScope(scope v1alpha2.ApplicationScope) (*AppScope, error) {
+	gvk, err := util.GetScopeGVK(p.client, p.dm, scope.Type)
+	if err != nil {
+		return nil, errors.WithMessagef(err, "fetch type of scope %s", scope.Name)
+	}
+	properties, err := util.RawExtension2Map(&scope.Properties)
+	if err != nil {
+		return nil, errors.WithMessagef(err, "fail to parse properties for scope %s", scope.Name)
+	}
+	selector := labels.Everything()
+	if scope.ComponentSelector != nil {
+		selector, err = metav1.LabelSelectorAsSelector(scope.ComponentSelector)
+		if err != nil {
+			return nil, errors.WithMessagef(err, "fail to parse component selector for scope %s", scope.Name)
+		}
+	}
+	return &AppScope{
+		Scope:      Scope{Name: scope.Name, GVK: gvk},
+		Properties: properties,
+		Selector:   selector,
+	}, nil
+}
+
 func (p *Parser) parseTrait(name string, properties map[string]interface{}) (*Trait, error) {
-	templ, health, err := util.LoadTemplate(p.client, name, types.TypeTrait)
+	templ, health, err := p.loadTemplate(name, types.TypeTrait)
 	if kerrors.IsNotFound(err) {
 		return nil, errors.Errorf("trait definition of %s not found", name)
 	}
@@ -4,12 +4,14 @@ import (
 	"context"
 	"fmt"
 	"reflect"
+	"testing"
 
 	. "github.com/onsi/ginkgo"
 	. "github.com/onsi/gomega"
 
 	"github.com/crossplane/crossplane-runtime/pkg/test"
 	"github.com/ghodss/yaml"
+	"github.com/stretchr/testify/assert"
 
 	"k8s.io/apimachinery/pkg/runtime"
 	"k8s.io/apimachinery/pkg/types"
@@ -252,3 +254,24 @@ func equal(af, dest *Appfile) bool {
 	}
 	return true
 }
+
+func TestAppfileValidateParameters(t *testing.T) {
+	appfile := &Appfile{Name: "myapp", Workloads: []*Workload{{
+		Name:     "myweb",
+		Type:     "worker",
+		Template: "output: image: parameter.image\nparameter: image: string",
+		Params:   map[string]interface{}{"image": "nginx", AppfileBuiltinConfig: "myconfig"},
+		Traits: []*Trait{{
+			Name:     "scaler",
+			Template: "output: replicas: parameter.replicas\nparameter: replicas: *1 | int",
+			Params:   map[string]interface{}{"replicas": 2},
+		}},
+	}}}
+	assert.NoError(t, appfile.ValidateParameters())
+
+	appfile.Workloads[0].Params["imag"] = "nginx"
+	appfile.Workloads[0].Traits[0].Params["replicas"] = "2"
+	err := appfile.ValidateParameters()
+	assert.EqualError(t, err, `component myweb: settings.imag: unknown field, expected one of image
+component myweb trait scaler: properties.replicas: expected int, got "2"`)
+}
@@ -9,13 +9,14 @@ import (
 	v1 "k8s.io/api/core/v1"
 	apierrors "k8s.io/apimachinery/pkg/api/errors"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
 	ctypes "k8s.io/apimachinery/pkg/types"
 	"k8s.io/utils/pointer"
 	ctrl "sigs.k8s.io/controller-runtime"
 	"sigs.k8s.io/controller-runtime/pkg/client"
 
 	"github.com/oam-dev/kubevela/apis/core.oam.dev/v1alpha2"
-	"github.com/oam-dev/kubevela/pkg/dsl/process"
+	"github.com/oam-dev/kubevela/pkg/utils/apply"
 )
 
 func errorCondition(tpy string, err error) runtimev1alpha1.Condition {
@@ -38,9 +39,12 @@ func readyCondition(tpy string) runtimev1alpha1.Condition {
 }
 
 type reter struct {
-	c   client.Client
-	app *v1alpha2.Application
-	l   logr.Logger
+	c          client.Client
+	applicator apply.Applicator
+	app        *v1alpha2.Application
+	l          logr.Logger
+	// drifted are the applied resources whose fields set by the application were changed by others
+	drifted []string
 }
 
 func (ret *reter) Err(err error) (ctrl.Result, error) {
@@ -56,7 +60,10 @@ func (ret *reter) Err(err error) (ctrl.Result, error) {
 	}, nil
 }
 
-func (ret *reter) apply(ctx context.Context, ac *v1alpha2.ApplicationConfiguration, comps []*v1alpha2.Component) error {
+// apply applies the rendered resources, the scopes are replaced by the ones in the cluster as the existing scope
+// instances are adopted.
+func (ret *reter) apply(ctx context.Context, ac *v1alpha2.ApplicationConfiguration, comps []*v1alpha2.Component,
+	scopes []*unstructured.Unstructured) error {
 	// set ownerReference for ApplicationConfiguration and Components created by Application
 	owners := []metav1.OwnerReference{{
 		APIVersion: v1alpha2.SchemeGroupVersion.String(),
@@ -69,75 +76,71 @@ func (ret *reter) apply(ctx context.Context, ac *v1alpha2.ApplicationConfigurati
 	for _, c := range comps {
 		c.SetOwnerReferences(owners)
 	}
-	return ret.Sync(ctx, ac, comps)
-}
-
-func (ret *reter) healthCheck(appfile *Appfile) error {
-	for _, wl := range appfile.Workloads {
-		pCtx := process.NewContext(wl.Name)
-		if err := wl.EvalContext(pCtx); err != nil {
+	// the scopes must exist before the ApplicationConfiguration refers to them. They're not owned by the application
+	// as a scope instance can be shared by many applications, the finalizer cleans up the ones created by it.
+	for i, scope := range scopes {
+		applied, err := CreateOrAdoptScope(ctx, ret.c, scope)
+		if err != nil {
 			return err
 		}
-		for _, tr := range wl.Traits {
-			if err := tr.EvalContext(pCtx); err != nil {
-				return err
-			}
-		}
-		if err := wl.EvalHealth(pCtx, ret.c, appfile.Name); err != nil {
-			return err
-		}
-		for _, trait := range wl.Traits {
-			if err := trait.EvalHealth(pCtx, ret.c, appfile.Name); err != nil {
-				return err
-			}
-		}
+		scopes[i] = applied
 	}
-	return nil
+	return ret.Sync(ctx, ac, comps)
 }
 
-// CreateOrUpdateComponent will create if not exist and update if exists.
-func CreateOrUpdateComponent(ctx context.Context, client client.Client, comp *v1alpha2.Component) error {
-	var getc v1alpha2.Component
-	key := ctypes.NamespacedName{Name: comp.Name, Namespace: comp.Namespace}
-	if err := client.Get(ctx, key, &getc); err != nil {
-		if !apierrors.IsNotFound(err) {
-			return err
-		}
-		return client.Create(ctx, comp)
-	}
-	comp.ResourceVersion = getc.ResourceVersion
-	return client.Update(ctx, comp)
+// CreateOrUpdateComponent will create if not exist and patch if exists, the fields not set by the application are
+// preserved. It returns whether the fields set by the application were changed by others since the last apply.
+func CreateOrUpdateComponent(ctx context.Context, applicator apply.Applicator, comp *v1alpha2.Component) (bool, error) {
+	var drifted bool
+	err := applicator.Apply(ctx, comp, apply.DetectDrift(&drifted))
+	return drifted, err
 }
 
-// CreateOrUpdateAppConfig will create if not exist and update if exists.
-func CreateOrUpdateAppConfig(ctx context.Context, client client.Client, appConfig *v1alpha2.ApplicationConfiguration) error {
-	var geta v1alpha2.ApplicationConfiguration
-	key := ctypes.NamespacedName{Name: appConfig.Name, Namespace: appConfig.Namespace}
-	var exist = true
-	if err := client.Get(ctx, key, &geta); err != nil {
+// CreateOrAdoptScope will create the scope instance if not exist, an existing one is adopted as is so that
+// a scope instance can be shared by many applications. It returns the scope instance in the cluster.
+func CreateOrAdoptScope(ctx context.Context, client client.Client, scope *unstructured.Unstructured) (
+	*unstructured.Unstructured, error) {
+	gets := &unstructured.Unstructured{}
+	gets.SetGroupVersionKind(scope.GroupVersionKind())
+	key := ctypes.NamespacedName{Name: scope.GetName(), Namespace: scope.GetNamespace()}
+	if err := client.Get(ctx, key, gets); err != nil {
 		if !apierrors.IsNotFound(err) {
-			return err
+			return nil, err
 		}
-		exist = false
+		return scope, client.Create(ctx, scope)
 	}
-	if !exist {
-		return client.Create(ctx, appConfig)
-	}
-	appConfig.ResourceVersion = geta.ResourceVersion
-	return client.Update(ctx, appConfig)
+	return gets, nil
+}
+
+// CreateOrUpdateAppConfig will create if not exist and patch if exists, the fields not set by the application are
+// preserved. It returns whether the fields set by the application were changed by others since the last apply.
+func CreateOrUpdateAppConfig(ctx context.Context, applicator apply.Applicator,
+	appConfig *v1alpha2.ApplicationConfiguration) (bool, error) {
+	var drifted bool
+	err := applicator.Apply(ctx, appConfig, apply.DetectDrift(&drifted))
+	return drifted, err
 }
 
 // Sync perform synchronization operations
 func (ret *reter) Sync(ctx context.Context, ac *v1alpha2.ApplicationConfiguration, comps []*v1alpha2.Component) error {
+	ret.drifted = nil
 	for _, comp := range comps {
-		if err := CreateOrUpdateComponent(ctx, ret.c, comp.DeepCopy()); err != nil {
+		drifted, err := CreateOrUpdateComponent(ctx, ret.applicator, comp.DeepCopy())
+		if err != nil {
 			return err
 		}
+		if drifted {
+			ret.drifted = append(ret.drifted, "Component "+comp.Name)
+		}
 	}
 
-	if err := CreateOrUpdateAppConfig(ctx, ret.c, ac); err != nil {
+	drifted, err := CreateOrUpdateAppConfig(ctx, ret.applicator, ac.DeepCopy())
+	if err != nil {
 		return err
 	}
+	if drifted {
+		ret.drifted = append(ret.drifted, "ApplicationConfiguration "+ac.Name)
+	}
 
 	// Garbage Collection for no used Components.
 	// There's no need to ApplicationConfiguration Garbage Collection, it has the same name with Application.
@@ -0,0 +1,126 @@
+package application
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	ctypes "k8s.io/apimachinery/pkg/types"
+	"k8s.io/utils/pointer"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+
+	core "github.com/oam-dev/kubevela/apis/core.oam.dev"
+	"github.com/oam-dev/kubevela/apis/core.oam.dev/v1alpha2"
+	"github.com/oam-dev/kubevela/pkg/utils/apply"
+)
+
+func TestCreateOrAdoptScope(t *testing.T) {
+	ctx := context.Background()
+	s := runtime.NewScheme()
+	assert.NoError(t, core.AddToScheme(s))
+	shared := &v1alpha2.HealthScope{}
+	shared.SetName("shared-health")
+	shared.SetNamespace("default")
+	shared.Spec.ProbeTimeout = pointer.Int32Ptr(30)
+	c := fake.NewFakeClientWithScheme(s, shared)
+
+	newScope := func(name string) *unstructured.Unstructured {
+		scope := &unstructured.Unstructured{Object: map[string]interface{}{
+			"spec": map[string]interface{}{"probe-timeout": int64(10)},
+		}}
+		scope.SetGroupVersionKind(v1alpha2.HealthScopeGroupVersionKind)
+		scope.SetName(name)
+		scope.SetNamespace("default")
+		return scope
+	}
+
+	// the scope is created if it doesn't exist
+	applied, err := CreateOrAdoptScope(ctx, c, newScope("app-health"))
+	assert.NoError(t, err)
+	assert.Equal(t, "app-health", applied.GetName())
+	var created v1alpha2.HealthScope
+	assert.NoError(t, c.Get(ctx, ctypes.NamespacedName{Namespace: "default", Name: "app-health"}, &created))
+	assert.Equal(t, int32(10), *created.Spec.ProbeTimeout)
+
+	// the existing scope is adopted as is
+	applied, err = CreateOrAdoptScope(ctx, c, newScope("shared-health"))
+	assert.NoError(t, err)
+	assert.Equal(t, int64(30), applied.Object["spec"].(map[string]interface{})["probe-timeout"])
+	var adopted v1alpha2.HealthScope
+	assert.NoError(t, c.Get(ctx, ctypes.NamespacedName{Namespace: "default", Name: "shared-health"}, &adopted))
+	assert.Equal(t, int32(30), *adopted.Spec.ProbeTimeout)
+}
+
+func TestSyncDrift(t *testing.T) {
+	ctx := context.Background()
+	c := fake.NewFakeClientWithScheme(newCleanupScheme(t))
+	app := &v1alpha2.Application{ObjectMeta: metav1.ObjectMeta{Name: "myapp", Namespace: "default"}}
+	ret := &reter{c: c, applicator: apply.NewAPIApplicator(c), app: app}
+	newRendered := func() (*v1alpha2.ApplicationConfiguration, []*v1alpha2.Component) {
+		ac := &v1alpha2.ApplicationConfiguration{ObjectMeta: metav1.ObjectMeta{Name: "myapp", Namespace: "default"}}
+		ac.SetGroupVersionKind(v1alpha2.ApplicationConfigurationGroupVersionKind)
+		ac.Spec.Components = []v1alpha2.ApplicationConfigurationComponent{{ComponentName: "myweb"}}
+		comp := &v1alpha2.Component{ObjectMeta: metav1.ObjectMeta{Name: "myweb", Namespace: "default"}}
+		comp.SetGroupVersionKind(v1alpha2.ComponentGroupVersionKind)
+		comp.Spec.Workload.Raw = []byte(`{"apiVersion":"apps/v1","kind":"Deployment","spec":{"replicas":1}}`)
+		return ac, []*v1alpha2.Component{comp}
+	}
+	getComp := func() *v1alpha2.Component {
+		var comp v1alpha2.Component
+		assert.NoError(t, c.Get(ctx, ctypes.NamespacedName{Namespace: "default", Name: "myweb"}, &comp))
+		return &comp
+	}
+
+	ac, comps := newRendered()
+	assert.NoError(t, ret.Sync(ctx, ac, comps))
+	assert.Empty(t, ret.drifted)
+
+	// the fields not set by the application are preserved and not regarded as drift
+	comp := getComp()
+	comp.Labels = map[string]string{"team": "web"}
+	assert.NoError(t, c.Update(ctx, comp))
+	var latest v1alpha2.ApplicationConfiguration
+	assert.NoError(t, c.Get(ctx, ctypes.NamespacedName{Namespace: "default", Name: "myapp"}, &latest))
+	latest.Status.ObservedGeneration = 1
+	assert.NoError(t, c.Update(ctx, &latest))
+	ac, comps = newRendered()
+	assert.NoError(t, ret.Sync(ctx, ac, comps))
+	assert.Empty(t, ret.drifted)
+	assert.Equal(t, "web", getComp().Labels["team"])
+
+	// the fields set by the application are overwritten and reported as drift
+	comp = getComp()
+	comp.Spec.Workload.Raw = []byte(`{"apiVersion":"apps/v1","kind":"Deployment","spec":{"replicas":3}}`)
+	assert.NoError(t, c.Update(ctx, comp))
+	ac, comps = newRendered()
+	assert.NoError(t, ret.Sync(ctx, ac, comps))
+	assert.Equal(t, []string{"Component myweb"}, ret.drifted)
+	comp = getComp()
+	assert.JSONEq(t, `{"apiVersion":"apps/v1","kind":"Deployment","spec":{"replicas":1}}`, string(comp.Spec.Workload.Raw))
+	assert.Equal(t, "web", comp.Labels["team"])
+}
+
+func TestApplyScopes(t *testing.T) {
+	ctx := context.Background()
+	c := fake.NewFakeClientWithScheme(newCleanupScheme(t))
+	app := &v1alpha2.Application{ObjectMeta: metav1.ObjectMeta{Name: "myapp", Namespace: "default", UID: "app-uid"}}
+	ret := &reter{c: c, applicator: apply.NewAPIApplicator(c), app: app}
+	ac := &v1alpha2.ApplicationConfiguration{ObjectMeta: metav1.ObjectMeta{Name: "myapp", Namespace: "default"}}
+	ac.SetGroupVersionKind(v1alpha2.ApplicationConfigurationGroupVersionKind)
+	scope := &unstructured.Unstructured{Object: map[string]interface{}{}}
+	scope.SetGroupVersionKind(v1alpha2.HealthScopeGroupVersionKind)
+	scope.SetName("app-health")
+	scope.SetNamespace("default")
+	assert.NoError(t, ret.apply(ctx, ac, nil, []*unstructured.Unstructured{scope}))
+
+	// the scope can be shared, so it's not garbage collected with the application that creates it
+	var created v1alpha2.HealthScope
+	assert.NoError(t, c.Get(ctx, ctypes.NamespacedName{Namespace: "default", Name: "app-health"}, &created))
+	assert.Empty(t, created.OwnerReferences)
+	var appConfig v1alpha2.ApplicationConfiguration
+	assert.NoError(t, c.Get(ctx, ctypes.NamespacedName{Namespace: "default", Name: "myapp"}, &appConfig))
+	assert.Equal(t, ctypes.UID("app-uid"), appConfig.OwnerReferences[0].UID)
+}
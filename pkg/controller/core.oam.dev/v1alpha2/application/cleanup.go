@@ -0,0 +1,238 @@
+package application
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	runtimev1alpha1 "github.com/crossplane/crossplane-runtime/apis/core/v1alpha1"
+	"github.com/crossplane/crossplane-runtime/pkg/meta"
+	"github.com/pkg/errors"
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	ctypes "k8s.io/apimachinery/pkg/types"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	"github.com/oam-dev/kubevela/apis/core.oam.dev/v1alpha2"
+	"github.com/oam-dev/kubevela/pkg/appfile/config"
+	"github.com/oam-dev/kubevela/pkg/oam"
+)
+
+// resourceCleanupFinalizer is added to an Application to clean up the resources rendered by it when it's deleted
+const resourceCleanupFinalizer = "resource.finalizer.core.oam.dev"
+
+// the interval to check whether the resources being deleted are gone
+const waitResourceDeletionInterval = 3 * time.Second
+
+func newAppResource(gvk schema.GroupVersionKind, namespace, name, component string) v1alpha2.AppResource {
+	return v1alpha2.AppResource{
+		APIVersion: gvk.GroupVersion().String(),
+		Kind:       gvk.Kind,
+		Namespace:  namespace,
+		Name:       name,
+		Component:  component,
+	}
+}
+
+func newAppResourceFromReference(ref runtimev1alpha1.TypedReference, namespace, component string) v1alpha2.AppResource {
+	return newAppResource(schema.FromAPIVersionAndKind(ref.APIVersion, ref.Kind), namespace, ref.Name, component)
+}
+
+// recordResources records the resources rendered by the application in the order of their dependencies: the scopes
+// and config ConfigMaps are used by the components, the ApplicationConfiguration is recorded after the workloads and
+// traits so that it's deleted first and won't render them again. The workloads and traits are taken from the status
+// of the ApplicationConfiguration as they're rendered by the ApplicationConfiguration controller.
+//
+// The scopes are the instances in the cluster returned by apply, only the ones created by the application are
+// recorded. The scopes adopted from others are left as is.
+func (ret *reter) recordResources(ctx context.Context, appfile *Appfile, ac *v1alpha2.ApplicationConfiguration,
+	comps []*v1alpha2.Component, scopes []*unstructured.Unstructured) error {
+	ns := ret.app.Namespace
+	var resources []v1alpha2.AppResource
+	for _, scope := range scopes {
+		if scope.GetLabels()[OAMApplicationLabel] != ret.app.Name {
+			continue
+		}
+		resources = append(resources, newAppResource(scope.GroupVersionKind(), ns, scope.GetName(), ""))
+	}
+	for _, wl := range appfile.Workloads {
+		if userConfig := wl.GetUserConfigName(); userConfig != "" {
+			resources = append(resources, newAppResource(corev1.SchemeGroupVersion.WithKind("ConfigMap"), ns,
+				config.GenConfigMapName(appfile.Name, wl.Name, userConfig), wl.Name))
+		}
+	}
+	for _, comp := range comps {
+		resources = append(resources, newAppResource(v1alpha2.ComponentGroupVersionKind, ns, comp.Name, comp.Name))
+	}
+
+	var latest v1alpha2.ApplicationConfiguration
+	if err := ret.c.Get(ctx, ctypes.NamespacedName{Namespace: ac.Namespace, Name: ac.Name}, &latest); err != nil {
+		return err
+	}
+	// the references in the status don't have namespaces, the traits put into other namespaces are found by the
+	// rendered traits
+	traitNamespaces := renderedTraitNamespaces(ac)
+	for _, w := range latest.Status.Workloads {
+		resources = append(resources, newAppResourceFromReference(w.Reference, ns, w.ComponentName))
+		for _, t := range w.Traits {
+			traitNS := ns
+			if rendered, ok := traitNamespaces[t.Reference]; ok {
+				traitNS = rendered
+			}
+			resources = append(resources, newAppResourceFromReference(t.Reference, traitNS, w.ComponentName))
+		}
+	}
+	resources = append(resources, newAppResource(v1alpha2.ApplicationConfigurationGroupVersionKind, ns, ac.Name, ""))
+	ret.app.Status.Resources = resources
+	return nil
+}
+
+// renderedTraitNamespaces returns the namespaces of the rendered traits that set their names and namespaces
+func renderedTraitNamespaces(ac *v1alpha2.ApplicationConfiguration) map[runtimev1alpha1.TypedReference]string {
+	namespaces := map[runtimev1alpha1.TypedReference]string{}
+	for _, comp := range ac.Spec.Components {
+		for _, ct := range comp.Traits {
+			t, ok := ct.Trait.Object.(*unstructured.Unstructured)
+			if !ok {
+				t = &unstructured.Unstructured{}
+				if err := json.Unmarshal(ct.Trait.Raw, &t.Object); err != nil {
+					continue
+				}
+			}
+			if t.GetName() == "" || t.GetNamespace() == "" {
+				continue
+			}
+			ref := runtimev1alpha1.TypedReference{APIVersion: t.GetAPIVersion(), Kind: t.GetKind(), Name: t.GetName()}
+			namespaces[ref] = t.GetNamespace()
+		}
+	}
+	return namespaces
+}
+
+// scopeInUse returns true if the resource is a scope referred by any ApplicationConfiguration in its namespace, the
+// ApplicationConfiguration of the application is deleted before the scopes so it's not counted.
+func scopeInUse(ctx context.Context, c client.Reader, res v1alpha2.AppResource) (bool, error) {
+	if res.Component != "" || res.Kind == v1alpha2.ApplicationConfigurationKind {
+		return false, nil
+	}
+	var acs v1alpha2.ApplicationConfigurationList
+	if err := c.List(ctx, &acs, client.InNamespace(res.Namespace)); err != nil {
+		return false, errors.Wrap(err, "list the ApplicationConfigurations using the scopes")
+	}
+	for _, ac := range acs.Items {
+		for _, comp := range ac.Spec.Components {
+			for _, sc := range comp.Scopes {
+				ref := sc.ScopeReference
+				if ref.APIVersion == res.APIVersion && ref.Kind == res.Kind && ref.Name == res.Name {
+					return true, nil
+				}
+			}
+		}
+	}
+	return false, nil
+}
+
+func resourceObject(res v1alpha2.AppResource) *unstructured.Unstructured {
+	obj := &unstructured.Unstructured{}
+	obj.SetAPIVersion(res.APIVersion)
+	obj.SetKind(res.Kind)
+	obj.SetNamespace(res.Namespace)
+	obj.SetName(res.Name)
+	return obj
+}
+
+// deleteResources deletes the resources in the reverse order of the record, it stops at the first resource that is
+// still terminating and returns the resources that are not deleted yet. A scope is kept if it's still used by other
+// ApplicationConfigurations, as it's shared after the application creates it.
+func deleteResources(ctx context.Context, c client.Client, resources []v1alpha2.AppResource) ([]v1alpha2.AppResource,
+	error) {
+	for i := len(resources) - 1; i >= 0; i-- {
+		obj := resourceObject(resources[i])
+		shared, err := scopeInUse(ctx, c, resources[i])
+		if err != nil {
+			return resources[:i+1], err
+		}
+		if shared {
+			continue
+		}
+		if err := c.Delete(ctx, obj); err != nil && !apierrors.IsNotFound(err) {
+			return resources[:i+1], errors.Wrapf(err, "delete %s %s", obj.GetKind(), obj.GetName())
+		}
+		err = c.Get(ctx, ctypes.NamespacedName{Namespace: obj.GetNamespace(), Name: obj.GetName()}, obj)
+		if err == nil {
+			return resources[:i+1], nil
+		}
+		if !apierrors.IsNotFound(err) {
+			return resources[:i+1], errors.Wrapf(err, "get %s %s", obj.GetKind(), obj.GetName())
+		}
+	}
+	return nil, nil
+}
+
+// orphanResources removes the owner references to the application from the resources so that they're not garbage
+// collected after the application is deleted
+func orphanResources(ctx context.Context, c client.Client, app *v1alpha2.Application) error {
+	for _, res := range app.Status.Resources {
+		obj := resourceObject(res)
+		if err := c.Get(ctx, ctypes.NamespacedName{Namespace: obj.GetNamespace(), Name: obj.GetName()}, obj); err != nil {
+			if apierrors.IsNotFound(err) {
+				continue
+			}
+			return errors.Wrapf(err, "get %s %s", obj.GetKind(), obj.GetName())
+		}
+		var owners []metav1.OwnerReference
+		for _, owner := range obj.GetOwnerReferences() {
+			if owner.UID != app.UID {
+				owners = append(owners, owner)
+			}
+		}
+		if len(owners) == len(obj.GetOwnerReferences()) {
+			continue
+		}
+		obj.SetOwnerReferences(owners)
+		if err := c.Update(ctx, obj); err != nil {
+			return errors.Wrapf(err, "orphan %s %s", obj.GetKind(), obj.GetName())
+		}
+	}
+	return nil
+}
+
+// finalize cleans up the resources rendered by the application before it's deleted, a resource is deleted only
+// after the resources depending on it are gone. The resources are kept if the application has the annotation
+// `app.oam.dev/orphan-resources: "true"`.
+func (r *Reconciler) finalize(ctx context.Context, app *v1alpha2.Application) (ctrl.Result, error) {
+	if !meta.FinalizerExists(&app.ObjectMeta, resourceCleanupFinalizer) {
+		return ctrl.Result{}, nil
+	}
+	if app.GetAnnotations()[oam.AnnotationOrphanResources] == "true" {
+		if err := orphanResources(ctx, r.Client, app); err != nil {
+			return ctrl.Result{}, err
+		}
+	} else {
+		remaining, err := deleteResources(ctx, r.Client, app.Status.Resources)
+		if err != nil || len(remaining) != 0 {
+			app.Status.Phase = v1alpha2.ApplicationDeleting
+			app.Status.Resources = remaining
+			cond := runtimev1alpha1.Deleting()
+			if err != nil {
+				cond = cond.WithMessage(err.Error())
+			} else {
+				waiting := remaining[len(remaining)-1]
+				cond = cond.WithMessage(fmt.Sprintf("waiting for %s %s to be deleted, %d resources left",
+					waiting.Kind, waiting.Name, len(remaining)))
+			}
+			app.Status.SetConditions(cond)
+			if uerr := r.Status().Update(ctx, app); uerr != nil {
+				return ctrl.Result{}, uerr
+			}
+			return ctrl.Result{RequeueAfter: waitResourceDeletionInterval}, err
+		}
+	}
+	meta.RemoveFinalizer(&app.ObjectMeta, resourceCleanupFinalizer)
+	return ctrl.Result{}, r.Update(ctx, app)
+}
@@ -0,0 +1,177 @@
+package application
+
+import (
+	"context"
+	"testing"
+
+	runtimev1alpha1 "github.com/crossplane/crossplane-runtime/apis/core/v1alpha1"
+	"github.com/stretchr/testify/assert"
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	ctypes "k8s.io/apimachinery/pkg/types"
+	clientgoscheme "k8s.io/client-go/kubernetes/scheme"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+
+	core "github.com/oam-dev/kubevela/apis/core.oam.dev"
+	"github.com/oam-dev/kubevela/apis/core.oam.dev/v1alpha2"
+	"github.com/oam-dev/kubevela/pkg/oam"
+	"github.com/oam-dev/kubevela/pkg/utils/apply"
+)
+
+func newCleanupScheme(t *testing.T) *runtime.Scheme {
+	s := runtime.NewScheme()
+	assert.NoError(t, clientgoscheme.AddToScheme(s))
+	assert.NoError(t, core.AddToScheme(s))
+	return s
+}
+
+func TestRecordResources(t *testing.T) {
+	ctx := context.Background()
+	app := &v1alpha2.Application{ObjectMeta: metav1.ObjectMeta{Name: "myapp", Namespace: "default"}}
+	existing := &v1alpha2.ApplicationConfiguration{ObjectMeta: metav1.ObjectMeta{Name: "myapp", Namespace: "default"}}
+	existing.Status.Workloads = []v1alpha2.WorkloadStatus{{
+		ComponentName: "myweb",
+		Reference:     runtimev1alpha1.TypedReference{APIVersion: "apps/v1", Kind: "Deployment", Name: "myweb"},
+		Traits: []v1alpha2.WorkloadTrait{{
+			Reference: runtimev1alpha1.TypedReference{APIVersion: "core.oam.dev/v1alpha2", Kind: "ManualScalerTrait",
+				Name: "myweb-scaler"},
+		}, {
+			Reference: runtimev1alpha1.TypedReference{APIVersion: "v1", Kind: "Secret", Name: "myweb-tls"},
+		}},
+	}}
+	// the scope created by another application is adopted
+	shared := &v1alpha2.HealthScope{ObjectMeta: metav1.ObjectMeta{Name: "shared-health", Namespace: "default",
+		Labels: map[string]string{OAMApplicationLabel: "other"}}}
+	c := fake.NewFakeClientWithScheme(newCleanupScheme(t), existing, shared)
+	ret := &reter{c: c, applicator: apply.NewAPIApplicator(c), app: app}
+
+	// the scopes are rendered with the label of the application as GenerateScopes does
+	scope := func(name string) *unstructured.Unstructured {
+		scope := &unstructured.Unstructured{Object: map[string]interface{}{}}
+		scope.SetGroupVersionKind(v1alpha2.HealthScopeGroupVersionKind)
+		scope.SetName(name)
+		scope.SetNamespace("default")
+		scope.SetLabels(map[string]string{OAMApplicationLabel: "myapp"})
+		return scope
+	}
+	scopes := []*unstructured.Unstructured{scope("myapp-health"), scope("shared-health")}
+	tls := &unstructured.Unstructured{}
+	tls.SetAPIVersion("v1")
+	tls.SetKind("Secret")
+	tls.SetName("myweb-tls")
+	tls.SetNamespace("certs")
+	ac := &v1alpha2.ApplicationConfiguration{ObjectMeta: metav1.ObjectMeta{Name: "myapp", Namespace: "default"}}
+	ac.SetGroupVersionKind(v1alpha2.ApplicationConfigurationGroupVersionKind)
+	ac.Spec.Components = []v1alpha2.ApplicationConfigurationComponent{{
+		ComponentName: "myweb",
+		Traits:        []v1alpha2.ComponentTrait{{Trait: runtime.RawExtension{Object: tls}}},
+	}}
+	comp := &v1alpha2.Component{ObjectMeta: metav1.ObjectMeta{Name: "myweb", Namespace: "default"}}
+	comp.SetGroupVersionKind(v1alpha2.ComponentGroupVersionKind)
+	comp.Spec.Workload.Raw = []byte(`{"apiVersion":"apps/v1","kind":"Deployment"}`)
+	comps := []*v1alpha2.Component{comp}
+	assert.NoError(t, ret.apply(ctx, ac, comps, scopes))
+
+	appfile := &Appfile{Name: "myapp", Workloads: []*Workload{{
+		Name:   "myweb",
+		Params: map[string]interface{}{AppfileBuiltinConfig: "myconfig"},
+	}}}
+	assert.NoError(t, ret.recordResources(ctx, appfile, ac, comps, scopes))
+	assert.Equal(t, []v1alpha2.AppResource{
+		{APIVersion: "core.oam.dev/v1alpha2", Kind: "HealthScope", Namespace: "default", Name: "myapp-health"},
+		{APIVersion: "v1", Kind: "ConfigMap", Namespace: "default", Name: "kubevela-myapp-myweb-myconfig",
+			Component: "myweb"},
+		{APIVersion: "core.oam.dev/v1alpha2", Kind: "Component", Namespace: "default", Name: "myweb",
+			Component: "myweb"},
+		{APIVersion: "apps/v1", Kind: "Deployment", Namespace: "default", Name: "myweb", Component: "myweb"},
+		{APIVersion: "core.oam.dev/v1alpha2", Kind: "ManualScalerTrait", Namespace: "default", Name: "myweb-scaler",
+			Component: "myweb"},
+		{APIVersion: "v1", Kind: "Secret", Namespace: "certs", Name: "myweb-tls", Component: "myweb"},
+		{APIVersion: "core.oam.dev/v1alpha2", Kind: "ApplicationConfiguration", Namespace: "default", Name: "myapp"},
+	}, app.Status.Resources)
+}
+
+func TestFinalize(t *testing.T) {
+	ctx := context.Background()
+	s := newCleanupScheme(t)
+	now := metav1.Now()
+	newApp := func(annotations map[string]string) *v1alpha2.Application {
+		app := &v1alpha2.Application{ObjectMeta: metav1.ObjectMeta{Name: "myapp", Namespace: "default", UID: "app-uid",
+			DeletionTimestamp: &now, Finalizers: []string{resourceCleanupFinalizer}, Annotations: annotations}}
+		app.Status.Resources = []v1alpha2.AppResource{
+			{APIVersion: "core.oam.dev/v1alpha2", Kind: "HealthScope", Namespace: "default", Name: "myapp-health"},
+			{APIVersion: "core.oam.dev/v1alpha2", Kind: "HealthScope", Namespace: "default", Name: "shared-health"},
+			{APIVersion: "v1", Kind: "ConfigMap", Namespace: "default", Name: "kubevela-myapp-myweb-myconfig"},
+			{APIVersion: "core.oam.dev/v1alpha2", Kind: "Component", Namespace: "default", Name: "myweb"},
+			{APIVersion: "apps/v1", Kind: "Deployment", Namespace: "default", Name: "myweb"},
+			{APIVersion: "core.oam.dev/v1alpha2", Kind: "ApplicationConfiguration", Namespace: "default",
+				Name: "myapp"},
+		}
+		return app
+	}
+	owners := []metav1.OwnerReference{{APIVersion: "core.oam.dev/v1alpha2", Kind: "Application", Name: "myapp",
+		UID: "app-uid"}}
+	// the scope created by the application is shared with another application later
+	otherAC := &v1alpha2.ApplicationConfiguration{ObjectMeta: metav1.ObjectMeta{Name: "otherapp", Namespace: "default"}}
+	otherAC.Spec.Components = []v1alpha2.ApplicationConfigurationComponent{{
+		ComponentName: "otherweb",
+		Scopes: []v1alpha2.ComponentScope{{ScopeReference: runtimev1alpha1.TypedReference{
+			APIVersion: "core.oam.dev/v1alpha2", Kind: "HealthScope", Name: "shared-health"}}},
+	}}
+	newObjects := func() []runtime.Object {
+		return []runtime.Object{
+			&v1alpha2.HealthScope{ObjectMeta: metav1.ObjectMeta{Name: "myapp-health", Namespace: "default"}},
+			&v1alpha2.HealthScope{ObjectMeta: metav1.ObjectMeta{Name: "shared-health", Namespace: "default"}},
+			otherAC.DeepCopy(),
+			&corev1.ConfigMap{ObjectMeta: metav1.ObjectMeta{Name: "kubevela-myapp-myweb-myconfig",
+				Namespace: "default"}},
+			&v1alpha2.Component{ObjectMeta: metav1.ObjectMeta{Name: "myweb", Namespace: "default",
+				OwnerReferences: owners}},
+			&appsv1.Deployment{ObjectMeta: metav1.ObjectMeta{Name: "myweb", Namespace: "default"}},
+			&v1alpha2.ApplicationConfiguration{ObjectMeta: metav1.ObjectMeta{Name: "myapp", Namespace: "default",
+				OwnerReferences: owners}},
+		}
+	}
+	req := ctrl.Request{NamespacedName: ctypes.NamespacedName{Namespace: "default", Name: "myapp"}}
+	get := func(c client.Client, obj runtime.Object, name string) error {
+		return c.Get(ctx, ctypes.NamespacedName{Namespace: "default", Name: name}, obj)
+	}
+
+	// all the resources are deleted
+	c := fake.NewFakeClientWithScheme(s, append(newObjects(), newApp(nil))...)
+	r := &Reconciler{Client: c, Scheme: s, Log: ctrl.Log}
+	_, err := r.Reconcile(req)
+	assert.NoError(t, err)
+	assert.True(t, apierrors.IsNotFound(get(c, &corev1.ConfigMap{}, "kubevela-myapp-myweb-myconfig")))
+	assert.True(t, apierrors.IsNotFound(get(c, &v1alpha2.Component{}, "myweb")))
+	assert.True(t, apierrors.IsNotFound(get(c, &appsv1.Deployment{}, "myweb")))
+	assert.True(t, apierrors.IsNotFound(get(c, &v1alpha2.ApplicationConfiguration{}, "myapp")))
+	assert.True(t, apierrors.IsNotFound(get(c, &v1alpha2.HealthScope{}, "myapp-health")))
+	assert.NoError(t, get(c, &v1alpha2.HealthScope{}, "shared-health"))
+	var app v1alpha2.Application
+	assert.NoError(t, get(c, &app, "myapp"))
+	assert.Empty(t, app.Finalizers)
+
+	// the resources are orphaned
+	c = fake.NewFakeClientWithScheme(s, append(newObjects(), newApp(map[string]string{
+		oam.AnnotationOrphanResources: "true"}))...)
+	r = &Reconciler{Client: c, Scheme: s, Log: ctrl.Log}
+	_, err = r.Reconcile(req)
+	assert.NoError(t, err)
+	var comp v1alpha2.Component
+	assert.NoError(t, get(c, &comp, "myweb"))
+	assert.Empty(t, comp.OwnerReferences)
+	var ac v1alpha2.ApplicationConfiguration
+	assert.NoError(t, get(c, &ac, "myapp"))
+	assert.Empty(t, ac.OwnerReferences)
+	assert.NoError(t, get(c, &corev1.ConfigMap{}, "kubevela-myapp-myweb-myconfig"))
+	var orphaned v1alpha2.Application
+	assert.NoError(t, get(c, &orphaned, "myapp"))
+	assert.Empty(t, orphaned.Finalizers)
+}
@@ -0,0 +1,192 @@
+package application
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/pkg/errors"
+
+	"github.com/oam-dev/kubevela/apis/core.oam.dev/v1alpha2"
+)
+
+// createdOutputFieldPath is the field of the workload that is set once the workload is created, it's the output
+// that the dependents wait for when a component has no outputs
+const createdOutputFieldPath = "metadata.uid"
+
+// dataOutputName is the name of the DataOutput in the ApplicationConfiguration compiled from an output of a
+// component, the name of the DataOutput of a component without outputs is the component name
+func dataOutputName(compName, output string) string {
+	if output == "" {
+		return compName
+	}
+	return compName + "." + output
+}
+
+// dependencies returns the components that the workload depends on, including the ones its inputs come from
+func (wl *Workload) dependencies() []string {
+	var deps []string
+	seen := map[string]bool{}
+	add := func(name string) {
+		if !seen[name] {
+			seen[name] = true
+			deps = append(deps, name)
+		}
+	}
+	for _, dep := range wl.DependsOn {
+		add(dep)
+	}
+	for _, in := range wl.Inputs {
+		add(in.Component)
+	}
+	return deps
+}
+
+func (wl *Workload) hasOutput(name string) bool {
+	for _, out := range wl.Outputs {
+		if out.Name == name {
+			return true
+		}
+	}
+	return false
+}
+
+// validateDependencies checks that the dependencies and the outputs the inputs come from exist and that the
+// components don't depend on each other circularly
+func validateDependencies(appfile *Appfile) error {
+	workloads := make(map[string]*Workload, len(appfile.Workloads))
+	for _, wl := range appfile.Workloads {
+		workloads[wl.Name] = wl
+	}
+	for _, wl := range appfile.Workloads {
+		outputs := map[string]bool{}
+		for _, out := range wl.Outputs {
+			if outputs[out.Name] {
+				return errors.Errorf("component(%s) has duplicate output %s", wl.Name, out.Name)
+			}
+			outputs[out.Name] = true
+		}
+		for _, dep := range wl.dependencies() {
+			if dep == wl.Name {
+				return errors.Errorf("component(%s) depends on itself", wl.Name)
+			}
+			if _, ok := workloads[dep]; !ok {
+				return errors.Errorf("component(%s) depends on component %s which doesn't exist", wl.Name, dep)
+			}
+		}
+		for _, in := range wl.Inputs {
+			if !workloads[in.Component].hasOutput(in.Output) {
+				return errors.Errorf("component(%s) has input from output %s of component %s which doesn't exist",
+					wl.Name, in.Output, in.Component)
+			}
+		}
+	}
+
+	// depth-first search for the cycles
+	const (
+		visiting = iota + 1
+		visited
+	)
+	state := make(map[string]int, len(appfile.Workloads))
+	var visit func(name string, path []string) error
+	visit = func(name string, path []string) error {
+		switch state[name] {
+		case visiting:
+			return errors.Errorf("components depend on each other circularly: %s", strings.Join(append(path, name), " -> "))
+		case visited:
+			return nil
+		}
+		state[name] = visiting
+		for _, dep := range workloads[name].dependencies() {
+			if err := visit(dep, append(path, name)); err != nil {
+				return err
+			}
+		}
+		state[name] = visited
+		return nil
+	}
+	for _, wl := range appfile.Workloads {
+		if err := visit(wl.Name, nil); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// compileDependencies compiles the outputs, inputs and dependencies of the workloads into the DataOutputs and
+// DataInputs of the ApplicationConfiguration components, which are in the same order as the workloads. A dependency
+// without inputs from it is compiled into the DataInputs without field paths to wait for all of its outputs.
+func compileDependencies(appfile *Appfile, acComps []v1alpha2.ApplicationConfigurationComponent) {
+	workloads := make(map[string]*Workload, len(appfile.Workloads))
+	depended := map[string]bool{}
+	for _, wl := range appfile.Workloads {
+		workloads[wl.Name] = wl
+		for _, dep := range wl.dependencies() {
+			depended[dep] = true
+		}
+	}
+
+	for i, wl := range appfile.Workloads {
+		acComp := &acComps[i]
+		for _, out := range wl.Outputs {
+			acComp.DataOutputs = append(acComp.DataOutputs, v1alpha2.DataOutput{
+				Name:       dataOutputName(wl.Name, out.Name),
+				FieldPath:  out.FieldPath,
+				Conditions: out.Conditions,
+			})
+		}
+		if depended[wl.Name] && len(wl.Outputs) == 0 {
+			acComp.DataOutputs = append(acComp.DataOutputs, v1alpha2.DataOutput{
+				Name:      dataOutputName(wl.Name, ""),
+				FieldPath: createdOutputFieldPath,
+			})
+		}
+
+		bound := map[string]bool{}
+		for _, in := range wl.Inputs {
+			name := dataOutputName(in.Component, in.Output)
+			bound[name] = true
+			acComp.DataInputs = append(acComp.DataInputs, v1alpha2.DataInput{
+				ValueFrom:         v1alpha2.DataInputValueFrom{DataOutputName: name},
+				ToFieldPaths:      in.ToFieldPaths,
+				StrategyMergeKeys: in.StrategyMergeKeys,
+			})
+		}
+		for _, dep := range wl.dependencies() {
+			var names []string
+			for _, out := range workloads[dep].Outputs {
+				names = append(names, dataOutputName(dep, out.Name))
+			}
+			if len(names) == 0 {
+				names = []string{dataOutputName(dep, "")}
+			}
+			for _, name := range names {
+				if bound[name] {
+					continue
+				}
+				acComp.DataInputs = append(acComp.DataInputs, v1alpha2.DataInput{
+					ValueFrom: v1alpha2.DataInputValueFrom{DataOutputName: name},
+				})
+			}
+		}
+	}
+}
+
+// dependencyMessage explains why a component is waiting for its dependencies, the unsatisfied dependencies
+// are the ones whose data goes to the workload or traits of the component
+func dependencyMessage(unsatisfied []v1alpha2.UnstaifiedDependency, rendered v1alpha2.WorkloadStatus) string {
+	names := map[string]bool{rendered.Reference.Name: true}
+	for _, t := range rendered.Traits {
+		names[t.Reference.Name] = true
+	}
+	var reasons []string
+	for _, ud := range unsatisfied {
+		if !names[ud.To.Name] {
+			continue
+		}
+		reasons = append(reasons, fmt.Sprintf("%s %s: %s", ud.From.Kind, ud.From.Name, ud.Reason))
+	}
+	if len(reasons) == 0 {
+		return ""
+	}
+	return "waiting for the dependencies, " + strings.Join(reasons, "; ")
+}
@@ -0,0 +1,127 @@
+package application
+
+import (
+	"context"
+	"testing"
+
+	runtimev1alpha1 "github.com/crossplane/crossplane-runtime/apis/core/v1alpha1"
+	"github.com/stretchr/testify/assert"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+
+	"github.com/oam-dev/kubevela/apis/core.oam.dev/v1alpha2"
+)
+
+func TestValidateDependencies(t *testing.T) {
+	secret := []v1alpha2.ApplicationComponentOutput{{Name: "secret", FieldPath: "spec.writeConnectionSecretToRef.name"}}
+	cases := map[string]struct {
+		workloads []*Workload
+		err       string
+	}{
+		"Valid": {
+			workloads: []*Workload{
+				{Name: "db", Outputs: secret},
+				{Name: "cache"},
+				{Name: "web", DependsOn: []string{"cache"},
+					Inputs: []v1alpha2.ApplicationComponentInput{{Component: "db", Output: "secret"}}},
+			},
+		},
+		"DependencyNotFound": {
+			workloads: []*Workload{{Name: "web", DependsOn: []string{"db"}}},
+			err:       "component(web) depends on component db which doesn't exist",
+		},
+		"OutputNotFound": {
+			workloads: []*Workload{
+				{Name: "db"},
+				{Name: "web", Inputs: []v1alpha2.ApplicationComponentInput{{Component: "db", Output: "secret"}}},
+			},
+			err: "component(web) has input from output secret of component db which doesn't exist",
+		},
+		"DuplicateOutput": {
+			workloads: []*Workload{{Name: "db", Outputs: append(secret, secret...)}},
+			err:       "component(db) has duplicate output secret",
+		},
+		"SelfDependency": {
+			workloads: []*Workload{{Name: "web", DependsOn: []string{"web"}}},
+			err:       "component(web) depends on itself",
+		},
+		"Cycle": {
+			workloads: []*Workload{
+				{Name: "web", DependsOn: []string{"api"}},
+				{Name: "api", DependsOn: []string{"db"}},
+				{Name: "db", DependsOn: []string{"web"}},
+			},
+			err: "components depend on each other circularly: web -> api -> db -> web",
+		},
+	}
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			err := validateDependencies(&Appfile{Name: "myapp", Workloads: tc.workloads})
+			if tc.err == "" {
+				assert.NoError(t, err)
+				return
+			}
+			assert.EqualError(t, err, tc.err)
+		})
+	}
+}
+
+func TestCompileDependencies(t *testing.T) {
+	template := `output: {
+	apiVersion: "apps/v1"
+	kind:       "Deployment"
+}`
+	appfile := &Appfile{Name: "myapp", Workloads: []*Workload{
+		{Name: "db", Type: "db", Template: template, Outputs: []v1alpha2.ApplicationComponentOutput{
+			{Name: "secret", FieldPath: "spec.writeConnectionSecretToRef.name"},
+			{Name: "ready", FieldPath: "status.phase",
+				Conditions: []v1alpha2.ConditionRequirement{{Operator: v1alpha2.ConditionEqual, Value: "Ready"}}},
+		}},
+		{Name: "cache", Type: "worker", Template: template},
+		{Name: "web", Type: "webservice", Template: template, DependsOn: []string{"cache"},
+			Inputs: []v1alpha2.ApplicationComponentInput{{Component: "db", Output: "secret",
+				ToFieldPaths: []string{"spec.template.spec.containers[0].env[0].valueFrom.secretKeyRef.name"}}}},
+	}}
+	ac, _, err := NewApplicationParser(nil, nil).GenerateApplicationConfiguration(appfile, "default")
+	assert.NoError(t, err)
+
+	db, cache, web := ac.Spec.Components[0], ac.Spec.Components[1], ac.Spec.Components[2]
+	assert.Equal(t, []v1alpha2.DataOutput{
+		{Name: "db.secret", FieldPath: "spec.writeConnectionSecretToRef.name"},
+		{Name: "db.ready", FieldPath: "status.phase",
+			Conditions: []v1alpha2.ConditionRequirement{{Operator: v1alpha2.ConditionEqual, Value: "Ready"}}},
+	}, db.DataOutputs)
+	assert.Empty(t, db.DataInputs)
+	// the component without outputs is ready once its workload is created
+	assert.Equal(t, []v1alpha2.DataOutput{{Name: "cache", FieldPath: "metadata.uid"}}, cache.DataOutputs)
+	assert.Empty(t, web.DataOutputs)
+	assert.Equal(t, []v1alpha2.DataInput{
+		{ValueFrom: v1alpha2.DataInputValueFrom{DataOutputName: "db.secret"},
+			ToFieldPaths: []string{"spec.template.spec.containers[0].env[0].valueFrom.secretKeyRef.name"}},
+		{ValueFrom: v1alpha2.DataInputValueFrom{DataOutputName: "cache"}},
+		{ValueFrom: v1alpha2.DataInputValueFrom{DataOutputName: "db.ready"}},
+	}, web.DataInputs)
+}
+
+func TestHealthCheckUnsatisfiedDependency(t *testing.T) {
+	ac := &v1alpha2.ApplicationConfiguration{ObjectMeta: metav1.ObjectMeta{Name: "myapp", Namespace: "default"}}
+	ac.Status.Workloads = []v1alpha2.WorkloadStatus{{
+		ComponentName: "web",
+		Reference:     runtimev1alpha1.TypedReference{APIVersion: "apps/v1", Kind: "Deployment", Name: "web"},
+	}}
+	ac.Status.Dependency.Unsatisfied = []v1alpha2.UnstaifiedDependency{{
+		Reason: "spec.writeConnectionSecretToRef.name not found in object",
+		From: v1alpha2.DependencyFromObject{TypedReference: runtimev1alpha1.TypedReference{
+			APIVersion: "database.example.org/v1alpha1", Kind: "PostgreSQLInstance", Name: "db"}},
+		To: v1alpha2.DependencyToObject{TypedReference: runtimev1alpha1.TypedReference{
+			APIVersion: "apps/v1", Kind: "Deployment", Name: "web"}},
+	}}
+	ret := &reter{c: fake.NewFakeClientWithScheme(newCleanupScheme(t))}
+	appfile := &Appfile{Name: "myapp", Workloads: []*Workload{{Name: "web", Type: "webservice",
+		Inputs: []v1alpha2.ApplicationComponentInput{{Component: "db", Output: "secret"}}}}}
+
+	assert.Equal(t, []v1alpha2.ApplicationComponentStatus{{Name: "web", WorkloadDefinition: "webservice",
+		Message: "waiting for the dependencies, PostgreSQLInstance db: " +
+			"spec.writeConnectionSecretToRef.name not found in object"}},
+		ret.healthCheck(context.Background(), appfile, ac))
+}
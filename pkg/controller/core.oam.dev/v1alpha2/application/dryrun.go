@@ -0,0 +1,168 @@
+package application
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+
+	"github.com/pkg/errors"
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	ctypes "k8s.io/apimachinery/pkg/types"
+	"k8s.io/utils/pointer"
+	"sigs.k8s.io/yaml"
+
+	"github.com/oam-dev/kubevela/apis/core.oam.dev/v1alpha2"
+	"github.com/oam-dev/kubevela/pkg/oam"
+)
+
+// the keys of the rendered resources in the dry-run ConfigMap
+const (
+	dryRunScopesKey     = "scopes.yaml"
+	dryRunComponentsKey = "components.yaml"
+	dryRunAppConfigKey  = "applicationconfiguration.yaml"
+)
+
+// IsDryRun checks whether the application is annotated to be rendered without being applied
+func IsDryRun(app *v1alpha2.Application) bool {
+	return app.GetAnnotations()[oam.AnnotationDryRun] == "true"
+}
+
+// DryRunConfigMapName returns the name of the ConfigMap saving the resources rendered by the dry run of an application
+func DryRunConfigMapName(appName string) string {
+	return appName + "-dry-run"
+}
+
+// RenderedResources are the resources rendered from an application, the trait objects are in the
+// ApplicationConfiguration and the workload objects are in the Components
+type RenderedResources struct {
+	Scopes     []*unstructured.Unstructured
+	Components []*v1alpha2.Component
+	AppConfig  *v1alpha2.ApplicationConfiguration
+	// Explanations are the sources of the fields of the components, they're only recorded by Explain
+	Explanations []Explanation
+}
+
+// Render parses the application and renders it with the definitions the parser loads, nothing is applied
+func (p *Parser) Render(app *v1alpha2.Application) (*RenderedResources, error) {
+	return p.render(app, false)
+}
+
+func (p *Parser) render(app *v1alpha2.Application, explain bool) (*RenderedResources, error) {
+	appfile, err := p.GenerateAppFile(app.Name, app)
+	if err != nil {
+		return nil, err
+	}
+	// the revision is unknown without the access to the cluster
+	if p.client != nil {
+		if appfile.RevisionName, err = ResolveRevisionName(context.Background(), p.client, app, appfile); err != nil {
+			return nil, err
+		}
+	}
+	ac, comps, explanations, err := p.generateApplicationConfiguration(appfile, app.Namespace, explain)
+	if err != nil {
+		return nil, err
+	}
+	return &RenderedResources{
+		Scopes:       p.GenerateScopes(appfile, app.Namespace),
+		Components:   comps,
+		AppConfig:    ac,
+		Explanations: explanations,
+	}, nil
+}
+
+// Manifests encodes the rendered resources into YAML by kind, in the order they are applied
+func (r *RenderedResources) Manifests() (map[string]string, error) {
+	manifests := map[string]string{}
+	var scopes []runtime.Object
+	for _, scope := range r.Scopes {
+		scopes = append(scopes, scope)
+	}
+	var comps []runtime.Object
+	for _, comp := range r.Components {
+		comps = append(comps, comp)
+	}
+	for key, objs := range map[string][]runtime.Object{
+		dryRunScopesKey:     scopes,
+		dryRunComponentsKey: comps,
+		dryRunAppConfigKey:  {r.AppConfig},
+	} {
+		if len(objs) == 0 {
+			continue
+		}
+		data, err := encodeYAML(objs)
+		if err != nil {
+			return nil, err
+		}
+		manifests[key] = string(data)
+	}
+	return manifests, nil
+}
+
+// YAML encodes all the rendered resources into one YAML stream, in the order they are applied
+func (r *RenderedResources) YAML() ([]byte, error) {
+	manifests, err := r.Manifests()
+	if err != nil {
+		return nil, err
+	}
+	var w bytes.Buffer
+	for _, key := range []string{dryRunScopesKey, dryRunComponentsKey, dryRunAppConfigKey} {
+		if manifests[key] == "" {
+			continue
+		}
+		if w.Len() > 0 {
+			w.WriteString("---\n")
+		}
+		w.WriteString(manifests[key])
+	}
+	return w.Bytes(), nil
+}
+
+func encodeYAML(objs []runtime.Object) ([]byte, error) {
+	var w bytes.Buffer
+	for i, obj := range objs {
+		data, err := yaml.Marshal(obj)
+		if err != nil {
+			return nil, fmt.Errorf("yaml encode %s failed: %w", obj.GetObjectKind().GroupVersionKind().Kind, err)
+		}
+		if i > 0 {
+			w.WriteString("---\n")
+		}
+		w.Write(data)
+	}
+	return w.Bytes(), nil
+}
+
+// saveDryRun saves the rendered resources in the dry-run ConfigMap of the application, the ConfigMap is owned by
+// the application so that it's deleted together
+func (ret *reter) saveDryRun(ctx context.Context, rendered *RenderedResources) error {
+	manifests, err := rendered.Manifests()
+	if err != nil {
+		return err
+	}
+	cm := &corev1.ConfigMap{}
+	key := ctypes.NamespacedName{Namespace: ret.app.Namespace, Name: DryRunConfigMapName(ret.app.Name)}
+	if err := ret.c.Get(ctx, key, cm); err != nil {
+		if !apierrors.IsNotFound(err) {
+			return errors.Wrap(err, "get dry-run ConfigMap")
+		}
+		cm = &corev1.ConfigMap{ObjectMeta: metav1.ObjectMeta{
+			Name:      key.Name,
+			Namespace: key.Namespace,
+			Labels:    map[string]string{OAMApplicationLabel: ret.app.Name},
+			OwnerReferences: []metav1.OwnerReference{{
+				APIVersion: v1alpha2.SchemeGroupVersion.String(),
+				Kind:       v1alpha2.ApplicationKind,
+				Name:       ret.app.Name,
+				UID:        ret.app.UID,
+				Controller: pointer.BoolPtr(true),
+			}},
+		}, Data: manifests}
+		return errors.Wrap(ret.c.Create(ctx, cm), "create dry-run ConfigMap")
+	}
+	cm.Data = manifests
+	return errors.Wrap(ret.c.Update(ctx, cm), "update dry-run ConfigMap")
+}
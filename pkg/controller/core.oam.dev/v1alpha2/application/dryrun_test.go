@@ -0,0 +1,87 @@
+package application
+
+import (
+	"context"
+	"sort"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+
+	"github.com/oam-dev/kubevela/apis/core.oam.dev/v1alpha2"
+	"github.com/oam-dev/kubevela/pkg/oam"
+)
+
+func TestDryRun(t *testing.T) {
+	ctx := context.Background()
+	app := &v1alpha2.Application{ObjectMeta: metav1.ObjectMeta{Name: "myapp", Namespace: "default", UID: "app-uid",
+		Annotations: map[string]string{oam.AnnotationDryRun: "true"}}}
+	app.Spec.Components = []v1alpha2.ApplicationComponent{{
+		Name:         "myweb",
+		WorkloadType: "webservice",
+		Settings:     runtime.RawExtension{Raw: []byte(`{"image":"nginx"}`)},
+		Traits:       []v1alpha2.ApplicationTrait{{Name: "scaler", Properties: runtime.RawExtension{Raw: []byte(`{"replicas":2}`)}}},
+	}}
+	assert.True(t, IsDryRun(app))
+
+	// the templates are provided by the revision instead of the definitions in the cluster
+	p := NewApplicationParser(nil, nil)
+	p.UseRevisionTemplates(&v1alpha2.ApplicationRevision{Spec: v1alpha2.ApplicationRevisionSpec{
+		WorkloadTemplates: []v1alpha2.DefinitionTemplate{{Name: "webservice", Template: `output: {
+	apiVersion: "apps/v1"
+	kind:       "Deployment"
+	spec: template: spec: containers: [{image: parameter.image}]
+}
+parameter: image: string`}},
+		TraitTemplates: []v1alpha2.DefinitionTemplate{{Name: "scaler", Template: `outputs: scaler: {
+	apiVersion: "core.oam.dev/v1alpha2"
+	kind:       "ManualScalerTrait"
+	spec: replicaCount: parameter.replicas
+}
+parameter: replicas: int`}},
+	}})
+	rendered, err := p.Render(app)
+	assert.NoError(t, err)
+	assert.Equal(t, "myapp", rendered.AppConfig.Name)
+	assert.Equal(t, 1, len(rendered.Components))
+
+	data, err := rendered.YAML()
+	assert.NoError(t, err)
+	docs := strings.Split(string(data), "---\n")
+	assert.Equal(t, 2, len(docs))
+	assert.Contains(t, docs[0], "kind: Component")
+	assert.Contains(t, docs[0], "image: nginx")
+	assert.Contains(t, docs[1], "kind: ApplicationConfiguration")
+	assert.Contains(t, docs[1], "replicaCount: 2")
+
+	c := fake.NewFakeClientWithScheme(newCleanupScheme(t))
+	ret := &reter{c: c, app: app}
+	assert.NoError(t, ret.saveDryRun(ctx, rendered))
+	var cm corev1.ConfigMap
+	assert.NoError(t, c.Get(ctx, client.ObjectKey{Namespace: "default", Name: "myapp-dry-run"}, &cm))
+	assert.Equal(t, []string{"applicationconfiguration.yaml", "components.yaml"}, keys(cm.Data))
+	assert.Equal(t, docs[0], cm.Data["components.yaml"])
+	assert.Equal(t, types.UID("app-uid"), cm.OwnerReferences[0].UID)
+
+	// the ConfigMap is updated by the next render
+	rendered.Components = nil
+	assert.NoError(t, ret.saveDryRun(ctx, rendered))
+	var updated corev1.ConfigMap
+	assert.NoError(t, c.Get(ctx, client.ObjectKey{Namespace: "default", Name: "myapp-dry-run"}, &updated))
+	assert.Equal(t, []string{"applicationconfiguration.yaml"}, keys(updated.Data))
+}
+
+func keys(m map[string]string) []string {
+	var ks []string
+	for k := range m {
+		ks = append(ks, k)
+	}
+	sort.Strings(ks)
+	return ks
+}
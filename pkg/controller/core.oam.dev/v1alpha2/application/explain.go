@@ -0,0 +1,59 @@
+package application
+
+import (
+	"github.com/oam-dev/kubevela/apis/core.oam.dev/v1alpha2"
+	"github.com/oam-dev/kubevela/pkg/dsl/definition"
+	"github.com/oam-dev/kubevela/pkg/dsl/model"
+	"github.com/oam-dev/kubevela/pkg/dsl/process"
+)
+
+// Explanation tells which definitions and parameters set the fields of the objects rendered for a component
+type Explanation struct {
+	Component string
+	// Workload is the provenance of the fields of the workload
+	Workload model.Provenance
+	// Traits are the provenance of the fields of the trait objects, in the same order as the traits of the component
+	// in the ApplicationConfiguration
+	Traits []TraitExplanation
+}
+
+// TraitExplanation tells which definitions and parameters set the fields of a trait object
+type TraitExplanation struct {
+	// Type is the trait type, or AuxiliaryWorkload for the objects in the `outputs` of the workload template
+	Type string
+	// Name is the key of the object in the `outputs` of the template, it's empty for the `output` of a trait
+	Name   string
+	Fields model.Provenance
+}
+
+// Explain renders the application in the same way as Render, and explains where the fields of the workloads and
+// traits come from, including the fields of the workloads patched by the traits
+func (p *Parser) Explain(app *v1alpha2.Application) (*RenderedResources, error) {
+	return p.render(app, true)
+}
+
+func explainComponent(pCtx process.Context, wl *Workload) Explanation {
+	base, assists := pCtx.Output()
+	exp := Explanation{Component: wl.Name, Workload: workloadProvenance(base.Provenance(), wl)}
+	for _, assist := range assists {
+		fields := assist.Ins.Provenance()
+		if assist.Type == definition.AuxiliaryWorkload {
+			fields = workloadProvenance(fields, wl)
+		}
+		exp.Traits = append(exp.Traits, TraitExplanation{Type: assist.Type, Name: assist.Name, Fields: fields})
+	}
+	return exp
+}
+
+// workloadProvenance attributes the fields set by the workload template to the workload type, the workload
+// templater is named after the component
+func workloadProvenance(fields model.Provenance, wl *Workload) model.Provenance {
+	for _, sources := range fields {
+		for i := range sources {
+			if sources[i].Definition == wl.Name {
+				sources[i].Definition = wl.Type
+			}
+		}
+	}
+	return fields
+}
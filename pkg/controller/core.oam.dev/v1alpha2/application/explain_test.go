@@ -0,0 +1,75 @@
+package application
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+
+	"github.com/oam-dev/kubevela/apis/core.oam.dev/v1alpha2"
+	"github.com/oam-dev/kubevela/pkg/dsl/model"
+)
+
+func TestExplain(t *testing.T) {
+	app := &v1alpha2.Application{ObjectMeta: metav1.ObjectMeta{Name: "myapp", Namespace: "default"}}
+	app.Spec.Components = []v1alpha2.ApplicationComponent{{
+		Name:         "myweb",
+		WorkloadType: "webservice",
+		Settings:     runtime.RawExtension{Raw: []byte(`{"image":"nginx"}`)},
+		Traits: []v1alpha2.ApplicationTrait{
+			{Name: "sidecar", Properties: runtime.RawExtension{Raw: []byte(`{"name":"log","image":"fluentd"}`)}},
+			{Name: "scaler", Properties: runtime.RawExtension{Raw: []byte(`{"replicas":2}`)}},
+		},
+	}}
+
+	p := NewApplicationParser(nil, nil)
+	p.UseRevisionTemplates(&v1alpha2.ApplicationRevision{Spec: v1alpha2.ApplicationRevisionSpec{
+		WorkloadTemplates: []v1alpha2.DefinitionTemplate{{Name: "webservice", Template: `output: {
+	apiVersion: "apps/v1"
+	kind:       "Deployment"
+	spec: template: spec: containers: [{name: context.name, image: parameter.image}]
+}
+parameter: image: string`}},
+		TraitTemplates: []v1alpha2.DefinitionTemplate{{Name: "sidecar", Template: `patch: spec: template: spec: {
+	// +patchKey=name
+	containers: [{name: parameter.name, image: parameter.image}]
+}
+parameter: {
+	name:  string
+	image: string
+}`}, {Name: "scaler", Template: `output: {
+	apiVersion: "core.oam.dev/v1alpha2"
+	kind:       "ManualScalerTrait"
+	spec: replicaCount: parameter.replicas
+}
+parameter: replicas: int`}},
+	}})
+
+	rendered, err := p.Render(app)
+	assert.NoError(t, err)
+	assert.Nil(t, rendered.Explanations)
+
+	rendered, err = p.Explain(app)
+	assert.NoError(t, err)
+	assert.Equal(t, 1, len(rendered.Components))
+	assert.Equal(t, []Explanation{{
+		Component: "myweb",
+		Workload: model.Provenance{
+			"apiVersion":                             {{Definition: "webservice"}},
+			"kind":                                   {{Definition: "webservice"}},
+			"spec.template.spec.containers[0].name":  {{Definition: "webservice", Parameter: "context.name"}},
+			"spec.template.spec.containers[0].image": {{Definition: "webservice", Parameter: "parameter.image"}},
+			"spec.template.spec.containers[1].name":  {{Definition: "sidecar", Parameter: "parameter.name"}},
+			"spec.template.spec.containers[1].image": {{Definition: "sidecar", Parameter: "parameter.image"}},
+		},
+		Traits: []TraitExplanation{{
+			Type: "scaler",
+			Fields: model.Provenance{
+				"apiVersion":        {{Definition: "scaler"}},
+				"kind":              {{Definition: "scaler"}},
+				"spec.replicaCount": {{Definition: "scaler", Parameter: "parameter.replicas"}},
+			},
+		}},
+	}}, rendered.Explanations)
+}
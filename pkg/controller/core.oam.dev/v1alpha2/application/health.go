@@ -0,0 +1,161 @@
+package application
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	runtimev1alpha1 "github.com/crossplane/crossplane-runtime/apis/core/v1alpha1"
+	"github.com/pkg/errors"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	ctypes "k8s.io/apimachinery/pkg/types"
+
+	"github.com/oam-dev/kubevela/apis/core.oam.dev/v1alpha2"
+	"github.com/oam-dev/kubevela/apis/types"
+	"github.com/oam-dev/kubevela/pkg/dsl/definition"
+	"github.com/oam-dev/kubevela/pkg/oam"
+)
+
+// healthCheckInterval is the interval to recheck the health of the components, the workloads and traits aren't
+// watched by the application controller
+const healthCheckInterval = 30 * time.Second
+
+// healthCheck evaluates the health policies of every workload and trait against the objects they rendered, the
+// objects are found by the references in the status of the latest ApplicationConfiguration in the cluster. A policy
+// that fails to evaluate makes the workload or trait unhealthy with the error as the message, and a component
+// waiting for its dependencies is unhealthy with the unsatisfied dependencies as the message.
+func (ret *reter) healthCheck(ctx context.Context, appfile *Appfile,
+	latest *v1alpha2.ApplicationConfiguration) []v1alpha2.ApplicationComponentStatus {
+	rendered := make(map[string]v1alpha2.WorkloadStatus, len(latest.Status.Workloads))
+	for _, w := range latest.Status.Workloads {
+		rendered[w.ComponentName] = w
+	}
+	statuses := make([]v1alpha2.ApplicationComponentStatus, 0, len(appfile.Workloads))
+	for _, wl := range appfile.Workloads {
+		w, ok := rendered[wl.Name]
+		if !ok {
+			statuses = append(statuses, v1alpha2.ApplicationComponentStatus{Name: wl.Name, WorkloadDefinition: wl.Type,
+				Message: "the workload isn't rendered yet"})
+			continue
+		}
+		if msg := dependencyMessage(latest.Status.Dependency.Unsatisfied, w); msg != "" {
+			statuses = append(statuses, v1alpha2.ApplicationComponentStatus{Name: wl.Name, WorkloadDefinition: wl.Type,
+				Message: msg})
+			continue
+		}
+		statuses = append(statuses, ret.componentHealth(ctx, latest.Namespace, wl, w))
+	}
+	return statuses
+}
+
+func (ret *reter) componentHealth(ctx context.Context, ns string, wl *Workload,
+	rendered v1alpha2.WorkloadStatus) v1alpha2.ApplicationComponentStatus {
+	status := v1alpha2.ApplicationComponentStatus{Name: wl.Name, WorkloadDefinition: wl.Type}
+	health := evalHealth(wl.Type, wl.Health, types.TypeWorkload, func() (*unstructured.Unstructured, error) {
+		return ret.getRendered(ctx, ns, rendered.Reference)
+	}, wl.EvalHealth)
+	status.Healthy, status.Message, status.Details = health.Healthy, health.Message, health.Details
+
+	for _, tr := range wl.Traits {
+		health := evalHealth(tr.Name, tr.Health, types.TypeTrait, func() (*unstructured.Unstructured, error) {
+			return ret.getRenderedTrait(ctx, ns, rendered.Traits, tr.Name)
+		}, tr.EvalHealth)
+		status.Traits = append(status.Traits, v1alpha2.ApplicationTraitStatus{Type: tr.Name, Healthy: health.Healthy,
+			Message: health.Message, Details: health.Details})
+		if !health.Healthy {
+			status.Healthy = false
+		}
+	}
+	return status
+}
+
+// evalHealth gets the rendered object and evaluates the health policy of the definition against it, the object isn't
+// needed if there is no health policy
+func evalHealth(definitionName, policy string, kind types.CapType, get func() (*unstructured.Unstructured, error),
+	eval func(map[string]interface{}) (definition.HealthStatus, error)) definition.HealthStatus {
+	if policy == "" {
+		return definition.HealthStatus{Healthy: true}
+	}
+	obj, err := get()
+	if err != nil {
+		return definition.HealthStatus{Message: err.Error()}
+	}
+	if obj == nil {
+		return definition.HealthStatus{Message: fmt.Sprintf("the %s isn't rendered yet", kind)}
+	}
+	health, err := eval(obj.Object)
+	if err != nil {
+		cueEvalFailures.WithLabelValues(definitionName, string(kind)).Inc()
+		return definition.HealthStatus{Message: err.Error()}
+	}
+	return health
+}
+
+// getRendered gets the object of the reference, it returns nil if the object is not found
+func (ret *reter) getRendered(ctx context.Context, ns string, ref runtimev1alpha1.TypedReference) (
+	*unstructured.Unstructured, error) {
+	obj := &unstructured.Unstructured{}
+	obj.SetAPIVersion(ref.APIVersion)
+	obj.SetKind(ref.Kind)
+	if err := ret.c.Get(ctx, ctypes.NamespacedName{Namespace: ns, Name: ref.Name}, obj); err != nil {
+		if apierrors.IsNotFound(err) {
+			return nil, nil
+		}
+		return nil, errors.Wrapf(err, "get %s %s", ref.Kind, ref.Name)
+	}
+	return obj, nil
+}
+
+// getRenderedTrait gets the object rendered by the `output` of the trait. The objects are labeled by the parser with
+// the trait type, the objects in the `outputs` of the trait are labeled with their keys in addition.
+func (ret *reter) getRenderedTrait(ctx context.Context, ns string, traits []v1alpha2.WorkloadTrait,
+	traitType string) (*unstructured.Unstructured, error) {
+	for _, t := range traits {
+		obj, err := ret.getRendered(ctx, ns, t.Reference)
+		if err != nil {
+			return nil, err
+		}
+		if obj == nil {
+			continue
+		}
+		labels := obj.GetLabels()
+		if labels[oam.TraitTypeLabel] == traitType && labels[oam.TraitResourceLabel] == "" {
+			return obj, nil
+		}
+	}
+	return nil, nil
+}
+
+// aggregateHealth returns the phase of the application by the health of its components
+func aggregateHealth(statuses []v1alpha2.ApplicationComponentStatus) v1alpha2.ApplicationPhase {
+	var healthy int
+	for _, status := range statuses {
+		if status.Healthy {
+			healthy++
+		}
+	}
+	switch {
+	case healthy == len(statuses):
+		return v1alpha2.ApplicationRunning
+	case healthy == 0:
+		return v1alpha2.ApplicationUnhealthy
+	default:
+		return v1alpha2.ApplicationDegraded
+	}
+}
+
+// healthCondition returns the HealthCheck condition, it lists the unhealthy components if there are any
+func healthCondition(statuses []v1alpha2.ApplicationComponentStatus) runtimev1alpha1.Condition {
+	var unhealthy []string
+	for _, status := range statuses {
+		if !status.Healthy {
+			unhealthy = append(unhealthy, status.Name)
+		}
+	}
+	if len(unhealthy) == 0 {
+		return readyCondition(conditionHealthCheck)
+	}
+	return errorCondition(conditionHealthCheck, fmt.Errorf("unhealthy components: %s", strings.Join(unhealthy, ", ")))
+}
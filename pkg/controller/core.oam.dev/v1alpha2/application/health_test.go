@@ -0,0 +1,85 @@
+package application
+
+import (
+	"context"
+	"testing"
+
+	runtimev1alpha1 "github.com/crossplane/crossplane-runtime/apis/core/v1alpha1"
+	"github.com/stretchr/testify/assert"
+	appsv1 "k8s.io/api/apps/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/utils/pointer"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+
+	"github.com/oam-dev/kubevela/apis/core.oam.dev/v1alpha2"
+	"github.com/oam-dev/kubevela/pkg/oam"
+)
+
+func TestHealthCheck(t *testing.T) {
+	ctx := context.Background()
+	newDeploy := func(name string, replicas, ready int32) *appsv1.Deployment {
+		deploy := &appsv1.Deployment{ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: "default"}}
+		deploy.Spec.Replicas = pointer.Int32Ptr(replicas)
+		deploy.Status.ReadyReplicas = ready
+		return deploy
+	}
+	scaler := &v1alpha2.ManualScalerTrait{ObjectMeta: metav1.ObjectMeta{Name: "myweb-scaler", Namespace: "default",
+		Labels: map[string]string{oam.TraitTypeLabel: "scaler"}}}
+	scaler.Spec.ReplicaCount = 3
+	// an object in the outputs of the trait has the same trait type, it's not the one the policy checks
+	extra := &v1alpha2.ManualScalerTrait{ObjectMeta: metav1.ObjectMeta{Name: "myweb-scaler-extra", Namespace: "default",
+		Labels: map[string]string{oam.TraitTypeLabel: "scaler", oam.TraitResourceLabel: "extra"}}}
+	ac := &v1alpha2.ApplicationConfiguration{ObjectMeta: metav1.ObjectMeta{Name: "myapp", Namespace: "default"}}
+	ac.Status.Workloads = []v1alpha2.WorkloadStatus{{
+		ComponentName: "myweb",
+		Reference:     runtimev1alpha1.TypedReference{APIVersion: "apps/v1", Kind: "Deployment", Name: "myweb"},
+		Traits: []v1alpha2.WorkloadTrait{{Reference: runtimev1alpha1.TypedReference{
+			APIVersion: "core.oam.dev/v1alpha2", Kind: "ManualScalerTrait", Name: "myweb-scaler-extra"}}, {
+			Reference: runtimev1alpha1.TypedReference{
+				APIVersion: "core.oam.dev/v1alpha2", Kind: "ManualScalerTrait", Name: "myweb-scaler"}}},
+	}, {
+		ComponentName: "myworker",
+		Reference:     runtimev1alpha1.TypedReference{APIVersion: "apps/v1", Kind: "Deployment", Name: "myworker"},
+	}}
+	// the deployment of another app with the same labels must not be checked
+	other := newDeploy("other", 1, 0)
+	other.Labels = map[string]string{oam.LabelAppName: "myapp"}
+	c := fake.NewFakeClientWithScheme(newCleanupScheme(t), ac, extra, scaler, other, newDeploy("myweb", 3, 2),
+		newDeploy("myworker", 1, 1))
+	ret := &reter{c: c}
+
+	policy := `isHealth: output.status.readyReplicas == output.spec.replicas
+message: "\(output.status.readyReplicas)/\(output.spec.replicas) replicas are ready"`
+	appfile := &Appfile{Name: "myapp", Workloads: []*Workload{{
+		Name:   "myweb",
+		Type:   "webservice",
+		Health: policy,
+		Traits: []*Trait{{
+			Name: "scaler",
+			Health: `isHealth: output.spec.replicaCount > 0
+details: replicas: output.spec.replicaCount`,
+		}},
+	}, {
+		Name:   "myworker",
+		Type:   "worker",
+		Health: policy,
+	}, {
+		Name: "mytask",
+		Type: "task",
+	}}}
+
+	statuses := ret.healthCheck(ctx, appfile, ac)
+	assert.Equal(t, []v1alpha2.ApplicationComponentStatus{
+		{Name: "myweb", WorkloadDefinition: "webservice", Message: "2/3 replicas are ready",
+			Traits: []v1alpha2.ApplicationTraitStatus{{Type: "scaler", Healthy: true,
+				Details: map[string]string{"replicas": "3"}}}},
+		{Name: "myworker", WorkloadDefinition: "worker", Healthy: true, Message: "1/1 replicas are ready"},
+		{Name: "mytask", WorkloadDefinition: "task", Message: "the workload isn't rendered yet"},
+	}, statuses)
+	assert.Equal(t, v1alpha2.ApplicationDegraded, aggregateHealth(statuses))
+	assert.Equal(t, "unhealthy components: myweb, mytask", healthCondition(statuses).Message)
+
+	assert.Equal(t, v1alpha2.ApplicationRunning, aggregateHealth(statuses[1:2]))
+	assert.Equal(t, readyCondition("HealthCheck").Status, healthCondition(statuses[1:2]).Status)
+	assert.Equal(t, v1alpha2.ApplicationUnhealthy, aggregateHealth(statuses[2:]))
+}
@@ -0,0 +1,34 @@
+package application
+
+import (
+	"github.com/prometheus/client_golang/prometheus"
+	"sigs.k8s.io/controller-runtime/pkg/metrics"
+)
+
+// the metrics of the application controller, they're served by the metrics endpoint of the controller manager
+var (
+	// renderDuration is the latency of parsing an application and building its ApplicationConfiguration
+	renderDuration = prometheus.NewHistogram(prometheus.HistogramOpts{
+		Name: "kubevela_application_render_duration_seconds",
+		Help: "Duration of rendering an application into the ApplicationConfiguration and Components.",
+	})
+	// cueEvalFailures counts the templates and health policies of the definitions that fail to evaluate
+	cueEvalFailures = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Name: "kubevela_application_cue_eval_failures_total",
+		Help: "Total number of CUE evaluation failures of the templates and health policies per definition.",
+	}, []string{"definition", "kind"})
+	// applyErrors counts the failures of applying the rendered resources to the cluster
+	applyErrors = prometheus.NewCounter(prometheus.CounterOpts{
+		Name: "kubevela_application_apply_errors_total",
+		Help: "Total number of errors applying the rendered resources of applications.",
+	})
+	// healthChecks counts the health checks of applications by the phase they result in
+	healthChecks = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Name: "kubevela_application_health_checks_total",
+		Help: "Total number of application health checks by the result: running, degraded or unhealthy.",
+	}, []string{"result"})
+)
+
+func init() {
+	metrics.Registry.MustRegister(renderDuration, cueEvalFailures, applyErrors, healthChecks)
+}
@@ -0,0 +1,61 @@
+package application
+
+import (
+	"testing"
+
+	runtimev1alpha1 "github.com/crossplane/crossplane-runtime/apis/core/v1alpha1"
+	"github.com/crossplane/crossplane-runtime/pkg/event"
+	"github.com/pkg/errors"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/stretchr/testify/assert"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+
+	"github.com/oam-dev/kubevela/apis/core.oam.dev/v1alpha2"
+)
+
+// eventRecorder records the events instead of sending them to the API server
+type eventRecorder struct {
+	events []event.Event
+}
+
+func (r *eventRecorder) Event(_ runtime.Object, e event.Event) {
+	r.events = append(r.events, e)
+}
+
+func (r *eventRecorder) WithAnnotations(_ ...string) event.Recorder {
+	return r
+}
+
+func TestRecordTransitions(t *testing.T) {
+	record := &eventRecorder{}
+	r := &Reconciler{record: record}
+	app := &v1alpha2.Application{ObjectMeta: metav1.ObjectMeta{Name: "myapp", Namespace: "default"}}
+	lastConditions := []runtimev1alpha1.Condition{readyCondition(conditionParsed),
+		errorCondition(conditionBuilt, errors.New("bad template"))}
+	app.Status.SetConditions(readyCondition(conditionParsed), readyCondition(conditionBuilt),
+		errorCondition(conditionApplied, errors.New("conflict")))
+
+	// the Parsed condition isn't changed and the HealthCheck isn't reached
+	r.recordTransitions(app, lastConditions)
+	assert.Equal(t, []event.Event{
+		event.Normal(conditionBuilt, readyMessages[conditionBuilt]),
+		event.Warning(conditionApplied, errors.New("conflict")),
+	}, record.events)
+}
+
+func TestCueEvalFailures(t *testing.T) {
+	p := NewApplicationParser(nil, nil)
+	appfile := &Appfile{Name: "myapp", Workloads: []*Workload{{
+		Name: "myweb",
+		Type: "broken-webservice",
+		Template: `output: {kind: "Deployment", replicas: parameter.replicas}
+parameter: replicas: string`,
+		Params: map[string]interface{}{"replicas": 1},
+	}}}
+	failures := cueEvalFailures.WithLabelValues("broken-webservice", "workload")
+	before := testutil.ToFloat64(failures)
+	_, _, err := p.GenerateApplicationConfiguration(appfile, "default")
+	assert.Error(t, err)
+	assert.Equal(t, before+1, testutil.ToFloat64(failures))
+}
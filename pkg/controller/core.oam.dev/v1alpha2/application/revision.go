@@ -0,0 +1,163 @@
+package application
+
+import (
+	"context"
+	"fmt"
+	"hash/fnv"
+	"sort"
+
+	"github.com/pkg/errors"
+	apiequality "k8s.io/apimachinery/pkg/api/equality"
+	kerrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/rand"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	"github.com/oam-dev/kubevela/apis/core.oam.dev/v1alpha2"
+	"github.com/oam-dev/kubevela/pkg/oam"
+	"github.com/oam-dev/kubevela/pkg/oam/util"
+)
+
+// RevisionName returns the name of the ApplicationRevision of the application
+func RevisionName(appName string, revision int64) string {
+	return fmt.Sprintf("%s-v%d", appName, revision)
+}
+
+func sortedTemplates(templates map[string]v1alpha2.DefinitionTemplate) []v1alpha2.DefinitionTemplate {
+	var sorted []v1alpha2.DefinitionTemplate
+	for _, t := range templates {
+		sorted = append(sorted, t)
+	}
+	sort.Slice(sorted, func(i, j int) bool {
+		return sorted[i].Name < sorted[j].Name
+	})
+	return sorted
+}
+
+// newRevision snapshots the application with the templates that it's rendered with
+func newRevision(app *v1alpha2.Application, appfile *Appfile) *v1alpha2.ApplicationRevision {
+	workloads := map[string]v1alpha2.DefinitionTemplate{}
+	traits := map[string]v1alpha2.DefinitionTemplate{}
+	for _, wl := range appfile.Workloads {
+		if wl.Template != "" {
+			workloads[wl.Type] = v1alpha2.DefinitionTemplate{Name: wl.Type, Template: wl.Template, Health: wl.Health}
+		}
+		for _, tr := range wl.Traits {
+			traits[tr.Name] = v1alpha2.DefinitionTemplate{Name: tr.Name, Template: tr.Template, Health: tr.Health}
+		}
+	}
+	rev := &v1alpha2.ApplicationRevision{}
+	rev.Spec.Application = *app.Spec.DeepCopy()
+	rev.Spec.WorkloadTemplates = sortedTemplates(workloads)
+	rev.Spec.TraitTemplates = sortedTemplates(traits)
+	return rev
+}
+
+// revisionHash computes the hash of the revision regardless of its revision number
+func revisionHash(rev *v1alpha2.ApplicationRevision) string {
+	spec := rev.Spec.DeepCopy()
+	spec.Revision = 0
+	hasher := fnv.New32a()
+	util.DeepHashObject(hasher, *spec)
+	return rand.SafeEncodeString(fmt.Sprint(hasher.Sum32()))
+}
+
+// ListRevisions lists the ApplicationRevisions of the application sorted by the revision number
+func ListRevisions(ctx context.Context, c client.Reader, namespace, appName string) ([]v1alpha2.ApplicationRevision,
+	error) {
+	var revs v1alpha2.ApplicationRevisionList
+	if err := c.List(ctx, &revs, client.InNamespace(namespace), client.MatchingLabels{oam.LabelAppName: appName}); err != nil {
+		return nil, err
+	}
+	sort.Slice(revs.Items, func(i, j int) bool {
+		return revs.Items[i].Spec.Revision < revs.Items[j].Spec.Revision
+	})
+	return revs.Items, nil
+}
+
+// rollbackRevision returns the revision that the application is rolled back to, the revision is ignored once the
+// spec of the application is changed after the rollback
+func (r *Reconciler) rollbackRevision(ctx context.Context, app *v1alpha2.Application) (*v1alpha2.ApplicationRevision,
+	error) {
+	name := app.GetAnnotations()[oam.AnnotationRollbackRevision]
+	if len(name) == 0 {
+		return nil, nil
+	}
+	rev := &v1alpha2.ApplicationRevision{}
+	if err := r.Get(ctx, client.ObjectKey{Namespace: app.Namespace, Name: name}, rev); err != nil {
+		if kerrors.IsNotFound(err) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	if !apiequality.Semantic.DeepEqual(rev.Spec.Application, app.Spec) {
+		return nil, nil
+	}
+	return rev, nil
+}
+
+// resolveRevision returns the revision with the same hash, or the revision numbered after the latest one if there
+// isn't such a revision, it returns true if the revision exists
+func resolveRevision(appName, hash string, revs []v1alpha2.ApplicationRevision) (v1alpha2.Revision, bool) {
+	for _, existing := range revs {
+		if existing.GetLabels()[oam.LabelAppRevisionHash] == hash {
+			return v1alpha2.Revision{Name: existing.Name, Revision: existing.Spec.Revision}, true
+		}
+	}
+	var revision int64 = 1
+	if len(revs) != 0 {
+		revision = revs[len(revs)-1].Spec.Revision + 1
+	}
+	return v1alpha2.Revision{Name: RevisionName(appName, revision), Revision: revision}, false
+}
+
+// ResolveRevisionName returns the name of the revision that the application is recorded as with the templates of the
+// appfile, the revision is created after the application is applied if it doesn't exist yet
+func ResolveRevisionName(ctx context.Context, c client.Reader, app *v1alpha2.Application, appfile *Appfile) (string,
+	error) {
+	revs, err := ListRevisions(ctx, c, app.Namespace, app.Name)
+	if err != nil {
+		return "", errors.Wrap(err, "list application revisions")
+	}
+	revision, _ := resolveRevision(app.Name, revisionHash(newRevision(app, appfile)), revs)
+	return revision.Name, nil
+}
+
+// applyRevision records the revision of the application. An existing revision with the same spec and templates is
+// reused, e.g. when the application is rolled back, otherwise a new revision is created and the oldest revisions
+// beyond the limit are deleted.
+func (r *Reconciler) applyRevision(ctx context.Context, app *v1alpha2.Application, appfile *Appfile) error {
+	rev := newRevision(app, appfile)
+	hash := revisionHash(rev)
+	revs, err := ListRevisions(ctx, r, app.Namespace, app.Name)
+	if err != nil {
+		return errors.Wrap(err, "list application revisions")
+	}
+	revision, existing := resolveRevision(app.Name, hash, revs)
+	if existing {
+		app.Status.LatestRevision = &revision
+		return nil
+	}
+
+	rev.Spec.Revision = revision.Revision
+	rev.SetName(revision.Name)
+	rev.SetNamespace(app.Namespace)
+	rev.SetLabels(map[string]string{oam.LabelAppName: app.Name, oam.LabelAppRevisionHash: hash})
+	rev.SetOwnerReferences([]metav1.OwnerReference{
+		*metav1.NewControllerRef(app, v1alpha2.SchemeGroupVersion.WithKind(v1alpha2.ApplicationKind)),
+	})
+	if err := r.Create(ctx, rev); err != nil {
+		return errors.Wrapf(err, "create application revision %s", rev.Name)
+	}
+	app.Status.LatestRevision = &v1alpha2.Revision{Name: rev.Name, Revision: rev.Spec.Revision}
+
+	if r.revisionLimit <= 0 {
+		return nil
+	}
+	for i := 0; i < len(revs)+1-r.revisionLimit; i++ {
+		if err := r.Delete(ctx, &revs[i]); err != nil && !kerrors.IsNotFound(err) {
+			return errors.Wrapf(err, "delete application revision %s", revs[i].Name)
+		}
+	}
+	return nil
+}
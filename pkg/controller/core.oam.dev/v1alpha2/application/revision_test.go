@@ -0,0 +1,118 @@
+package application
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+
+	core "github.com/oam-dev/kubevela/apis/core.oam.dev"
+	"github.com/oam-dev/kubevela/apis/core.oam.dev/v1alpha2"
+	"github.com/oam-dev/kubevela/pkg/oam"
+)
+
+func TestApplyRevision(t *testing.T) {
+	ctx := context.Background()
+	s := runtime.NewScheme()
+	assert.NoError(t, core.AddToScheme(s))
+	c := fake.NewFakeClientWithScheme(s)
+	r := &Reconciler{Client: c, Scheme: s, revisionLimit: 2}
+
+	app := &v1alpha2.Application{ObjectMeta: metav1.ObjectMeta{Name: "myapp", Namespace: "default", UID: "app-uid"}}
+	app.Spec.Components = []v1alpha2.ApplicationComponent{{Name: "myweb", WorkloadType: "webservice"}}
+	appfile := &Appfile{Name: "myapp", Workloads: []*Workload{{
+		Name:     "myweb",
+		Type:     "webservice",
+		Template: "output: kind: \"Deployment\"",
+		Traits:   []*Trait{{Name: "scaler", Template: "output: kind: \"ManualScalerTrait\""}},
+	}}}
+	revisions := func() []string {
+		revs, err := ListRevisions(ctx, c, "default", "myapp")
+		assert.NoError(t, err)
+		var names []string
+		for _, rev := range revs {
+			names = append(names, rev.Name)
+		}
+		return names
+	}
+
+	// the revision is resolved before it's created
+	name, err := ResolveRevisionName(ctx, c, app, appfile)
+	assert.NoError(t, err)
+	assert.Equal(t, "myapp-v1", name)
+	assert.NoError(t, r.applyRevision(ctx, app, appfile))
+	assert.Equal(t, &v1alpha2.Revision{Name: "myapp-v1", Revision: 1}, app.Status.LatestRevision)
+	var rev v1alpha2.ApplicationRevision
+	assert.NoError(t, c.Get(ctx, client.ObjectKey{Namespace: "default", Name: "myapp-v1"}, &rev))
+	assert.Equal(t, app.Spec, rev.Spec.Application)
+	assert.Equal(t, []v1alpha2.DefinitionTemplate{{Name: "webservice", Template: "output: kind: \"Deployment\""}},
+		rev.Spec.WorkloadTemplates)
+	assert.Equal(t, []v1alpha2.DefinitionTemplate{{Name: "scaler", Template: "output: kind: \"ManualScalerTrait\""}},
+		rev.Spec.TraitTemplates)
+	assert.Equal(t, "myapp", rev.Labels[oam.LabelAppName])
+
+	// the revision is not changed if neither the app nor the templates change
+	assert.NoError(t, r.applyRevision(ctx, app, appfile))
+	assert.Equal(t, []string{"myapp-v1"}, revisions())
+
+	// a change of the template creates a new revision
+	appfile.Workloads[0].Template = "output: kind: \"StatefulSet\""
+	assert.NoError(t, r.applyRevision(ctx, app, appfile))
+	assert.Equal(t, &v1alpha2.Revision{Name: "myapp-v2", Revision: 2}, app.Status.LatestRevision)
+
+	// the oldest revision is deleted beyond the limit
+	app.Spec.Components[0].Name = "frontend"
+	assert.NoError(t, r.applyRevision(ctx, app, appfile))
+	assert.Equal(t, []string{"myapp-v2", "myapp-v3"}, revisions())
+
+	// the revision is reused when the app is rolled back
+	app.Spec.Components[0].Name = "myweb"
+	name, err = ResolveRevisionName(ctx, c, app, appfile)
+	assert.NoError(t, err)
+	assert.Equal(t, "myapp-v2", name)
+	assert.NoError(t, r.applyRevision(ctx, app, appfile))
+	assert.Equal(t, &v1alpha2.Revision{Name: "myapp-v2", Revision: 2}, app.Status.LatestRevision)
+	assert.Equal(t, []string{"myapp-v2", "myapp-v3"}, revisions())
+	err = c.Get(ctx, client.ObjectKey{Namespace: "default", Name: "myapp-v1"}, &v1alpha2.ApplicationRevision{})
+	assert.True(t, apierrors.IsNotFound(err))
+}
+
+func TestRollbackRevision(t *testing.T) {
+	ctx := context.Background()
+	s := runtime.NewScheme()
+	assert.NoError(t, core.AddToScheme(s))
+	spec := v1alpha2.ApplicationSpec{Components: []v1alpha2.ApplicationComponent{{Name: "myweb", WorkloadType: "worker"}}}
+	rev := &v1alpha2.ApplicationRevision{
+		ObjectMeta: metav1.ObjectMeta{Name: "myapp-v1", Namespace: "default"},
+		Spec: v1alpha2.ApplicationRevisionSpec{
+			Revision:          1,
+			Application:       spec,
+			WorkloadTemplates: []v1alpha2.DefinitionTemplate{{Name: "worker", Template: "output: kind: \"Deployment\""}},
+		},
+	}
+	r := &Reconciler{Client: fake.NewFakeClientWithScheme(s, rev), Scheme: s}
+	app := &v1alpha2.Application{ObjectMeta: metav1.ObjectMeta{Name: "myapp", Namespace: "default",
+		Annotations: map[string]string{oam.AnnotationRollbackRevision: "myapp-v1"}}, Spec: *spec.DeepCopy()}
+
+	got, err := r.rollbackRevision(ctx, app)
+	assert.NoError(t, err)
+	assert.Equal(t, "myapp-v1", got.Name)
+
+	// the templates are loaded from the revision
+	p := NewApplicationParser(r.Client, nil)
+	p.UseRevisionTemplates(got)
+	appfile, err := p.GenerateAppFile(app.Name, app)
+	assert.NoError(t, err)
+	assert.Equal(t, "output: kind: \"Deployment\"", appfile.Workloads[0].Template)
+
+	// the revision is ignored once the app is changed after the rollback
+	app.Spec.Components[0].WorkloadType = "webservice"
+	got, err = r.rollbackRevision(ctx, app)
+	assert.NoError(t, err)
+	assert.Nil(t, got)
+}
@@ -20,6 +20,7 @@ import (
 	"path/filepath"
 	"testing"
 
+	"github.com/crossplane/crossplane-runtime/pkg/event"
 	. "github.com/onsi/ginkgo"
 	. "github.com/onsi/gomega"
 	"k8s.io/utils/pointer"
@@ -88,6 +89,7 @@ var _ = BeforeSuite(func(done Done) {
 		Log:    ctrl.Log.WithName("Application"),
 		Scheme: testScheme,
 		dm:     dm,
+		record: event.NewNopRecorder(),
 	}
 	close(done)
 }, 60)
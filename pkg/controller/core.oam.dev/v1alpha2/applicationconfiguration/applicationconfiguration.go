@@ -297,7 +297,7 @@ func (r *OAMApplicationReconciler) Reconcile(req reconcile.Request) (result reco
 	log.Debug("Successfully rendered components", "workloads", len(workloads))
 	r.record.Event(ac, event.Normal(reasonRenderComponents, "Successfully rendered components", "workloads", strconv.Itoa(len(workloads))))
 
-	applyOpts := []apply.ApplyOption{apply.MustBeControllableBy(ac.GetUID())}
+	applyOpts := []apply.ApplyOption{apply.MustBeControllableBy(ac.GetUID()), keepRolloutReplicas()}
 	if r.applyOnceOnly {
 		applyOpts = append(applyOpts, applyOnceOnly())
 	}
@@ -577,6 +577,29 @@ func (e *GenerationUnchanged) Error() string {
 		"Please ignore this error in other logic.")
 }
 
+// keepRolloutReplicas keeps the replicas of the workloads under a rollout, which are scaled by the rollout
+func keepRolloutReplicas() apply.ApplyOption {
+	return func(ctx context.Context, current, desired runtime.Object) error {
+		c, _ := current.(*unstructured.Unstructured)
+		d, _ := desired.(*unstructured.Unstructured)
+		if c == nil || d == nil {
+			return nil
+		}
+		if _, ok := c.GetAnnotations()[oam.AnnotationRolloutReplicas]; !ok {
+			return nil
+		}
+		replicas, found, err := unstructured.NestedFieldCopy(c.Object, "spec", "replicas")
+		if err != nil {
+			return err
+		}
+		if !found {
+			unstructured.RemoveNestedField(d.Object, "spec", "replicas")
+			return nil
+		}
+		return unstructured.SetNestedField(d.Object, replicas, "spec", "replicas")
+	}
+}
+
 func applyOnceOnly() apply.ApplyOption {
 	return func(ctx context.Context, current, desired runtime.Object) error {
 		if current == nil {
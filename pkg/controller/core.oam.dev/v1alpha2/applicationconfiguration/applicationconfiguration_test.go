@@ -41,6 +41,7 @@ import (
 
 	"github.com/oam-dev/kubevela/apis/core.oam.dev/v1alpha2"
 
+	"github.com/oam-dev/kubevela/pkg/oam"
 	"github.com/oam-dev/kubevela/pkg/oam/mock"
 	"github.com/oam-dev/kubevela/pkg/utils/apply"
 )
@@ -1639,3 +1640,42 @@ func TestUpdateStatus(t *testing.T) {
 	assert.Equal(t, ac.Status.ObservedGeneration, int64(1))
 
 }
+
+func TestKeepRolloutReplicas(t *testing.T) {
+	deployment := func(replicas int64, annotations map[string]string) *unstructured.Unstructured {
+		u := &unstructured.Unstructured{Object: map[string]interface{}{}}
+		u.SetAnnotations(annotations)
+		_ = unstructured.SetNestedField(u.Object, replicas, "spec", "replicas")
+		return u
+	}
+	rollout := map[string]string{oam.AnnotationRolloutReplicas: "3"}
+
+	cases := map[string]struct {
+		current  runtime.Object
+		desired  *unstructured.Unstructured
+		replicas int64
+	}{
+		"CreateWorkload": {
+			current:  nil,
+			desired:  deployment(3, nil),
+			replicas: 3,
+		},
+		"WorkloadNotUnderRollout": {
+			current:  deployment(1, nil),
+			desired:  deployment(3, nil),
+			replicas: 3,
+		},
+		"WorkloadUnderRollout": {
+			current:  deployment(1, rollout),
+			desired:  deployment(3, nil),
+			replicas: 1,
+		},
+	}
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			assert.NoError(t, keepRolloutReplicas()(context.Background(), tc.current, tc.desired))
+			replicas, _, _ := unstructured.NestedInt64(tc.desired.Object, "spec", "replicas")
+			assert.Equal(t, tc.replicas, replicas)
+		})
+	}
+}
@@ -258,8 +258,14 @@ func setTraitProperties(t *unstructured.Unstructured, traitName, namespace strin
 		t.SetName(traitName)
 	}
 
-	t.SetOwnerReferences([]metav1.OwnerReference{*ref})
-	t.SetNamespace(namespace)
+	// a trait is put into the namespace of the ApplicationConfiguration unless it sets another namespace explicitly,
+	// it's not owned by the ApplicationConfiguration then as an owner must be in the same namespace
+	if t.GetNamespace() == "" {
+		t.SetNamespace(namespace)
+	}
+	if t.GetNamespace() == namespace {
+		t.SetOwnerReferences([]metav1.OwnerReference{*ref})
+	}
 }
 
 // SetWorkloadInstanceName will set metadata.name for workload CR according to createRevision flag in traitDefinition
@@ -1053,6 +1053,16 @@ func TestSetTraitProperties(t *testing.T) {
 	expU.SetName("comp1")
 	expU.SetNamespace("ns")
 	expU.SetOwnerReferences([]metav1.OwnerReference{{Name: "comp1"}})
+	assert.Equal(t, expU, u)
+
+	// the trait in another namespace is not owned by the ApplicationConfiguration
+	u = &unstructured.Unstructured{}
+	u.SetNamespace("other")
+	setTraitProperties(u, "comp1", "ns", &metav1.OwnerReference{Name: "comp1"})
+	expU = &unstructured.Unstructured{}
+	expU.SetName("comp1")
+	expU.SetNamespace("other")
+	assert.Equal(t, expU, u)
 }
 
 func TestRenderTraitName(t *testing.T) {
@@ -3,21 +3,46 @@ package applicationdeployment
 import (
 	"context"
 	"fmt"
+	"strconv"
+	"time"
 
 	"github.com/crossplane/crossplane-runtime/pkg/event"
 	"github.com/crossplane/crossplane-runtime/pkg/logging"
+	"github.com/crossplane/crossplane-runtime/pkg/meta"
+	"github.com/pkg/errors"
 	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
 	"k8s.io/apimachinery/pkg/runtime"
+	ktypes "k8s.io/apimachinery/pkg/types"
 	"k8s.io/klog/v2"
 	ctrl "sigs.k8s.io/controller-runtime"
 	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
 
 	"github.com/oam-dev/kubevela/apis/core.oam.dev/v1alpha2"
 	corev1alpha2 "github.com/oam-dev/kubevela/apis/core.oam.dev/v1alpha2"
+	"github.com/oam-dev/kubevela/apis/standard.oam.dev/v1alpha1"
+	"github.com/oam-dev/kubevela/pkg/controller/common/rollout"
+	"github.com/oam-dev/kubevela/pkg/controller/common/rollout/workloads"
 	controller "github.com/oam-dev/kubevela/pkg/controller/core.oam.dev"
 	"github.com/oam-dev/kubevela/pkg/oam/discoverymapper"
 )
 
+// the time to wait before retrying when the applications are not ready to be rolled out
+var waitApplicationInterval = 10 * time.Second
+
+// revertFinalizer is added to an ApplicationDeployment that reverts the rollout when it's deleted
+const revertFinalizer = "revert.finalizer.core.oam.dev"
+
+// event reasons emitted by the applicationdeployment controller
+const (
+	reasonLocateApplications = "LocateApplications"
+	reasonRolloutRestarted   = "RolloutRestarted"
+	reasonReverting          = "Reverting"
+	reasonReverted           = "Reverted"
+	reasonRevertFailed       = "RevertFailed"
+)
+
 // Reconciler reconciles a PodSpecWorkload object
 type Reconciler struct {
 	client.Client
@@ -30,6 +55,9 @@ type Reconciler struct {
 // +kubebuilder:rbac:groups=core.oam.dev,resources=applicationdeployments,verbs=get;list;watch;create;update;patch;delete
 // +kubebuilder:rbac:groups=core.oam.dev,resources=applicationdeployments/status,verbs=get;update;patch
 // +kubebuilder:rbac:groups=core.oam.dev,resources=applicationconfigurations,verbs=get;list;watch;create;update;patch;delete
+// +kubebuilder:rbac:groups=core.oam.dev,resources=applications,verbs=get;list;watch;delete
+// +kubebuilder:rbac:groups=apps,resources=deployments;statefulsets;replicasets,verbs=get;list;watch;update;patch
+// +kubebuilder:rbac:groups=apps.kruise.io,resources=clonesets,verbs=get;list;watch;update;patch
 func (r *Reconciler) Reconcile(req ctrl.Request) (ctrl.Result, error) {
 	ctx := context.Background()
 	var appdeploy v1alpha2.ApplicationDeployment
@@ -41,9 +69,308 @@ func (r *Reconciler) Reconcile(req ctrl.Request) (ctrl.Result, error) {
 	}
 	klog.InfoS("Start to reconcile ", "application deployment", klog.KObj(&appdeploy))
 
-	// TODO add reconcile logic here
+	if appdeploy.DeletionTimestamp != nil {
+		return r.finalize(ctx, &appdeploy)
+	}
+	revertOnDelete := appdeploy.Spec.RevertOnDelete != nil && *appdeploy.Spec.RevertOnDelete
+	if revertOnDelete != meta.FinalizerExists(&appdeploy.ObjectMeta, revertFinalizer) {
+		if revertOnDelete {
+			meta.AddFinalizer(&appdeploy.ObjectMeta, revertFinalizer)
+		} else {
+			meta.RemoveFinalizer(&appdeploy.ObjectMeta, revertFinalizer)
+		}
+		return ctrl.Result{}, r.Update(ctx, &appdeploy)
+	}
+
+	targetApp, sourceApp, err := r.getApplications(ctx, &appdeploy)
+	if err != nil {
+		klog.ErrorS(err, "cannot get the applications", "application deployment", klog.KObj(&appdeploy))
+		r.record.Event(&appdeploy, event.Warning(reasonLocateApplications, err))
+		return reconcile.Result{RequeueAfter: waitApplicationInterval}, nil
+	}
+
+	targetAC, sourceAC, err := r.getAppConfigs(ctx, targetApp, sourceApp)
+	if err != nil {
+		klog.ErrorS(err, "cannot get the application configurations", "application deployment", klog.KObj(&appdeploy))
+		r.record.Event(&appdeploy, event.Warning(reasonLocateApplications, err))
+		return reconcile.Result{RequeueAfter: waitApplicationInterval}, nil
+	}
+	componentList, err := componentsToRollout(appdeploy.Spec.ComponentList, targetAC)
+	if err != nil {
+		klog.ErrorS(err, "cannot decide the components to rollout", "application deployment", klog.KObj(&appdeploy))
+		r.record.Event(&appdeploy, event.Warning(reasonLocateApplications, err))
+		return reconcile.Result{RequeueAfter: waitApplicationInterval}, nil
+	}
+
+	ordered := appdeploy.Spec.ComponentRolloutStrategy != v1alpha2.ParallelComponentRollout
+	statuses := syncComponentStatuses(appdeploy.Status.ComponentStatuses, componentList)
+	// restart the rollout of the components from the beginning if any of the applications has changed since it started
+	targetGeneration := strconv.FormatInt(targetApp.Generation, 10)
+	var sourceGeneration string
+	if sourceApp != nil {
+		sourceGeneration = strconv.FormatInt(sourceApp.Generation, 10)
+	}
+	restarted := false
+	for i := range statuses {
+		if rollout.RestartOnChange(&statuses[i].RolloutStatus, targetGeneration, sourceGeneration) {
+			restarted = true
+		}
+	}
+	if restarted {
+		r.record.Event(&appdeploy, event.Normal(reasonRolloutRestarted,
+			"the applications have changed, restart the rollout"))
+	}
+
+	var result reconcile.Result
+	for i := range statuses {
+		status := &statuses[i]
+		// a component starts only after the previous one succeeds if the components are rolled out in order
+		if ordered && i > 0 && statuses[i-1].RollingState != v1alpha1.Succeed {
+			continue
+		}
+		targetWorkload, sourceWorkload, err := r.extractWorkloads(ctx, status.ComponentName, targetAC, sourceAC)
+		if err != nil {
+			klog.ErrorS(err, "cannot locate the workloads", "application deployment", klog.KObj(&appdeploy),
+				"component", status.ComponentName)
+			r.record.Event(&appdeploy, event.Warning(reasonLocateApplications, err))
+			return reconcile.Result{RequeueAfter: waitApplicationInterval}, nil
+		}
+		rolloutPlanController := rollout.NewRolloutPlanController(r,
+			r.record.WithAnnotations("component", status.ComponentName), &appdeploy, &appdeploy.Spec.RolloutPlan,
+			&status.RolloutStatus, targetWorkload, sourceWorkload)
+		componentResult, rolloutStatus := rolloutPlanController.Reconcile(ctx)
+		status.RolloutStatus = *rolloutStatus
+		if componentResult.RequeueAfter != 0 &&
+			(result.RequeueAfter == 0 || componentResult.RequeueAfter < result.RequeueAfter) {
+			result.RequeueAfter = componentResult.RequeueAfter
+		}
+	}
+	appdeploy.Status.ComponentStatuses = statuses
+	appdeploy.Status.RolloutStatus = aggregateComponentStatuses(statuses)
+	return result, r.Status().Update(ctx, &appdeploy)
+}
+
+// componentsToRollout returns the components to rollout, it's the only component of the application if the
+// component list is omitted
+func componentsToRollout(componentList []string, targetAC *v1alpha2.ApplicationConfiguration) ([]string, error) {
+	if len(componentList) != 0 {
+		return componentList, nil
+	}
+	if len(targetAC.Spec.Components) != 1 {
+		return nil, fmt.Errorf("the component list is required for an application with %d components",
+			len(targetAC.Spec.Components))
+	}
+	return []string{targetAC.Spec.Components[0].ComponentName}, nil
+}
+
+// syncComponentStatuses returns the status of each component in the list, in the same order as the list
+func syncComponentStatuses(statuses []v1alpha2.ComponentRolloutStatus, componentList []string) []v1alpha2.ComponentRolloutStatus {
+	synced := make([]v1alpha2.ComponentRolloutStatus, len(componentList))
+	for i, name := range componentList {
+		synced[i].ComponentName = name
+		for _, status := range statuses {
+			if status.ComponentName == name {
+				synced[i] = *status.DeepCopy()
+				break
+			}
+		}
+	}
+	return synced
+}
 
-	return ctrl.Result{}, nil
+// aggregateComponentStatuses returns the overall rollout status. It's the status of the first failed component,
+// or the first component that is not completed yet, or the last component if all of them succeed.
+func aggregateComponentStatuses(statuses []v1alpha2.ComponentRolloutStatus) v1alpha1.RolloutStatus {
+	if len(statuses) == 0 {
+		return v1alpha1.RolloutStatus{}
+	}
+	current := len(statuses) - 1
+	for i := len(statuses) - 1; i >= 0; i-- {
+		if statuses[i].RollingState != v1alpha1.Succeed {
+			current = i
+		}
+	}
+	for i := range statuses {
+		if statuses[i].RollingState == v1alpha1.Failed {
+			current = i
+			break
+		}
+	}
+	overall := *statuses[current].RolloutStatus.DeepCopy()
+	overall.RolloutTargetSize, overall.UpgradedReplicas, overall.UpgradedReadyReplicas = 0, 0, 0
+	for _, status := range statuses {
+		overall.RolloutTargetSize += status.RolloutTargetSize
+		overall.UpgradedReplicas += status.UpgradedReplicas
+		overall.UpgradedReadyReplicas += status.UpgradedReadyReplicas
+	}
+	return overall
+}
+
+// finalize reverts the rollout if the ApplicationDeployment has the revert finalizer. The rollout may be stopped at
+// any point, even in the middle of a batch, so the source workloads are scaled back to the full size first and the
+// target application is deleted only after all the source pods are ready.
+func (r *Reconciler) finalize(ctx context.Context, appdeploy *v1alpha2.ApplicationDeployment) (ctrl.Result, error) {
+	if !meta.FinalizerExists(&appdeploy.ObjectMeta, revertFinalizer) {
+		return ctrl.Result{}, nil
+	}
+	reverted, err := r.restoreSource(ctx, appdeploy)
+	if err != nil {
+		klog.ErrorS(err, "cannot restore the source application", "application deployment", klog.KObj(appdeploy))
+		r.record.Event(appdeploy, event.Warning(reasonRevertFailed, err))
+		return reconcile.Result{RequeueAfter: waitApplicationInterval}, nil
+	}
+	if !reverted {
+		return reconcile.Result{RequeueAfter: waitApplicationInterval}, nil
+	}
+
+	var targetApp v1alpha2.Application
+	targetApp.SetNamespace(appdeploy.Namespace)
+	targetApp.SetName(appdeploy.Spec.TargetApplicationName)
+	if err := r.Delete(ctx, &targetApp); client.IgnoreNotFound(err) != nil {
+		klog.ErrorS(err, "cannot delete the target application", "application deployment", klog.KObj(appdeploy))
+		r.record.Event(appdeploy, event.Warning(reasonRevertFailed,
+			errors.Wrapf(err, "cannot delete the target application %s", targetApp.Name)))
+		return reconcile.Result{RequeueAfter: waitApplicationInterval}, nil
+	}
+	r.record.Event(appdeploy, event.Normal(reasonReverted,
+		fmt.Sprintf("the rollout is reverted, the target application %s is deleted", targetApp.Name)))
+	meta.RemoveFinalizer(&appdeploy.ObjectMeta, revertFinalizer)
+	return ctrl.Result{}, r.Update(ctx, appdeploy)
+}
+
+// restoreSource scales the source workload of each component that has been rolled out back to the full size of the
+// rollout, it returns true once all the source pods are ready
+func (r *Reconciler) restoreSource(ctx context.Context, appdeploy *v1alpha2.ApplicationDeployment) (bool, error) {
+	if appdeploy.Spec.SourceApplicationName == nil || len(*appdeploy.Spec.SourceApplicationName) == 0 {
+		return true, nil
+	}
+	var sourceApp v1alpha2.Application
+	key := ktypes.NamespacedName{Namespace: appdeploy.Namespace, Name: *appdeploy.Spec.SourceApplicationName}
+	if err := r.Get(ctx, key, &sourceApp); err != nil {
+		if apierrors.IsNotFound(err) {
+			r.record.Event(appdeploy, event.Warning(reasonRevertFailed,
+				fmt.Errorf("the source application %s is not found, there is nothing to restore", key.Name)))
+			return true, nil
+		}
+		return false, errors.Wrapf(err, "cannot get the source application %s", key.Name)
+	}
+	sourceAC, err := r.getAppConfig(ctx, &sourceApp)
+	if err != nil {
+		return false, err
+	}
+
+	reverted := true
+	for _, status := range appdeploy.Status.ComponentStatuses {
+		// the source is not touched before the rollout is initialized
+		if status.RolloutTargetSize == 0 {
+			continue
+		}
+		workload, err := r.fetchWorkload(ctx, sourceAC, status.ComponentName)
+		if err != nil {
+			return false, err
+		}
+		source, err := workloads.NewWorkloadController(r, workload)
+		if err != nil {
+			return false, err
+		}
+		if err := source.Scale(ctx, status.RolloutTargetSize); err != nil {
+			return false, errors.Wrapf(err, "cannot scale the source workload of component %s", status.ComponentName)
+		}
+		readySize, err := source.ReadySize()
+		if err != nil {
+			return false, err
+		}
+		if readySize < status.RolloutTargetSize {
+			klog.InfoS("waiting for the source workload to be ready", "application deployment", klog.KObj(appdeploy),
+				"component", status.ComponentName, "ready", readySize, "desired", status.RolloutTargetSize)
+			r.record.Event(appdeploy, event.Normal(reasonReverting, fmt.Sprintf(
+				"%d/%d pods of component %s in the source application are ready", readySize,
+				status.RolloutTargetSize, status.ComponentName)))
+			reverted = false
+		}
+	}
+	return reverted, nil
+}
+
+// getApplications fetches the target application and the source application, the source is nil if it's omitted
+func (r *Reconciler) getApplications(ctx context.Context, appdeploy *v1alpha2.ApplicationDeployment) (
+	*v1alpha2.Application, *v1alpha2.Application, error) {
+	var targetApp v1alpha2.Application
+	key := ktypes.NamespacedName{Namespace: appdeploy.Namespace, Name: appdeploy.Spec.TargetApplicationName}
+	if err := r.Get(ctx, key, &targetApp); err != nil {
+		return nil, nil, errors.Wrapf(err, "cannot get the target application %s", key.Name)
+	}
+	if appdeploy.Spec.SourceApplicationName == nil || len(*appdeploy.Spec.SourceApplicationName) == 0 {
+		return &targetApp, nil, nil
+	}
+	var sourceApp v1alpha2.Application
+	key.Name = *appdeploy.Spec.SourceApplicationName
+	if err := r.Get(ctx, key, &sourceApp); err != nil {
+		return nil, nil, errors.Wrapf(err, "cannot get the source application %s", key.Name)
+	}
+	return &targetApp, &sourceApp, nil
+}
+
+// getAppConfigs gets the ApplicationConfigurations of both applications, the source is nil if it's omitted
+func (r *Reconciler) getAppConfigs(ctx context.Context, targetApp, sourceApp *v1alpha2.Application) (
+	*v1alpha2.ApplicationConfiguration, *v1alpha2.ApplicationConfiguration, error) {
+	targetAC, err := r.getAppConfig(ctx, targetApp)
+	if err != nil {
+		return nil, nil, err
+	}
+	if sourceApp == nil {
+		return targetAC, nil, nil
+	}
+	sourceAC, err := r.getAppConfig(ctx, sourceApp)
+	if err != nil {
+		return nil, nil, err
+	}
+	return targetAC, sourceAC, nil
+}
+
+// extractWorkloads locates the workloads of the component to upgrade in both applications
+func (r *Reconciler) extractWorkloads(ctx context.Context, componentName string, targetAC,
+	sourceAC *v1alpha2.ApplicationConfiguration) (*unstructured.Unstructured, *unstructured.Unstructured, error) {
+	targetWorkload, err := r.fetchWorkload(ctx, targetAC, componentName)
+	if err != nil {
+		return nil, nil, err
+	}
+	if sourceAC == nil {
+		return targetWorkload, nil, nil
+	}
+	sourceWorkload, err := r.fetchWorkload(ctx, sourceAC, componentName)
+	if err != nil {
+		return nil, nil, err
+	}
+	return targetWorkload, sourceWorkload, nil
+}
+
+// getAppConfig gets the ApplicationConfiguration generated by the application
+func (r *Reconciler) getAppConfig(ctx context.Context, app *v1alpha2.Application) (*v1alpha2.ApplicationConfiguration, error) {
+	var ac v1alpha2.ApplicationConfiguration
+	if err := r.Get(ctx, ktypes.NamespacedName{Namespace: app.Namespace, Name: app.Name}, &ac); err != nil {
+		return nil, errors.Wrapf(err, "cannot get the application configuration of application %s", app.Name)
+	}
+	return &ac, nil
+}
+
+// fetchWorkload fetches the workload of the component from the status of the ApplicationConfiguration
+func (r *Reconciler) fetchWorkload(ctx context.Context, ac *v1alpha2.ApplicationConfiguration,
+	componentName string) (*unstructured.Unstructured, error) {
+	for _, w := range ac.Status.Workloads {
+		if w.ComponentName != componentName {
+			continue
+		}
+		var workload unstructured.Unstructured
+		workload.SetAPIVersion(w.Reference.APIVersion)
+		workload.SetKind(w.Reference.Kind)
+		key := ktypes.NamespacedName{Namespace: ac.Namespace, Name: w.Reference.Name}
+		if err := r.Get(ctx, key, &workload); err != nil {
+			return nil, errors.Wrapf(err, "cannot get the workload of component %s", componentName)
+		}
+		return &workload, nil
+	}
+	return nil, fmt.Errorf("the workload of component %s in application %s is not created yet", componentName, ac.Name)
 }
 
 // SetupWithManager setup the controller with manager
@@ -0,0 +1,233 @@
+package applicationdeployment
+
+import (
+	"context"
+	"testing"
+
+	runtimev1alpha1 "github.com/crossplane/crossplane-runtime/apis/core/v1alpha1"
+	"github.com/crossplane/crossplane-runtime/pkg/event"
+	"github.com/stretchr/testify/assert"
+	appsv1 "k8s.io/api/apps/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	ktypes "k8s.io/apimachinery/pkg/types"
+	clientgoscheme "k8s.io/client-go/kubernetes/scheme"
+	"k8s.io/utils/pointer"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+
+	core "github.com/oam-dev/kubevela/apis/core.oam.dev"
+	"github.com/oam-dev/kubevela/apis/core.oam.dev/v1alpha2"
+	"github.com/oam-dev/kubevela/apis/standard.oam.dev/v1alpha1"
+)
+
+// eventRecorder records the events instead of sending them to the API server
+type eventRecorder struct {
+	events []event.Event
+}
+
+func (r *eventRecorder) Event(_ runtime.Object, e event.Event) {
+	r.events = append(r.events, e)
+}
+
+func (r *eventRecorder) WithAnnotations(_ ...string) event.Recorder {
+	return r
+}
+
+func (r *eventRecorder) count(reason event.Reason) int {
+	var count int
+	for _, e := range r.events {
+		if e.Reason == reason {
+			count++
+		}
+	}
+	return count
+}
+
+func componentStatus(name string, state v1alpha1.RollingState, upgraded int32) v1alpha2.ComponentRolloutStatus {
+	return v1alpha2.ComponentRolloutStatus{
+		ComponentName: name,
+		RolloutStatus: v1alpha1.RolloutStatus{
+			RollingState:      state,
+			RolloutTargetSize: 4,
+			UpgradedReplicas:  upgraded,
+		},
+	}
+}
+
+func TestComponentsToRollout(t *testing.T) {
+	ac := &v1alpha2.ApplicationConfiguration{}
+	_, err := componentsToRollout(nil, ac)
+	assert.Error(t, err)
+
+	ac.Spec.Components = []v1alpha2.ApplicationConfigurationComponent{{ComponentName: "frontend"}}
+	components, err := componentsToRollout(nil, ac)
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"frontend"}, components)
+
+	components, err = componentsToRollout([]string{"backend", "frontend"}, ac)
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"backend", "frontend"}, components)
+}
+
+func TestSyncComponentStatuses(t *testing.T) {
+	statuses := []v1alpha2.ComponentRolloutStatus{
+		componentStatus("frontend", v1alpha1.Succeed, 4),
+		componentStatus("removed", v1alpha1.Rolling, 2),
+	}
+	synced := syncComponentStatuses(statuses, []string{"backend", "frontend"})
+	assert.Equal(t, 2, len(synced))
+	assert.Equal(t, "backend", synced[0].ComponentName)
+	assert.Equal(t, v1alpha1.RollingState(""), synced[0].RollingState)
+	assert.Equal(t, statuses[0], synced[1])
+}
+
+func TestAggregateComponentStatuses(t *testing.T) {
+	testCases := map[string]struct {
+		statuses []v1alpha2.ComponentRolloutStatus
+		state    v1alpha1.RollingState
+		upgraded int32
+	}{
+		"the first component that is not completed": {
+			statuses: []v1alpha2.ComponentRolloutStatus{
+				componentStatus("frontend", v1alpha1.Succeed, 4),
+				componentStatus("backend", v1alpha1.Rolling, 2),
+				componentStatus("worker", v1alpha1.Verifying, 0),
+			},
+			state:    v1alpha1.Rolling,
+			upgraded: 6,
+		},
+		"failed component": {
+			statuses: []v1alpha2.ComponentRolloutStatus{
+				componentStatus("frontend", v1alpha1.Rolling, 2),
+				componentStatus("backend", v1alpha1.Failed, 1),
+			},
+			state:    v1alpha1.Failed,
+			upgraded: 3,
+		},
+		"all succeed": {
+			statuses: []v1alpha2.ComponentRolloutStatus{
+				componentStatus("frontend", v1alpha1.Succeed, 4),
+				componentStatus("backend", v1alpha1.Succeed, 4),
+			},
+			state:    v1alpha1.Succeed,
+			upgraded: 8,
+		},
+	}
+	for name, tc := range testCases {
+		status := aggregateComponentStatuses(tc.statuses)
+		assert.Equal(t, tc.state, status.RollingState, name)
+		assert.Equal(t, tc.upgraded, status.UpgradedReplicas, name)
+		assert.Equal(t, int32(4*len(tc.statuses)), status.RolloutTargetSize, name)
+	}
+}
+
+func TestFinalizeRevert(t *testing.T) {
+	ctx := context.Background()
+	s := runtime.NewScheme()
+	assert.NoError(t, clientgoscheme.AddToScheme(s))
+	assert.NoError(t, core.AddToScheme(s))
+
+	// the deployment is deleted in the middle of the first batch of the frontend
+	source := "source"
+	now := metav1.Now()
+	appdeploy := &v1alpha2.ApplicationDeployment{
+		ObjectMeta: metav1.ObjectMeta{Name: "deploy", Namespace: "default", DeletionTimestamp: &now,
+			Finalizers: []string{revertFinalizer}},
+		Spec: v1alpha2.ApplicationDeploymentSpec{
+			TargetApplicationName: "target",
+			SourceApplicationName: &source,
+			RevertOnDelete:        pointer.BoolPtr(true),
+		},
+		Status: v1alpha2.ApplicationDeploymentStatus{ComponentStatuses: []v1alpha2.ComponentRolloutStatus{
+			componentStatus("frontend", v1alpha1.Rolling, 2),
+			componentStatus("backend", v1alpha1.Verifying, 0),
+		}},
+	}
+	appdeploy.Status.ComponentStatuses[1].RolloutTargetSize = 0
+	sourceAC := &v1alpha2.ApplicationConfiguration{
+		ObjectMeta: metav1.ObjectMeta{Name: "source", Namespace: "default"},
+		Status: v1alpha2.ApplicationConfigurationStatus{Workloads: []v1alpha2.WorkloadStatus{{
+			ComponentName: "frontend",
+			Reference:     runtimev1alpha1.TypedReference{APIVersion: "apps/v1", Kind: "Deployment", Name: "frontend-v1"},
+		}}},
+	}
+	sourceWorkload := &appsv1.Deployment{
+		ObjectMeta: metav1.ObjectMeta{Name: "frontend-v1", Namespace: "default"},
+		Spec:       appsv1.DeploymentSpec{Replicas: pointer.Int32Ptr(2)},
+	}
+	c := fake.NewFakeClientWithScheme(s, appdeploy, sourceAC, sourceWorkload,
+		&v1alpha2.Application{ObjectMeta: metav1.ObjectMeta{Name: "source", Namespace: "default"}},
+		&v1alpha2.Application{ObjectMeta: metav1.ObjectMeta{Name: "target", Namespace: "default"}})
+	r := &Reconciler{Client: c, record: event.NewNopRecorder(), Scheme: s}
+	req := ctrl.Request{NamespacedName: ktypes.NamespacedName{Namespace: "default", Name: "deploy"}}
+
+	// the target is kept until the source is back to the full size
+	result, err := r.Reconcile(req)
+	assert.NoError(t, err)
+	assert.Equal(t, waitApplicationInterval, result.RequeueAfter)
+	assert.NoError(t, c.Get(ctx, ktypes.NamespacedName{Namespace: "default", Name: "frontend-v1"}, sourceWorkload))
+	assert.Equal(t, int32(4), *sourceWorkload.Spec.Replicas)
+	assert.NoError(t, c.Get(ctx, ktypes.NamespacedName{Namespace: "default", Name: "target"}, &v1alpha2.Application{}))
+
+	sourceWorkload.Status.ReadyReplicas = 4
+	assert.NoError(t, c.Update(ctx, sourceWorkload))
+	_, err = r.Reconcile(req)
+	assert.NoError(t, err)
+	err = c.Get(ctx, ktypes.NamespacedName{Namespace: "default", Name: "target"}, &v1alpha2.Application{})
+	assert.True(t, apierrors.IsNotFound(err))
+	var deleted v1alpha2.ApplicationDeployment
+	assert.NoError(t, c.Get(ctx, req.NamespacedName, &deleted))
+	assert.Empty(t, deleted.Finalizers)
+}
+
+func TestRestartOnChange(t *testing.T) {
+	ctx := context.Background()
+	s := runtime.NewScheme()
+	assert.NoError(t, clientgoscheme.AddToScheme(s))
+	assert.NoError(t, core.AddToScheme(s))
+
+	// the target application is changed in the middle of the rollout of the frontend
+	appdeploy := &v1alpha2.ApplicationDeployment{
+		ObjectMeta: metav1.ObjectMeta{Name: "deploy", Namespace: "default"},
+		Spec: v1alpha2.ApplicationDeploymentSpec{
+			TargetApplicationName: "target",
+			ComponentList:         []string{"frontend"},
+		},
+		Status: v1alpha2.ApplicationDeploymentStatus{ComponentStatuses: []v1alpha2.ComponentRolloutStatus{
+			componentStatus("frontend", v1alpha1.Rolling, 2),
+		}},
+	}
+	appdeploy.Status.ComponentStatuses[0].TargetGeneration = "1"
+	targetAC := &v1alpha2.ApplicationConfiguration{
+		ObjectMeta: metav1.ObjectMeta{Name: "target", Namespace: "default"},
+		Status: v1alpha2.ApplicationConfigurationStatus{Workloads: []v1alpha2.WorkloadStatus{{
+			ComponentName: "frontend",
+			Reference:     runtimev1alpha1.TypedReference{APIVersion: "apps/v1", Kind: "Deployment", Name: "frontend-v2"},
+		}}},
+	}
+	targetWorkload := &appsv1.Deployment{
+		ObjectMeta: metav1.ObjectMeta{Name: "frontend-v2", Namespace: "default"},
+		Spec:       appsv1.DeploymentSpec{Replicas: pointer.Int32Ptr(4)},
+	}
+	c := fake.NewFakeClientWithScheme(s, appdeploy, targetAC, targetWorkload,
+		&v1alpha2.Application{ObjectMeta: metav1.ObjectMeta{Name: "target", Namespace: "default", Generation: 2}})
+	record := &eventRecorder{}
+	r := &Reconciler{Client: c, record: record, Scheme: s}
+	req := ctrl.Request{NamespacedName: ktypes.NamespacedName{Namespace: "default", Name: "deploy"}}
+
+	_, err := r.Reconcile(req)
+	assert.NoError(t, err)
+	var restarted v1alpha2.ApplicationDeployment
+	assert.NoError(t, c.Get(ctx, req.NamespacedName, &restarted))
+	assert.Equal(t, "2", restarted.Status.ComponentStatuses[0].TargetGeneration)
+	assert.Equal(t, v1alpha1.Initializing, restarted.Status.ComponentStatuses[0].RollingState)
+	assert.Equal(t, v1alpha1.Initializing, restarted.Status.RollingState)
+	assert.Equal(t, 1, record.count(reasonRolloutRestarted))
+
+	// the rollout isn't restarted again as long as the applications are unchanged
+	_, err = r.Reconcile(req)
+	assert.NoError(t, err)
+	assert.Equal(t, 1, record.count(reasonRolloutRestarted))
+}
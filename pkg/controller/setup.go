@@ -23,6 +23,7 @@ import (
 	"github.com/oam-dev/kubevela/pkg/controller/standard.oam.dev/v1alpha1/autoscaler"
 	"github.com/oam-dev/kubevela/pkg/controller/standard.oam.dev/v1alpha1/metrics"
 	"github.com/oam-dev/kubevela/pkg/controller/standard.oam.dev/v1alpha1/podspecworkload"
+	"github.com/oam-dev/kubevela/pkg/controller/standard.oam.dev/v1alpha1/rollout"
 	"github.com/oam-dev/kubevela/pkg/controller/standard.oam.dev/v1alpha1/routes"
 	"github.com/oam-dev/kubevela/pkg/controller/utils"
 )
@@ -33,7 +34,7 @@ func Setup(mgr ctrl.Manager, disableCaps string) error {
 	switch disableCaps {
 	case common.DisableNoneCaps:
 		functions = []func(ctrl.Manager) error{
-			metrics.Setup, podspecworkload.Setup, routes.Setup, autoscaler.Setup,
+			metrics.Setup, podspecworkload.Setup, routes.Setup, autoscaler.Setup, rollout.Setup,
 		}
 	case common.DisableAllCaps:
 	default:
@@ -50,6 +51,9 @@ func Setup(mgr ctrl.Manager, disableCaps string) error {
 		if !disableCapsSet.Contains(common.AutoscaleControllerName) {
 			functions = append(functions, autoscaler.Setup)
 		}
+		if !disableCapsSet.Contains(common.RolloutControllerName) {
+			functions = append(functions, rollout.Setup)
+		}
 	}
 
 	for _, setup := range functions {
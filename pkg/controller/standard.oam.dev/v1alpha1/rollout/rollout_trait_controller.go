@@ -0,0 +1,144 @@
+/*
+
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package rollout
+
+import (
+	"context"
+	"fmt"
+	"hash/fnv"
+
+	cpv1alpha1 "github.com/crossplane/crossplane-runtime/apis/core/v1alpha1"
+	"github.com/crossplane/crossplane-runtime/pkg/event"
+	"github.com/go-logr/logr"
+	"github.com/pkg/errors"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/apimachinery/pkg/util/rand"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	"github.com/oam-dev/kubevela/apis/standard.oam.dev/v1alpha1"
+	"github.com/oam-dev/kubevela/pkg/controller/common"
+	"github.com/oam-dev/kubevela/pkg/controller/common/rollout"
+	oamutil "github.com/oam-dev/kubevela/pkg/oam/util"
+)
+
+// Reconciler reconciles a RolloutTrait object
+type Reconciler struct {
+	client.Client
+
+	Log    logr.Logger
+	Scheme *runtime.Scheme
+	record event.Recorder
+}
+
+// Reconcile is the main logic for rollout trait controller
+// +kubebuilder:rbac:groups=standard.oam.dev,resources=rollouttraits,verbs=get;list;watch;create;update;patch;delete
+// +kubebuilder:rbac:groups=standard.oam.dev,resources=rollouttraits/status,verbs=get;update;patch
+// +kubebuilder:rbac:groups=apps,resources=deployments;statefulsets;replicasets,verbs=get;list;watch;update;patch
+// +kubebuilder:rbac:groups=apps.kruise.io,resources=clonesets,verbs=get;list;watch;update;patch
+func (r *Reconciler) Reconcile(req ctrl.Request) (ctrl.Result, error) {
+	log := r.Log.WithValues("rollouttrait", req.NamespacedName)
+	log.Info("Reconciling RolloutTrait...")
+	ctx := context.Background()
+	var trait v1alpha1.RolloutTrait
+	if err := r.Get(ctx, req.NamespacedName, &trait); err != nil {
+		return ctrl.Result{}, client.IgnoreNotFound(err)
+	}
+	if trait.DeletionTimestamp != nil {
+		return ctrl.Result{}, nil
+	}
+
+	targetWorkload, err := oamutil.FetchWorkload(ctx, r, log, &trait)
+	if err != nil {
+		log.Error(err, "Error while fetching the target workload", "target reference", trait.Spec.TargetRef)
+		r.record.Event(&trait, event.Warning(common.ErrLocatingWorkload, err))
+		return oamutil.ReconcileWaitResult,
+			oamutil.PatchCondition(ctx, r, &trait, cpv1alpha1.ReconcileError(errors.Wrap(err, common.ErrLocatingWorkload)))
+	}
+	sourceWorkload, err := r.fetchSourceWorkload(ctx, &trait)
+	if err != nil {
+		log.Error(err, "Error while fetching the source workload", "source reference", trait.Spec.SourceRef)
+		r.record.Event(&trait, event.Warning(common.ErrLocatingWorkload, err))
+		return oamutil.ReconcileWaitResult,
+			oamutil.PatchCondition(ctx, r, &trait, cpv1alpha1.ReconcileError(errors.Wrap(err, common.ErrLocatingWorkload)))
+	}
+
+	// restart the rollout if any of the workloads is replaced or its spec is changed
+	var sourceRevision string
+	if sourceWorkload != nil {
+		sourceRevision = workloadRevision(sourceWorkload)
+	}
+	if rollout.RestartOnChange(&trait.Status, workloadRevision(targetWorkload), sourceRevision) {
+		log.Info("The workloads have changed, restart the rollout")
+	}
+
+	rolloutPlanController := rollout.NewRolloutPlanController(r, r.record, &trait, &trait.Spec.RolloutPlan,
+		&trait.Status, targetWorkload, sourceWorkload)
+	result, rolloutStatus := rolloutPlanController.Reconcile(ctx)
+	trait.Status = *rolloutStatus
+	return result, errors.Wrap(r.Status().Update(ctx, &trait), common.ErrUpdateStatus)
+}
+
+// fetchSourceWorkload fetches the workload that contains the older version, it returns nil if there is no source
+func (r *Reconciler) fetchSourceWorkload(ctx context.Context, trait *v1alpha1.RolloutTrait) (*unstructured.Unstructured, error) {
+	switch len(trait.Spec.SourceRef) {
+	case 0:
+		return nil, nil
+	case 1:
+	default:
+		return nil, fmt.Errorf("rollout from %d source workloads is not supported", len(trait.Spec.SourceRef))
+	}
+	ref := trait.Spec.SourceRef[0]
+	var workload unstructured.Unstructured
+	workload.SetAPIVersion(ref.APIVersion)
+	workload.SetKind(ref.Kind)
+	if err := r.Get(ctx, types.NamespacedName{Namespace: trait.Namespace, Name: ref.Name}, &workload); err != nil {
+		return nil, err
+	}
+	return &workload, nil
+}
+
+// workloadRevision identifies a version of the workload by its UID and the hash of its spec. The replicas are left
+// out as the rollout scales the workloads, the generation of the workloads changes with every batch.
+func workloadRevision(workload *unstructured.Unstructured) string {
+	spec, _, _ := unstructured.NestedMap(workload.Object, "spec")
+	delete(spec, "replicas")
+	hasher := fnv.New32a()
+	oamutil.DeepHashObject(hasher, spec)
+	return fmt.Sprintf("%s-%s", workload.GetUID(), rand.SafeEncodeString(fmt.Sprint(hasher.Sum32())))
+}
+
+// SetupWithManager will setup with event recorder
+func (r *Reconciler) SetupWithManager(mgr ctrl.Manager) error {
+	r.record = event.NewAPIRecorder(mgr.GetEventRecorderFor("RolloutTrait")).
+		WithAnnotations("controller", "RolloutTrait")
+	return ctrl.NewControllerManagedBy(mgr).
+		For(&v1alpha1.RolloutTrait{}).
+		Complete(r)
+}
+
+// Setup adds a controller that reconciles RolloutTrait.
+func Setup(mgr ctrl.Manager) error {
+	r := Reconciler{
+		Client: mgr.GetClient(),
+		Log:    ctrl.Log.WithName("RolloutTrait"),
+		Scheme: mgr.GetScheme(),
+	}
+	return r.SetupWithManager(mgr)
+}
@@ -0,0 +1,84 @@
+package rollout
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	runtimev1alpha1 "github.com/crossplane/crossplane-runtime/apis/core/v1alpha1"
+	"github.com/crossplane/crossplane-runtime/pkg/event"
+	"github.com/stretchr/testify/assert"
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	clientgoscheme "k8s.io/client-go/kubernetes/scheme"
+	"k8s.io/utils/pointer"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+
+	"github.com/oam-dev/kubevela/apis/standard.oam.dev/v1alpha1"
+)
+
+func TestRolloutTraitReconcile(t *testing.T) {
+	scheme := runtime.NewScheme()
+	assert.NoError(t, clientgoscheme.AddToScheme(scheme))
+	assert.NoError(t, v1alpha1.AddToScheme(scheme))
+
+	deploy := func(name string, replicas int32) *appsv1.Deployment {
+		return &appsv1.Deployment{
+			TypeMeta:   metav1.TypeMeta{APIVersion: "apps/v1", Kind: "Deployment"},
+			ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: "default", UID: types.UID(name)},
+			Spec:       appsv1.DeploymentSpec{Replicas: pointer.Int32Ptr(replicas)},
+		}
+	}
+	trait := &v1alpha1.RolloutTrait{
+		TypeMeta:   metav1.TypeMeta{APIVersion: v1alpha1.SchemeGroupVersion.String(), Kind: "RolloutTrait"},
+		ObjectMeta: metav1.ObjectMeta{Name: "rollout", Namespace: "default"},
+		Spec: v1alpha1.RolloutTraitSpec{
+			TargetRef: runtimev1alpha1.TypedReference{APIVersion: "apps/v1", Kind: "Deployment", Name: "v2"},
+			SourceRef: []runtimev1alpha1.TypedReference{{APIVersion: "apps/v1", Kind: "Deployment", Name: "v1"}},
+		},
+	}
+	c := fake.NewFakeClientWithScheme(scheme, deploy("v1", 3), deploy("v2", 3), trait)
+	r := Reconciler{Client: c, Log: ctrl.Log.WithName("RolloutTrait"), Scheme: scheme, record: event.NewNopRecorder()}
+	req := ctrl.Request{NamespacedName: types.NamespacedName{Namespace: "default", Name: "rollout"}}
+
+	_, err := r.Reconcile(req)
+	assert.NoError(t, err)
+	var got v1alpha1.RolloutTrait
+	assert.NoError(t, c.Get(context.Background(), req.NamespacedName, &got))
+	assert.Equal(t, v1alpha1.Initializing, got.Status.RollingState)
+	assert.Equal(t, int32(3), got.Status.RolloutTargetSize)
+	assert.True(t, strings.HasPrefix(got.Status.TargetGeneration, "v2-"))
+	assert.True(t, strings.HasPrefix(got.Status.SourceGeneration, "v1-"))
+	targetRevision := got.Status.TargetGeneration
+
+	_, err = r.Reconcile(req)
+	assert.NoError(t, err)
+	var target appsv1.Deployment
+	assert.NoError(t, c.Get(context.Background(), types.NamespacedName{Namespace: "default", Name: "v2"}, &target))
+	assert.Equal(t, int32(0), *target.Spec.Replicas)
+	// scaling the target doesn't restart the rollout
+	assert.NoError(t, c.Get(context.Background(), req.NamespacedName, &got))
+	assert.Equal(t, targetRevision, got.Status.TargetGeneration)
+	assert.NotEqual(t, v1alpha1.Verifying, got.Status.RollingState)
+
+	// changing the spec of the target in place restarts the rollout
+	target.Spec.Template.Spec.Containers = []corev1.Container{{Name: "app", Image: "app:v3"}}
+	assert.NoError(t, c.Update(context.Background(), &target))
+	_, err = r.Reconcile(req)
+	assert.NoError(t, err)
+	assert.NoError(t, c.Get(context.Background(), req.NamespacedName, &got))
+	assert.NotEqual(t, targetRevision, got.Status.TargetGeneration)
+	assert.True(t, strings.HasPrefix(got.Status.TargetGeneration, "v2-"))
+
+	// more than one source is not supported
+	assert.NoError(t, c.Get(context.Background(), req.NamespacedName, &got))
+	got.Spec.SourceRef = append(got.Spec.SourceRef, got.Spec.SourceRef[0])
+	assert.NoError(t, c.Update(context.Background(), &got))
+	result, err := r.Reconcile(req)
+	assert.NoError(t, err)
+	assert.NotZero(t, result.RequeueAfter)
+}
@@ -5,9 +5,18 @@ const BaseTemplate = `
 
 context: {
   name: string
+  appName: string
+  namespace: string
+  appRevision: string
+  componentType: string
+  appLabels: [string]: string
+  appAnnotations: [string]: string
   config?: [...{
     name: string
     value: string
   }]
+  input?: {...}
+  traits?: [string]: {...}
+  outputs?: [string]: {...}
 }
 `
@@ -0,0 +1,190 @@
+package definition
+
+import (
+	"container/list"
+	"fmt"
+	"reflect"
+	"sync"
+
+	"cuelang.org/go/cue/ast"
+	"cuelang.org/go/cue/build"
+)
+
+// maxCachedTemplates bounds the number of parsed templates kept in memory, the least recently used one is evicted
+// when the cache is full
+const maxCachedTemplates = 512
+
+// templateCache keeps the templates parsed from their text. A parsed file is never built by itself, cue resolves the
+// identifiers of a file in place when it's built, so every build takes a copy of it.
+type templateCache struct {
+	mu    sync.Mutex
+	size  int
+	files map[string]*list.Element
+	// recent orders the parsed templates from the most recently used to the least
+	recent *list.List
+}
+
+type parsedTemplate struct {
+	templ string
+	file  *ast.File
+	// nodes is the number of the nodes in the file
+	nodes int
+}
+
+var templates = &templateCache{size: maxCachedTemplates, files: map[string]*list.Element{}, recent: list.New()}
+
+// get returns a copy of the parsed template that can be built, the template is parsed if it's not in the cache. It
+// returns nil if the template can't be reused, e.g. it's in a package, so that it's parsed along with the build.
+func (c *templateCache) get(templ string) (*ast.File, error) {
+	var cached parsedTemplate
+	c.mu.Lock()
+	elem, ok := c.files[templ]
+	if ok {
+		c.recent.MoveToFront(elem)
+		cached = elem.Value.(parsedTemplate)
+	}
+	c.mu.Unlock()
+	if ok {
+		file, _, err := copyFile(cached.file, cached.nodes)
+		return file, err
+	}
+
+	bi := build.NewContext().NewInstance("", nil)
+	if err := bi.AddFile("-", templ); err != nil {
+		return nil, err
+	}
+	if bi.Err != nil || bi.PkgName != "" {
+		return nil, nil
+	}
+	file := bi.Files[0]
+	// the file is cached before it's built with the other files, the unresolved references are bound by the build
+	copied, nodes, err := copyFile(file, 0)
+	if err != nil {
+		return nil, nil
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if _, ok := c.files[templ]; !ok {
+		c.files[templ] = c.recent.PushFront(parsedTemplate{templ: templ, file: copied, nodes: nodes})
+		for c.recent.Len() > c.size {
+			oldest := c.recent.Back()
+			c.recent.Remove(oldest)
+			delete(c.files, oldest.Value.(parsedTemplate).templ)
+		}
+	}
+	return file, nil
+}
+
+// copyFile returns a deep copy of the parsed file, the references resolved in the file are bound to the copied nodes.
+// The comments are shared by the copies, they're never changed when a file is built. It returns the number of the
+// copied nodes as well, which is the size of the map of the copied nodes when the file is copied again.
+func copyFile(f *ast.File, nodes int) (*ast.File, int, error) {
+	c := &astCopier{copied: make(map[ast.Node]ast.Node, nodes)}
+	copied := c.node(f)
+	return copied.(*ast.File), len(c.copied), c.err
+}
+
+type astCopier struct {
+	// copied maps the nodes to their copies, so that a node referred by many others is copied only once
+	copied map[ast.Node]ast.Node
+	err    error
+}
+
+// nodeFields keeps the indices of the fields that refer to the other nodes of each type of the nodes, the other fields
+// are copied along with the node
+var nodeFields sync.Map
+
+func fieldsToCopy(t reflect.Type) []int {
+	if fields, ok := nodeFields.Load(t); ok {
+		return fields.([]int)
+	}
+	var fields []int
+	for i := 0; i < t.NumField(); i++ {
+		f := t.Field(i)
+		if f.PkgPath != "" {
+			continue
+		}
+		switch f.Type.Kind() {
+		case reflect.Ptr, reflect.Interface, reflect.Slice:
+			fields = append(fields, i)
+		}
+	}
+	nodeFields.Store(t, fields)
+	return fields
+}
+
+func (c *astCopier) node(n ast.Node) ast.Node {
+	if copied, ok := c.copied[n]; ok {
+		return copied
+	}
+	v := reflect.ValueOf(n).Elem()
+	copied := reflect.New(v.Type())
+	copied.Elem().Set(v)
+	node := copied.Interface().(ast.Node)
+	c.copied[n] = node
+	for _, i := range fieldsToCopy(v.Type()) {
+		c.field(copied.Elem().Field(i).Addr().Interface())
+	}
+	return node
+}
+
+// field replaces the nodes referred by the field of a copied node with their copies, the field is assigned without
+// reflection as checking the interfaces of the nodes by reflection is much slower than copying them
+func (c *astCopier) field(f interface{}) {
+	switch f := f.(type) {
+	case *ast.Node:
+		if *f != nil {
+			*f = c.node(*f)
+		}
+	case *ast.Expr:
+		if *f != nil {
+			*f = c.node(*f).(ast.Expr)
+		}
+	case *ast.Label:
+		if *f != nil {
+			*f = c.node(*f).(ast.Label)
+		}
+	case **ast.Ident:
+		if *f != nil {
+			*f = c.node(*f).(*ast.Ident)
+		}
+	case **ast.BasicLit:
+		if *f != nil {
+			*f = c.node(*f).(*ast.BasicLit)
+		}
+	case *[]ast.Expr:
+		*f = append([]ast.Expr(nil), *f...)
+		for i := range *f {
+			c.field(&(*f)[i])
+		}
+	case *[]ast.Decl:
+		*f = append([]ast.Decl(nil), *f...)
+		for i, d := range *f {
+			(*f)[i] = c.node(d).(ast.Decl)
+		}
+	case *[]ast.Clause:
+		*f = append([]ast.Clause(nil), *f...)
+		for i, clause := range *f {
+			(*f)[i] = c.node(clause).(ast.Clause)
+		}
+	case *[]*ast.Ident:
+		*f = append([]*ast.Ident(nil), *f...)
+		for i := range *f {
+			c.field(&(*f)[i])
+		}
+	case *[]*ast.ImportSpec:
+		*f = append([]*ast.ImportSpec(nil), *f...)
+		for i, spec := range *f {
+			(*f)[i] = c.node(spec).(*ast.ImportSpec)
+		}
+	case *[]*ast.Attribute:
+		*f = append([]*ast.Attribute(nil), *f...)
+		for i, attr := range *f {
+			(*f)[i] = c.node(attr).(*ast.Attribute)
+		}
+	default:
+		// a node of a new version of cue that isn't known here
+		c.err = fmt.Errorf("cannot copy %T of the parsed template", f)
+	}
+}
@@ -0,0 +1,157 @@
+package definition
+
+import (
+	"fmt"
+	"sync"
+	"testing"
+
+	"cuelang.org/go/cue/ast"
+	"cuelang.org/go/cue/build"
+	"github.com/bmizerany/assert"
+
+	"github.com/oam-dev/kubevela/pkg/dsl/process"
+)
+
+const cachedTemplate = `
+output: {
+	apiVersion: "apps/v1"
+	kind:       "Deployment"
+	metadata: name: context.name
+	spec: {
+		replicas: parameter.replicas
+		template: spec: containers: [{name: context.name, image: parameter.image}]
+	}
+}
+parameter: {
+	replicas: *1 | int
+	image:    string
+}
+`
+
+func renderCached(name string, params map[string]interface{}) (map[string]interface{}, error) {
+	ctx := process.NewContext(name)
+	if err := NewWDTemplater("test", cachedTemplate, "").Params(params).Complete(ctx); err != nil {
+		return nil, err
+	}
+	base, _ := ctx.Output()
+	obj, err := base.Unstructured()
+	if err != nil {
+		return nil, err
+	}
+	return obj.Object, nil
+}
+
+func TestBuildTemplateReuse(t *testing.T) {
+	for i := 0; i < 3; i++ {
+		name := fmt.Sprintf("app%d", i)
+		obj, err := renderCached(name, map[string]interface{}{"replicas": i + 1, "image": name})
+		assert.Equal(t, nil, err)
+		assert.Equal(t, map[string]interface{}{
+			"apiVersion": "apps/v1",
+			"kind":       "Deployment",
+			"metadata":   map[string]interface{}{"name": name},
+			"spec": map[string]interface{}{
+				"replicas": int64(i + 1),
+				"template": map[string]interface{}{"spec": map[string]interface{}{
+					"containers": []interface{}{map[string]interface{}{"name": name, "image": name}},
+				}},
+			},
+		}, obj)
+	}
+	parsed, err := templates.get(cachedTemplate)
+	assert.Equal(t, nil, err)
+	assert.NotEqual(t, (*ast.File)(nil), parsed)
+
+	var wg sync.WaitGroup
+	errs := make([]error, 8)
+	for i := range errs {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			name := fmt.Sprintf("app%d", i)
+			obj, err := renderCached(name, map[string]interface{}{"image": name})
+			if err == nil && obj["metadata"].(map[string]interface{})["name"] != name {
+				err = fmt.Errorf("%s is rendered with the context of another rendering", name)
+			}
+			errs[i] = err
+		}(i)
+	}
+	wg.Wait()
+	for _, err := range errs {
+		assert.Equal(t, nil, err)
+	}
+}
+
+func TestCopyFile(t *testing.T) {
+	bi := build.NewContext().NewInstance("", nil)
+	assert.Equal(t, nil, bi.AddFile("-", cachedTemplate))
+	file := bi.Files[0]
+	nodes := func(f *ast.File) map[ast.Node]bool {
+		nodes := map[ast.Node]bool{}
+		ast.Walk(f, func(n ast.Node) bool {
+			nodes[n] = true
+			return true
+		}, nil)
+		return nodes
+	}
+	original := nodes(file)
+
+	copied, copiedNodes, err := copyFile(file, 0)
+	assert.Equal(t, nil, err)
+	assert.Equal(t, len(original), copiedNodes)
+	assert.Equal(t, len(original), len(nodes(copied)))
+	// the references in the copy are bound to the copied nodes, the unresolved ones are still unresolved
+	var resolved int
+	ast.Walk(copied, func(n ast.Node) bool {
+		assert.Equal(t, false, original[n])
+		if ident, ok := n.(*ast.Ident); ok && ident.Node != nil {
+			resolved++
+			assert.Equal(t, false, original[ident.Node])
+			assert.Equal(t, false, original[ident.Scope])
+		}
+		return true
+	}, nil)
+	assert.NotEqual(t, 0, resolved)
+	assert.Equal(t, len(file.Unresolved), len(copied.Unresolved))
+	for i, ident := range copied.Unresolved {
+		assert.Equal(t, false, original[ident])
+		assert.Equal(t, file.Unresolved[i].Name, ident.Name)
+	}
+}
+
+func TestBuildTemplateErrors(t *testing.T) {
+	// the invalid parameter is reported every time the template is built
+	for i := 0; i < 2; i++ {
+		_, err := renderCached("test", map[string]interface{}{"replicas": "1", "image": "nginx"})
+		assert.NotEqual(t, nil, err)
+	}
+
+	// a template in a package isn't reused
+	parsed, err := templates.get("package test\noutput: name: context.name")
+	assert.Equal(t, nil, err)
+	assert.Equal(t, (*ast.File)(nil), parsed)
+
+	_, err = templates.get(`output: {`)
+	assert.NotEqual(t, nil, err)
+}
+
+func BenchmarkComplete(b *testing.B) {
+	params := map[string]interface{}{"replicas": 2, "image": "nginx"}
+	b.Run("parse every time", func(b *testing.B) {
+		for i := 0; i < b.N; i++ {
+			// a distinct template is parsed as it's not in the cache
+			templ := fmt.Sprintf("%s// %d\n", cachedTemplate, i)
+			if err := NewWDTemplater("test", templ, "").Params(params).Complete(process.NewContext("test")); err != nil {
+				b.Fatal(err)
+			}
+		}
+	})
+	b.Run("reuse parsed template", func(b *testing.B) {
+		for i := 0; i < b.N; i++ {
+			err := NewWDTemplater("test", cachedTemplate, "").Params(params).Complete(process.NewContext("test"))
+			if err != nil {
+				b.Fatal(err)
+			}
+		}
+	})
+}
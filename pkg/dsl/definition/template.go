@@ -1,38 +1,41 @@
 package definition
 
 import (
-	"context"
 	"encoding/json"
 	"fmt"
 
 	"github.com/oam-dev/kubevela/pkg/dsl/task"
 
 	"cuelang.org/go/cue"
+	"cuelang.org/go/cue/ast"
 	"cuelang.org/go/cue/build"
 	"github.com/pkg/errors"
-	kerrors "k8s.io/apimachinery/pkg/api/errors"
-	"k8s.io/apimachinery/pkg/api/meta"
-	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
-	"k8s.io/apimachinery/pkg/runtime"
-	"sigs.k8s.io/controller-runtime/pkg/client"
 
 	"github.com/oam-dev/kubevela/pkg/dsl/model"
 	"github.com/oam-dev/kubevela/pkg/dsl/process"
-	"github.com/oam-dev/kubevela/pkg/oam"
-)
-
-var (
-	metadataAccessor = meta.NewAccessor()
 )
 
 // Template defines Definition's Render interface
 type Template interface {
 	Params(params interface{}) Template
 	Complete(ctx process.Context) error
-	Output(ctx process.Context, client client.Client, name string) Template
-	HealthCheck() error
+	Output(output map[string]interface{}) Template
+	HealthCheck() (HealthStatus, error)
+}
+
+// HealthStatus is the result of the health policy of a definition
+type HealthStatus struct {
+	Healthy bool
+	// Message is the `message` of the health policy, such as "2/3 replicas are ready"
+	Message string
+	// Details are the optional `details` of the health policy
+	Details map[string]string
 }
 
+// AuxiliaryWorkload is the type of the assistants rendered from the `outputs` of a workload template, they're
+// applied along with the workload in the same way as traits
+const AuxiliaryWorkload = "AuxiliaryWorkload"
+
 type def struct {
 	name   string
 	templ  string
@@ -41,6 +44,48 @@ type def struct {
 	output map[string]interface{}
 }
 
+// buildTemplate builds the template with the other files. The template is parsed only once, every build takes a copy
+// of the parsed template, as cue resolves the identifiers of a parsed file in place and the values evaluated from an
+// instance are cached in it, neither of them can be shared by the renderings safely.
+func buildTemplate(templ string, files ...source) (*cue.Instance, error) {
+	file, err := templates.get(templ)
+	if err != nil {
+		return nil, err
+	}
+	bi := build.NewContext().NewInstance("", nil)
+	if file == nil {
+		if err := bi.AddFile("-", templ); err != nil {
+			return nil, err
+		}
+	}
+	for _, f := range files {
+		if err := bi.AddFile(f.name, f.src); err != nil {
+			return nil, err
+		}
+	}
+	if file != nil {
+		// the template is resolved already, so it's added without resolving it again
+		bi.Files = append([]*ast.File{file}, bi.Files...)
+	}
+	return cue.Build([]*build.Instance{bi})[0], nil
+}
+
+// source is a CUE file built along with a template, such as the parameter and the context
+type source struct {
+	name string
+	src  string
+}
+
+// sources returns the parameter and the context that the template is built with
+func (d *def) sources(ctx process.Context) []source {
+	var files []source
+	if d.params != nil {
+		bt, _ := json.Marshal(d.params)
+		files = append(files, source{name: "parameter", src: fmt.Sprintf("parameter: %s", string(bt))})
+	}
+	return append(files, source{name: "-", src: ctx.Compile("context")})
+}
+
 type workloadDef struct {
 	def
 }
@@ -66,81 +111,60 @@ func (wd *workloadDef) Params(params interface{}) Template {
 
 // Complete do workload definition's rendering
 func (wd *workloadDef) Complete(ctx process.Context) error {
-	bi := build.NewContext().NewInstance("", nil)
-	if err := bi.AddFile("-", wd.templ); err != nil {
+	inst, err := buildTemplate(wd.templ, wd.sources(ctx)...)
+	if err != nil {
 		return err
 	}
-	if wd.params != nil {
-		bt, _ := json.Marshal(wd.params)
-		if err := bi.AddFile("parameter", fmt.Sprintf("parameter: %s", string(bt))); err != nil {
-			return err
+	if err := inst.Value().Err(); err != nil {
+		return errors.WithMessagef(err, "workloadDef %s eval", wd.name)
+	}
+	if inst.Lookup("processing").Exists() {
+		reader, ns := ctx.Cluster()
+		if inst, err = task.Process(inst, reader, ns); err != nil {
+			return errors.WithMessagef(err, "workloadDef %s process", wd.name)
 		}
 	}
-
-	if err := bi.AddFile("-", ctx.Compile("context")); err != nil {
-		return err
+	output := inst.Lookup("output")
+	base, err := model.NewBase(output)
+	if err != nil {
+		return errors.WithMessagef(err, "workloadDef %s new base", wd.name)
 	}
-	insts := cue.Build([]*build.Instance{bi})
-	for _, inst := range insts {
-		if err := inst.Value().Err(); err != nil {
-			return errors.WithMessagef(err, "workloadDef %s eval", wd.name)
-		}
-		output := inst.Lookup("output")
-		base, err := model.NewBase(output)
-		if err != nil {
-			return errors.WithMessagef(err, "workloadDef %s new base", wd.name)
+	if ctx.Explain() {
+		base.Explain(wd.name, output)
+	}
+	ctx.SetBase(base)
+
+	// the auxiliary objects are rendered along with the workload, e.g. the Service of a web service
+	outputs := inst.Lookup("outputs")
+	st, err := outputs.Struct()
+	if err == nil {
+		for i := 0; i < st.Len(); i++ {
+			fieldInfo := st.Field(i)
+			if fieldInfo.IsDefinition || fieldInfo.IsHidden || fieldInfo.IsOptional {
+				continue
+			}
+			other, err := model.NewOther(fieldInfo.Value)
+			if err != nil {
+				return errors.WithMessagef(err, "workloadDef %s new Assists(%s)", wd.name, fieldInfo.Name)
+			}
+			if ctx.Explain() {
+				other.Explain(wd.name, fieldInfo.Value)
+			}
+			ctx.PutAssistants(process.Assistant{Ins: other, Type: AuxiliaryWorkload, Name: fieldInfo.Name})
 		}
-		ctx.SetBase(base)
 	}
 	return nil
 }
 
-// Output fetch the workload cr and set result to context
-func (wd *workloadDef) Output(ctx process.Context, client client.Client, name string) Template {
-	base, _ := ctx.Output()
-	componentWorkload, err := base.Unstructured()
-	if err != nil {
-		return wd
-	}
-	workloadCr, err := getObj(client, componentWorkload, name)
-	if err != nil {
-		return wd
-	}
-	wd.output = workloadCr
+// Output sets the workload object rendered in the cluster, the health policy is evaluated against it
+func (wd *workloadDef) Output(output map[string]interface{}) Template {
+	wd.output = output
 	return wd
 }
 
 // HealthCheck address health check for workload
-func (wd *workloadDef) HealthCheck() error {
-	if wd.health == "" {
-		return nil
-	}
-	bi := build.NewContext().NewInstance("", nil)
-	if err := bi.AddFile("-", wd.health); err != nil {
-		return err
-	}
-	if wd.output != nil {
-		bt, _ := json.Marshal(wd.output)
-		if err := bi.AddFile("output", fmt.Sprintf("output: %s", string(bt))); err != nil {
-			return err
-		}
-	} else {
-		return errors.WithMessagef(errors.New("there is no workload output cr for health check"), "workload %s health check", wd.name)
-	}
-	insts := cue.Build([]*build.Instance{bi})
-	for _, inst := range insts {
-		if err := inst.Value().Err(); err != nil {
-			return errors.WithMessagef(err, "workload %s check", wd.name)
-		}
-		isHealthVal := inst.Lookup("isHealth")
-		if isHealthVal.Exists() {
-			healthRs := isHealthVal.Eval()
-			if isHealth, err := healthRs.Bool(); err != nil || !isHealth {
-				return errors.WithMessage(err, "the workload is unhealthy")
-			}
-		}
-	}
-	return nil
+func (wd *workloadDef) HealthCheck() (HealthStatus, error) {
+	return wd.healthCheck("workload")
 }
 
 type traitDef struct {
@@ -166,152 +190,131 @@ func (td *traitDef) Params(params interface{}) Template {
 
 // Complete do trait definition's rendering
 func (td *traitDef) Complete(ctx process.Context) error {
-	bi := build.NewContext().NewInstance("", nil)
-	if err := bi.AddFile("-", td.templ); err != nil {
+	inst, err := buildTemplate(td.templ, td.sources(ctx)...)
+	if err != nil {
 		return err
 	}
-	if td.params != nil {
-		bt, _ := json.Marshal(td.params)
-		if err := bi.AddFile("parameter", fmt.Sprintf("parameter: %s", string(bt))); err != nil {
-			return err
-		}
+	if err := inst.Value().Err(); err != nil {
+		return errors.WithMessagef(err, "traitDef %s build", td.name)
 	}
 
-	if err := bi.AddFile("f", ctx.Compile("context")); err != nil {
-		return err
-	}
-	insts := cue.Build([]*build.Instance{bi})
-	for _, inst := range insts {
-
-		if err := inst.Value().Err(); err != nil {
+	processing := inst.Lookup("processing")
+	if processing.Exists() {
+		reader, ns := ctx.Cluster()
+		if inst, err = task.Process(inst, reader, ns); err != nil {
 			return errors.WithMessagef(err, "traitDef %s build", td.name)
 		}
+	}
 
-		processing := inst.Lookup("processing")
-		var err error
-		if processing.Exists() {
-			if inst, err = task.Process(inst); err != nil {
-				return errors.WithMessagef(err, "traitDef %s build", td.name)
-			}
+	output := inst.Lookup("output")
+	if output.Exists() {
+		other, err := model.NewOther(output)
+		if err != nil {
+			return errors.WithMessagef(err, "traitDef %s new Assist", td.name)
 		}
-
-		output := inst.Lookup("output")
-		if output.Exists() {
-			other, err := model.NewOther(output)
-			if err != nil {
-				return errors.WithMessagef(err, "traitDef %s new Assist", td.name)
-			}
-			ctx.PutAssistants(process.Assistant{Ins: other, Type: td.name})
+		if ctx.Explain() {
+			other.Explain(td.name, output)
 		}
+		ctx.PutAssistants(process.Assistant{Ins: other, Type: td.name})
+	}
 
-		outputs := inst.Lookup("outputs")
-		st, err := outputs.Struct()
-		if err == nil {
-			for i := 0; i < st.Len(); i++ {
-				fieldInfo := st.Field(i)
-				if fieldInfo.IsDefinition || fieldInfo.IsHidden || fieldInfo.IsOptional {
-					continue
-				}
-				other, err := model.NewOther(fieldInfo.Value)
-				if err != nil {
-					return errors.WithMessagef(err, "traitDef %s new Assists(%s)", td.name, fieldInfo.Name)
-				}
-				ctx.PutAssistants(process.Assistant{Ins: other, Type: td.name})
+	outputs := inst.Lookup("outputs")
+	st, err := outputs.Struct()
+	if err == nil {
+		for i := 0; i < st.Len(); i++ {
+			fieldInfo := st.Field(i)
+			if fieldInfo.IsDefinition || fieldInfo.IsHidden || fieldInfo.IsOptional {
+				continue
 			}
-
-		}
-
-		patcher := inst.Lookup("patch")
-		if patcher.Exists() {
-			base, _ := ctx.Output()
-			p, err := model.NewOther(patcher)
+			other, err := model.NewOther(fieldInfo.Value)
 			if err != nil {
-				return errors.WithMessagef(err, "traitDef %s patcher NewOther", td.name)
+				return errors.WithMessagef(err, "traitDef %s new Assists(%s)", td.name, fieldInfo.Name)
 			}
-			if err := base.Unify(p); err != nil {
-				return err
+			if ctx.Explain() {
+				other.Explain(td.name, fieldInfo.Value)
 			}
+			ctx.PutAssistants(process.Assistant{Ins: other, Type: td.name, Name: fieldInfo.Name})
 		}
 
 	}
-	return nil
-}
 
-// Output fetch the trait cr and set result to context
-func (td *traitDef) Output(ctx process.Context, client client.Client, name string) Template {
-	_, assists := ctx.Output()
-	for _, assist := range assists {
-		if assist.Type != td.name {
-			continue
+	patcher := inst.Lookup("patch")
+	if patcher.Exists() {
+		base, _ := ctx.Output()
+		if base == nil {
+			return errors.Errorf("traitDef %s patch: there is no workload to patch", td.name)
 		}
-		traitRef, err := assist.Ins.Unstructured()
+		p, err := model.NewOther(patcher)
 		if err != nil {
-			return td
+			return errors.WithMessagef(err, "traitDef %s patcher NewOther", td.name)
 		}
-		traitCr, err := getObj(client, traitRef, name)
-		if err != nil {
-			return td
+		if ctx.Explain() {
+			p.Explain(td.name, patcher)
+		}
+		if err := base.Unify(p); err != nil {
+			return err
 		}
-		td.output = traitCr
-		return td
 	}
+	return nil
+}
+
+// Output sets the trait object rendered in the cluster, the health policy is evaluated against it
+func (td *traitDef) Output(output map[string]interface{}) Template {
+	td.output = output
 	return td
 }
 
 // HealthCheck address health check for trait
-func (td *traitDef) HealthCheck() error {
-	if td.health == "" {
-		return nil
+func (td *traitDef) HealthCheck() (HealthStatus, error) {
+	return td.healthCheck("trait")
+}
+
+// healthCheck evaluates the health policy against the output, the policy is made of
+// - isHealth: whether the output is healthy, the output is healthy if it's omitted
+// - message: optional, explains the health of the output
+// - details: optional, the details of the health such as the number of ready replicas
+func (d *def) healthCheck(kind string) (HealthStatus, error) {
+	if d.health == "" {
+		return HealthStatus{Healthy: true}, nil
 	}
-	bi := build.NewContext().NewInstance("", nil)
-	if err := bi.AddFile("-", td.health); err != nil {
-		return err
+	if d.output == nil {
+		return HealthStatus{}, errors.Errorf("there is no %s output cr for health check", kind)
 	}
-	if td.output != nil {
-		bt, _ := json.Marshal(td.output)
-		if err := bi.AddFile("output", fmt.Sprintf("output: %s", string(bt))); err != nil {
-			return err
-		}
-	} else {
-		return errors.WithMessagef(errors.New("there is no trait output cr for health check"), "trait %s health check", td.name)
+	bt, _ := json.Marshal(d.output)
+	inst, err := buildTemplate(d.health, source{name: "output", src: fmt.Sprintf("output: %s", string(bt))})
+	if err != nil {
+		return HealthStatus{}, errors.WithMessagef(err, "%s %s health policy", kind, d.name)
 	}
-	insts := cue.Build([]*build.Instance{bi})
-	for _, inst := range insts {
-		if err := inst.Value().Err(); err != nil {
-			return errors.WithMessagef(err, "trait %s check", td.name)
-		}
-		isHealthVal := inst.Lookup("isHealth")
-		if isHealthVal.Exists() {
-			if isHealth, err := isHealthVal.Bool(); err != nil || !isHealth {
-				return errors.WithMessage(err, "the trait is unhealthy")
-			}
-		}
+	if err := inst.Value().Err(); err != nil {
+		return HealthStatus{}, errors.WithMessagef(err, "%s %s check", kind, d.name)
 	}
-	return nil
-}
 
-func getObj(cli client.Client, obj runtime.Object, name string) (map[string]interface{}, error) {
-	var kind, apiVersion string
-	var err error
-	kind, err = metadataAccessor.Kind(obj)
-	if err != nil {
-		return nil, fmt.Errorf("cannot access object kind")
-	}
-	apiVersion, err = metadataAccessor.APIVersion(obj)
-	if err != nil {
-		return nil, fmt.Errorf("cannot access object kind")
+	status := HealthStatus{Healthy: true}
+	if isHealthVal := inst.Lookup("isHealth"); isHealthVal.Exists() {
+		isHealth, err := isHealthVal.Eval().Bool()
+		if err != nil {
+			return HealthStatus{}, errors.WithMessagef(err, "%s %s evaluate isHealth", kind, d.name)
+		}
+		status.Healthy = isHealth
 	}
-	unList := &unstructured.UnstructuredList{}
-	unList.SetKind(kind)
-	unList.SetAPIVersion(apiVersion)
-	if err := cli.List(context.Background(), unList, client.MatchingLabels{oam.LabelAppName: name}); err != nil {
-		if kerrors.IsNotFound(err) {
-			return nil, nil
+	if messageVal := inst.Lookup("message"); messageVal.Exists() {
+		message, err := messageVal.Eval().String()
+		if err != nil {
+			return HealthStatus{}, errors.WithMessagef(err, "%s %s evaluate message", kind, d.name)
 		}
-		return nil, err
+		status.Message = message
+	} else if !status.Healthy {
+		status.Message = fmt.Sprintf("the %s is unhealthy", kind)
 	}
-	if len(unList.Items) == 0 {
-		return nil, nil
+	if detailsVal := inst.Lookup("details"); detailsVal.Exists() {
+		var details map[string]interface{}
+		if err := detailsVal.Decode(&details); err != nil {
+			return HealthStatus{}, errors.WithMessagef(err, "%s %s evaluate details", kind, d.name)
+		}
+		status.Details = make(map[string]string, len(details))
+		for k, v := range details {
+			status.Details[k] = fmt.Sprint(v)
+		}
 	}
-	return unList.Items[0].Object, nil
+	return status, nil
 }
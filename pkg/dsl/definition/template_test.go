@@ -55,6 +55,44 @@ parameter: {
 
 }
 
+func TestWDTemplateOutputs(t *testing.T) {
+	templ := `
+output: {
+	apiVersion: "apps/v1"
+	kind:       "Deployment"
+	metadata: name: context.name
+}
+outputs: {
+	service: {
+		apiVersion: "v1"
+		kind:       "Service"
+		spec: ports: [{port: parameter.port}]
+	}
+	pdb: {
+		apiVersion: "policy/v1beta1"
+		kind:       "PodDisruptionBudget"
+		spec: minAvailable: 1
+	}
+}
+parameter: port: int
+`
+	ctx := process.NewContext("test")
+	if err := NewWDTemplater("-", templ, "").Params(map[string]interface{}{"port": 80}).Complete(ctx); err != nil {
+		t.Error(err)
+		return
+	}
+	base, assists := ctx.Output()
+	assert.Equal(t, false, base == nil)
+	assert.Equal(t, 2, len(assists))
+	assert.Equal(t, AuxiliaryWorkload, assists[0].Type)
+	assert.Equal(t, "service", assists[0].Name)
+	svc, err := assists[0].Ins.Unstructured()
+	assert.Equal(t, nil, err)
+	assert.Equal(t, &unstructured.Unstructured{Object: map[string]interface{}{"apiVersion": "v1", "kind": "Service",
+		"spec": map[string]interface{}{"ports": []interface{}{map[string]interface{}{"port": int64(80)}}}}}, svc)
+	assert.Equal(t, "pdb", assists[1].Name)
+}
+
 func TestTDTemplate(t *testing.T) {
 	baseTemplate := `
 output:{
@@ -133,3 +171,127 @@ parameter: {
 		}}
 	assert.Equal(t, expect, obj)
 }
+
+func TestTDTemplatePatchStrategy(t *testing.T) {
+	baseTemplate := `
+output: {
+	apiVersion: "apps/v1"
+	kind:       "Deployment"
+	spec: template: spec: {
+		containers: [{
+			name:  "main"
+			image: "website:0.1"
+			args: ["--debug"]
+			env: [{name: "MODE", value: "dev"}, {name: "TOKEN", value: "xxx"}]
+		}]
+		volumes: [{name: "data", emptyDir: {}}]
+	}
+}
+`
+	ctx := process.NewContext("test")
+	if err := NewWDTemplater("-", baseTemplate, "").Complete(ctx); err != nil {
+		t.Error(err)
+		return
+	}
+
+	td := NewTDTemplater("-", `
+patch: spec: template: spec: {
+	// +patchKey=name
+	containers: [{
+		name: "main"
+		// +patchStrategy=replace
+		args: ["--verbose"]
+		env: [{name: "TOKEN", $patch: "delete"}]
+	}]
+	// +patchKey=name
+	// +patchStrategy=retainKeys
+	volumes: [{name: "data", hostPath: path: parameter.path}]
+}
+parameter: path: string
+`, "")
+	if err := td.Params(map[string]interface{}{"path": "/data"}).Complete(ctx); err != nil {
+		t.Error(err)
+		return
+	}
+
+	base, _ := ctx.Output()
+	obj, err := base.Unstructured()
+	assert.Equal(t, nil, err)
+	expect := &unstructured.Unstructured{
+		Object: map[string]interface{}{
+			"apiVersion": "apps/v1",
+			"kind":       "Deployment",
+			"spec": map[string]interface{}{
+				"template": map[string]interface{}{
+					"spec": map[string]interface{}{
+						"containers": []interface{}{map[string]interface{}{
+							"name":  "main",
+							"image": "website:0.1",
+							"args":  []interface{}{"--verbose"},
+							"env":   []interface{}{map[string]interface{}{"name": "MODE", "value": "dev"}},
+						}},
+						"volumes": []interface{}{map[string]interface{}{
+							"name":     "data",
+							"hostPath": map[string]interface{}{"path": "/data"},
+						}},
+					}}}},
+	}
+	assert.Equal(t, expect, obj)
+}
+
+func TestHealthCheck(t *testing.T) {
+	policy := `
+isHealth: output.status.readyReplicas == output.spec.replicas
+message: "\(output.status.readyReplicas)/\(output.spec.replicas) replicas are ready"
+details: {
+	ready: output.status.readyReplicas
+}
+`
+	output := map[string]interface{}{
+		"spec":   map[string]interface{}{"replicas": 3},
+		"status": map[string]interface{}{"readyReplicas": 2},
+	}
+
+	testCases := []struct {
+		templ     Template
+		expStatus HealthStatus
+		expErr    bool
+	}{
+		{
+			templ: NewWDTemplater("web", "", policy).Output(output),
+			expStatus: HealthStatus{Healthy: false, Message: "2/3 replicas are ready",
+				Details: map[string]string{"ready": "2"}},
+		},
+		{
+			templ:     NewTDTemplater("scaler", "", "isHealth: output.status.readyReplicas > 1").Output(output),
+			expStatus: HealthStatus{Healthy: true},
+		},
+		{
+			templ:     NewTDTemplater("scaler", "", "isHealth: false").Output(output),
+			expStatus: HealthStatus{Healthy: false, Message: "the trait is unhealthy"},
+		},
+		{
+			// the output is healthy if the policy only explains it
+			templ:     NewTDTemplater("scaler", "", `message: "\(output.status.readyReplicas) replicas are ready"`).Output(output),
+			expStatus: HealthStatus{Healthy: true, Message: "2 replicas are ready"},
+		},
+		{
+			templ:     NewWDTemplater("web", "", "").Output(nil),
+			expStatus: HealthStatus{Healthy: true},
+		},
+		{
+			templ:  NewWDTemplater("web", "", policy).Output(nil),
+			expErr: true,
+		},
+		{
+			templ:  NewWDTemplater("web", "", "isHealth: output.status.unknown == 1").Output(output),
+			expErr: true,
+		},
+	}
+
+	for _, v := range testCases {
+		status, err := v.templ.HealthCheck()
+		assert.Equal(t, v.expErr, err != nil)
+		assert.Equal(t, v.expStatus, status)
+	}
+}
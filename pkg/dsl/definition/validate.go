@@ -0,0 +1,255 @@
+package definition
+
+import (
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strings"
+
+	"cuelang.org/go/cue"
+	"cuelang.org/go/cue/ast"
+	"cuelang.org/go/cue/format"
+	"cuelang.org/go/cue/token"
+	"github.com/pkg/errors"
+
+	mycue "github.com/oam-dev/kubevela/pkg/cue"
+)
+
+// FieldError is an invalid field of the parameters
+type FieldError struct {
+	// Path is the path of the field, such as `settings.env[0].name`
+	Path    string
+	Message string
+}
+
+// Error implements error
+func (e FieldError) Error() string {
+	return fmt.Sprintf("%s: %s", e.Path, e.Message)
+}
+
+// FieldErrors are the invalid fields of the parameters
+type FieldErrors []FieldError
+
+// Error implements error
+func (e FieldErrors) Error() string {
+	msgs := make([]string, 0, len(e))
+	for _, fe := range e {
+		msgs = append(msgs, fe.Error())
+	}
+	return strings.Join(msgs, "; ")
+}
+
+// ValidateParameters checks the parameters against the `parameter` of the template before rendering, it returns
+// FieldErrors of the fields with the wrong type or value, the unknown fields and the missing required fields. The
+// paths of the fields start with the label, e.g. `settings`. Nothing is checked if the template has no `parameter`.
+func ValidateParameters(templ, label string, params map[string]interface{}) error {
+	if templ == "" {
+		return nil
+	}
+	var r cue.Runtime
+	inst, err := r.Compile("-", templ+mycue.BaseTemplate)
+	if err != nil {
+		return errors.Wrap(err, "compile template")
+	}
+	schema := inst.Lookup("parameter")
+	if !schema.Exists() {
+		return nil
+	}
+	if params == nil {
+		params = map[string]interface{}{}
+	}
+	bt, err := json.Marshal(params)
+	if err != nil {
+		return err
+	}
+	data, err := r.Compile("-", string(bt))
+	if err != nil {
+		return errors.Wrapf(err, "compile %s", label)
+	}
+	var errs FieldErrors
+	validateValue(schema, data.Value(), label, &errs)
+	if len(errs) == 0 {
+		return nil
+	}
+	sort.SliceStable(errs, func(i, j int) bool {
+		return errs[i].Path < errs[j].Path
+	})
+	return errs
+}
+
+// validateValue checks the data against the schema field by field, so that the error is reported on the field. The
+// structs and lists are checked against each disjunct of the schema such as `{a: string} | {b: string}`, the data is
+// valid if it matches any of them, otherwise the errors of the disjunct with the fewest errors are reported.
+func validateValue(schema, data cue.Value, path string, errs *FieldErrors) {
+	if kind := data.Kind(); kind == cue.StructKind || kind == cue.ListKind {
+		var closest FieldErrors
+		matched := false
+		for _, d := range disjuncts(schema) {
+			if d.IncompleteKind()&kind == 0 {
+				continue
+			}
+			var derrs FieldErrors
+			if kind == cue.StructKind {
+				validateStruct(d, data, path, &derrs)
+			} else {
+				validateList(d, data, path, &derrs)
+			}
+			if len(derrs) == 0 {
+				return
+			}
+			if !matched || len(derrs) < len(closest) {
+				closest = derrs
+			}
+			matched = true
+		}
+		if matched {
+			*errs = append(*errs, closest...)
+			return
+		}
+	}
+	validateConcrete(schema, data, path, errs)
+}
+
+func validateList(schema, data cue.Value, path string, errs *FieldErrors) {
+	elem, ok := schema.Elem()
+	if !ok {
+		validateConcrete(schema, data, path, errs)
+		return
+	}
+	iter, _ := data.List()
+	for i := 0; iter.Next(); i++ {
+		validateValue(elem, iter.Value(), fmt.Sprintf("%s[%d]", path, i), errs)
+	}
+}
+
+func validateConcrete(schema, data cue.Value, path string, errs *FieldErrors) {
+	if err := schema.Unify(data).Validate(cue.Concrete(true)); err != nil {
+		*errs = append(*errs, FieldError{Path: path,
+			Message: fmt.Sprintf("expected %s, got %s", expected(schema), marshal(data))})
+	}
+}
+
+// disjuncts returns the disjuncts of the schema, or the schema itself if it's not a disjunction. The defaults
+// subsumed by the other disjuncts are left out, e.g. `[...string] | *[]` has the only disjunct `[...string]`.
+func disjuncts(schema cue.Value) []cue.Value {
+	op, args := schema.Expr()
+	if op == cue.OrOp || (op == cue.NoOp && len(args) == 1) {
+		return args
+	}
+	return []cue.Value{schema}
+}
+
+// hasDefault checks whether the field has a default, a default referring to the context such as
+// `*context.name | string` isn't concrete before rendering so the syntax of the field is checked as well
+func hasDefault(field cue.Value) bool {
+	if _, ok := field.Default(); ok {
+		return true
+	}
+	return markedDefault(field.Source())
+}
+
+func markedDefault(n ast.Node) bool {
+	switch x := n.(type) {
+	case *ast.BinaryExpr:
+		return x.Op == token.OR && (markedDefault(x.X) || markedDefault(x.Y))
+	case *ast.UnaryExpr:
+		return x.Op == token.MUL
+	case *ast.ParenExpr:
+		return markedDefault(x.X)
+	case *ast.Field:
+		return markedDefault(x.Value)
+	}
+	return false
+}
+
+func validateStruct(schema, data cue.Value, path string, errs *FieldErrors) {
+	fields := map[string]cue.Value{}
+	var required []string
+	if iter, err := schema.Fields(cue.Optional(true)); err == nil {
+		for iter.Next() {
+			fields[iter.Label()] = iter.Value()
+			if !iter.IsOptional() {
+				required = append(required, iter.Label())
+			}
+		}
+	}
+	// the fields not in the schema are accepted by a pattern such as `[string]: string` or by `...`
+	elem, open := schema.Elem()
+
+	given := map[string]bool{}
+	iter, err := data.Fields()
+	for err == nil && iter.Next() {
+		label := iter.Label()
+		given[label] = true
+		fieldPath := path + "." + label
+		if field, ok := fields[label]; ok {
+			validateValue(field, iter.Value(), fieldPath, errs)
+			continue
+		}
+		if !open {
+			*errs = append(*errs, FieldError{Path: fieldPath, Message: fmt.Sprintf("unknown field, expected one of %s",
+				strings.Join(sortedKeys(fields), ", "))})
+			continue
+		}
+		validateValue(elem, iter.Value(), fieldPath, errs)
+	}
+
+	for _, label := range required {
+		field := fields[label]
+		if given[label] {
+			continue
+		}
+		if hasDefault(field) || field.Validate(cue.Concrete(true)) == nil {
+			continue
+		}
+		if d := disjuncts(field); len(d) == 1 && d[0].IncompleteKind() == cue.StructKind {
+			// the required fields of a struct are reported one by one
+			validateStruct(d[0], cue.Value{}, path+"."+label, errs)
+			continue
+		}
+		*errs = append(*errs, FieldError{Path: path + "." + label,
+			Message: fmt.Sprintf("missing required field, expected %s", expected(field))})
+	}
+}
+
+// expected returns the type or the values that the schema expects, such as `int` or `"ClusterIP" | "NodePort"`
+func expected(schema cue.Value) string {
+	op, args := schema.Expr()
+	if len(args) == 0 {
+		return node(schema)
+	}
+	sep := map[cue.Op]string{cue.OrOp: " | ", cue.AndOp: " & "}[op]
+	if sep == "" {
+		return node(args[0])
+	}
+	parts := make([]string, 0, len(args))
+	for _, arg := range args {
+		parts = append(parts, node(arg))
+	}
+	return strings.Join(parts, sep)
+}
+
+func node(v cue.Value) string {
+	bt, err := format.Node(v.Syntax())
+	if err != nil {
+		return v.IncompleteKind().String()
+	}
+	return string(bt)
+}
+
+func marshal(v cue.Value) string {
+	bt, err := v.MarshalJSON()
+	if err != nil {
+		return v.Kind().String()
+	}
+	return string(bt)
+}
+
+func sortedKeys(m map[string]cue.Value) []string {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
@@ -0,0 +1,109 @@
+package definition
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestValidateParameters(t *testing.T) {
+	templ := `
+output: {
+	kind: "Deployment"
+	metadata: name: context.name
+}
+parameter: {
+	name:  *context.name | string
+	image: string
+	port:  *80 | int
+	type:  *"ClusterIP" | "NodePort"
+	cpu?:  string & =~"^[0-9]+m$"
+	env?: [...{
+		name:   string
+		value?: string
+	}]
+	labels?: [string]: string
+	volumes?: [...{
+		name:     string
+		hostPath: string
+	} | {
+		name:      string
+		configMap: string
+	}]
+	ports: [...{port: int}] | *[]
+	resources?: {
+		limits: {
+			memory: string
+		}
+	}
+}`
+	testCases := map[string]struct {
+		params map[string]interface{}
+		errs   FieldErrors
+	}{
+		"valid": {
+			params: map[string]interface{}{"image": "nginx", "port": 8080, "type": "NodePort",
+				"env":    []interface{}{map[string]interface{}{"name": "ENV", "value": "prod"}},
+				"labels": map[string]interface{}{"team": "vela"},
+				"volumes": []interface{}{map[string]interface{}{"name": "data", "hostPath": "/data"},
+					map[string]interface{}{"name": "conf", "configMap": "conf"}},
+				"ports": []interface{}{map[string]interface{}{"port": 80}}},
+		},
+		"disjunctions": {
+			params: map[string]interface{}{"image": "nginx",
+				"volumes": []interface{}{map[string]interface{}{"name": "conf", "configMap": 1},
+					map[string]interface{}{"name": "data", "hostPth": "/data"}},
+				"ports": []interface{}{map[string]interface{}{"port": 80, "protocol": "TCP"}}},
+			errs: FieldErrors{
+				{Path: "settings.ports[0].protocol", Message: "unknown field, expected one of port"},
+				{Path: "settings.volumes[0].configMap", Message: "expected string, got 1"},
+				{Path: "settings.volumes[1].hostPath", Message: "missing required field, expected string"},
+				{Path: "settings.volumes[1].hostPth", Message: "unknown field, expected one of hostPath, name"},
+			},
+		},
+		"wrong type": {
+			params: map[string]interface{}{"image": "nginx", "port": "8080"},
+			errs:   FieldErrors{{Path: "settings.port", Message: `expected int, got "8080"`}},
+		},
+		"not in enum": {
+			params: map[string]interface{}{"image": "nginx", "type": "Nodeport"},
+			errs:   FieldErrors{{Path: "settings.type", Message: `expected "ClusterIP" | "NodePort", got "Nodeport"`}},
+		},
+		"constraint": {
+			params: map[string]interface{}{"image": "nginx", "cpu": "1"},
+			errs:   FieldErrors{{Path: "settings.cpu", Message: `expected string & =~"^[0-9]+m$", got "1"`}},
+		},
+		"unknown and nested fields": {
+			params: map[string]interface{}{"image": "nginx", "imagePullPolicy": "Always",
+				"env":       []interface{}{map[string]interface{}{"name": 1}, map[string]interface{}{"name": "A", "valu": "b"}},
+				"labels":    map[string]interface{}{"team": 1},
+				"resources": map[string]interface{}{"limits": map[string]interface{}{}}},
+			errs: FieldErrors{
+				{Path: "settings.env[0].name", Message: "expected string, got 1"},
+				{Path: "settings.env[1].valu", Message: "unknown field, expected one of name, value"},
+				{Path: "settings.imagePullPolicy",
+					Message: "unknown field, expected one of cpu, env, image, labels, name, port, ports, resources, type, " +
+						"volumes"},
+				{Path: "settings.labels.team", Message: "expected string, got 1"},
+				{Path: "settings.resources.limits.memory", Message: "missing required field, expected string"},
+			},
+		},
+		"missing required field": {
+			params: nil,
+			errs:   FieldErrors{{Path: "settings.image", Message: "missing required field, expected string"}},
+		},
+	}
+	for name, tc := range testCases {
+		t.Run(name, func(t *testing.T) {
+			err := ValidateParameters(templ, "settings", tc.params)
+			if tc.errs == nil {
+				assert.NoError(t, err)
+				return
+			}
+			assert.Equal(t, tc.errs, err)
+		})
+	}
+
+	// the template without parameter isn't checked
+	assert.NoError(t, ValidateParameters(`output: kind: "Route"`, "properties", map[string]interface{}{"a": 1}))
+}
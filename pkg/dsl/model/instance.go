@@ -1,6 +1,8 @@
 package model
 
 import (
+	"encoding/json"
+
 	"cuelang.org/go/cue"
 	"cuelang.org/go/cue/ast"
 	"cuelang.org/go/cue/format"
@@ -15,11 +17,18 @@ type Instance interface {
 	Unstructured() (*unstructured.Unstructured, error)
 	IsBase() bool
 	Unify(other Instance) error
+	// Explain starts to record the sources of the fields, the value is what the instance is created from
+	Explain(definition string, v cue.Value)
+	// Provenance returns the sources of the fields, it's nil if the instance isn't explained
+	Provenance() Provenance
 }
 
 type instance struct {
 	v    string
 	base bool
+	exp  *explanation
+	// json is the value compiled to JSON, it's kept until the value is changed by Unify
+	json []byte
 }
 
 // String return instance's cue format string
@@ -33,13 +42,21 @@ func (inst *instance) IsBase() bool {
 }
 
 func (inst *instance) compile() ([]byte, error) {
+	if inst.json != nil {
+		return inst.json, nil
+	}
 	var r cue.Runtime
 	cueInst, err := r.Compile("-", inst.v)
 	if err != nil {
 		return nil, err
 	}
 
-	return cueInst.Value().MarshalJSON()
+	jsonv, err := cueInst.Value().MarshalJSON()
+	if err != nil {
+		return nil, err
+	}
+	inst.json = jsonv
+	return jsonv, nil
 }
 
 // Unstructured convert cue values to unstructured.Unstructured
@@ -65,10 +82,96 @@ func (inst *instance) Unify(other Instance) error {
 	if err != nil {
 		return err
 	}
+	if inst.exp != nil {
+		if err := inst.explainPatch(pv, other); err != nil {
+			return err
+		}
+	}
 	inst.v = pv
+	inst.json = nil
 	return nil
 }
 
+// Explain starts to record the sources of the fields
+func (inst *instance) Explain(definition string, v cue.Value) {
+	inst.exp = explain(definition, v)
+}
+
+// Provenance returns the sources of the fields
+func (inst *instance) Provenance() Provenance {
+	if inst.exp == nil {
+		return nil
+	}
+	sources := make(Provenance, len(inst.exp.sources))
+	for path, s := range inst.exp.sources {
+		sources[path] = append([]Source{}, s...)
+	}
+	return sources
+}
+
+// explainPatch records the definition of the patch as a source of the fields that the patch sets, even if they have
+// the same values before, so that the conflicts of the definitions are explained. The fields added or changed by the
+// patch in other ways are attributed to the definition of the patch, and the fields removed by the patch are forgotten.
+// The list items merged by the patch keys are matched by the keys rather than the indexes as the patch may delete or
+// move them.
+func (inst *instance) explainPatch(patched string, other Instance) error {
+	keys, err := patchKeys(other.String())
+	if err != nil {
+		return err
+	}
+	before, err := inst.keyedLeaves(keys)
+	if err != nil {
+		return err
+	}
+	after, err := (&instance{v: patched}).keyedLeaves(keys)
+	if err != nil {
+		return err
+	}
+	patch := &explanation{sources: Provenance{}}
+	patchLeaves := map[string]leaf{}
+	if o, ok := other.(*instance); ok && o.exp != nil {
+		patch = o.exp
+		// the patch isn't always concrete by itself, its fields are matched by the indexes then
+		if patchLeaves, err = o.keyedLeaves(keys); err != nil {
+			patchLeaves = map[string]leaf{}
+			for path := range patch.sources {
+				patchLeaves[path] = leaf{path: path}
+			}
+		}
+	}
+
+	sources := make(Provenance, len(after))
+	for keyed, l := range after {
+		var s []Source
+		old, existed := before[keyed]
+		if existed {
+			s = append(s, inst.exp.sources[old.path]...)
+		}
+		if p, ok := patchLeaves[keyed]; ok && len(patch.sources[p.path]) > 0 {
+			s = append(s, patch.sources[p.path]...)
+		} else if !existed || old.value != l.value {
+			s = append(s, Source{Definition: patch.definition})
+		}
+		sources[l.path] = s
+	}
+	inst.exp.sources = sources
+	return nil
+}
+
+func (inst *instance) keyedLeaves(keys map[string][]string) (map[string]leaf, error) {
+	bt, err := inst.compile()
+	if err != nil {
+		return nil, err
+	}
+	var obj interface{}
+	if err := json.Unmarshal(bt, &obj); err != nil {
+		return nil, err
+	}
+	ret := map[string]leaf{}
+	keyedLeaves(obj, "", "", keys, ret)
+	return ret, nil
+}
+
 // NewBase create a base instance
 func NewBase(v cue.Value) (Instance, error) {
 	vs, err := openPrint(v)
@@ -61,3 +61,85 @@ metadata: name: "test"
 		assert.Equal(t, false, other.IsBase())
 	}
 }
+
+func TestInstanceExplain(t *testing.T) {
+	var r cue.Runtime
+	inst, err := r.Compile("-", `
+context: name: "myweb"
+parameter: {
+	image: "nginx"
+	env: [{name: "A", value: "a"}]
+}
+output: {
+	metadata: name: "\(context.name)-deploy"
+	spec: containers: [{
+		image: parameter.image
+		env:   parameter.env
+	}]
+	spec: replicas: 1
+}
+`)
+	assert.Equal(t, nil, err)
+	output := inst.Lookup("output")
+	base, err := NewBase(output)
+	assert.Equal(t, nil, err)
+	assert.Equal(t, Provenance(nil), base.Provenance())
+
+	base.Explain("webservice", output)
+	assert.Equal(t, Provenance{
+		"metadata.name":                   {{Definition: "webservice", Parameter: "context.name"}},
+		"spec.containers[0].image":        {{Definition: "webservice", Parameter: "parameter.image"}},
+		"spec.containers[0].env[0].name":  {{Definition: "webservice", Parameter: "parameter.env[0].name"}},
+		"spec.containers[0].env[0].value": {{Definition: "webservice", Parameter: "parameter.env[0].value"}},
+		"spec.replicas":                   {{Definition: "webservice"}},
+	}, base.Provenance())
+
+	inst, err = r.Compile("-", `
+parameter: value: "b"
+patch: spec: containers: [{
+	// +patchKey=name
+	env: [{name: "B", value: parameter.value}]
+}]
+`)
+	assert.Equal(t, nil, err)
+	patch := inst.Lookup("patch")
+	other, err := NewOther(patch)
+	assert.Equal(t, nil, err)
+	other.Explain("env", patch)
+	assert.Equal(t, nil, base.Unify(other))
+	assert.Equal(t, Provenance{
+		"metadata.name":                   {{Definition: "webservice", Parameter: "context.name"}},
+		"spec.containers[0].image":        {{Definition: "webservice", Parameter: "parameter.image"}},
+		"spec.containers[0].env[0].name":  {{Definition: "webservice", Parameter: "parameter.env[0].name"}},
+		"spec.containers[0].env[0].value": {{Definition: "webservice", Parameter: "parameter.env[0].value"}},
+		"spec.containers[0].env[1].name":  {{Definition: "env"}},
+		"spec.containers[0].env[1].value": {{Definition: "env", Parameter: "parameter.value"}},
+		"spec.replicas":                   {{Definition: "webservice"}},
+	}, base.Provenance())
+
+	// the items merged by the patch key keep their sources after the items before them are deleted, and the fields
+	// set to the same values are attributed to both definitions
+	inst, err = r.Compile("-", `
+patch: spec: {
+	replicas: 1
+	containers: [{
+		// +patchKey=name
+		env: [{name: "A", "$patch": "delete"}, {name: "B", value: "b"}]
+	}]
+}
+`)
+	assert.Equal(t, nil, err)
+	patch = inst.Lookup("patch")
+	other, err = NewOther(patch)
+	assert.Equal(t, nil, err)
+	other.Explain("override", patch)
+	assert.Equal(t, nil, base.Unify(other))
+	assert.Equal(t, Provenance{
+		"metadata.name":                  {{Definition: "webservice", Parameter: "context.name"}},
+		"spec.containers[0].image":       {{Definition: "webservice", Parameter: "parameter.image"}},
+		"spec.containers[0].env[0].name": {{Definition: "env"}, {Definition: "override"}},
+		"spec.containers[0].env[0].value": {{Definition: "env", Parameter: "parameter.value"},
+			{Definition: "override"}},
+		"spec.replicas": {{Definition: "webservice"}, {Definition: "override"}},
+	}, base.Provenance())
+}
@@ -0,0 +1,231 @@
+package model
+
+import (
+	"encoding/json"
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"cuelang.org/go/cue"
+
+	"github.com/oam-dev/kubevela/pkg/dsl/model/sets"
+)
+
+// Source is where the value of a field in the rendered object comes from
+type Source struct {
+	// Definition is the name of the workload type or the trait that sets the field
+	Definition string `json:"definition"`
+	// Parameter is the parameter or the context field that the value refers to, e.g. `parameter.image`, the
+	// references are separated by commas if there are more than one, and it's empty if the value is a constant
+	Parameter string `json:"parameter,omitempty"`
+}
+
+// Provenance maps the paths of the fields to their sources, the paths are like `spec.containers[0].image`. The
+// sources are in the order that the definitions set the field, the last one sets the value in the rendered object and
+// the former ones are overridden or set the same value.
+type Provenance map[string][]Source
+
+// explanation records the sources of the leaf fields of an instance
+type explanation struct {
+	definition string
+	sources    Provenance
+}
+
+var identifier = regexp.MustCompile(`^[a-zA-Z_$][a-zA-Z0-9_$]*$`)
+
+func fieldPath(parent, label string) string {
+	if !identifier.MatchString(label) {
+		label = fmt.Sprintf("[%q]", label)
+		return parent + label
+	}
+	if parent == "" {
+		return label
+	}
+	return parent + "." + label
+}
+
+func indexPath(parent string, i int) string {
+	return fmt.Sprintf("%s[%d]", parent, i)
+}
+
+var listIndex = regexp.MustCompile(`\[[0-9]+\]`)
+
+// anyIndex replaces the list indexes of the path with `[*]`
+func anyIndex(path string) string {
+	return listIndex.ReplaceAllString(path, "[*]")
+}
+
+// explain walks the value that the instance is created from, the fields referring to the parameter or the context
+// are attributed to them, and the nested fields of the referred struct or list are attributed to the nested fields
+func explain(definition string, v cue.Value) *explanation {
+	e := &explanation{definition: definition, sources: Provenance{}}
+	e.walk(v, "", "")
+	return e
+}
+
+func (e *explanation) walk(v cue.Value, path, ref string) {
+	if r := reference(v); r != "" {
+		ref = r
+	}
+	switch v.IncompleteKind() {
+	case cue.StructKind:
+		if iter, err := v.Fields(); err == nil && iter.Next() {
+			for ok := true; ok; ok = iter.Next() {
+				e.walk(iter.Value(), fieldPath(path, iter.Label()), refPath(ref, fieldPath("", iter.Label())))
+			}
+			return
+		}
+	case cue.ListKind:
+		if iter, err := v.List(); err == nil && iter.Next() {
+			for i, ok := 0, true; ok; i, ok = i+1, iter.Next() {
+				e.walk(iter.Value(), indexPath(path, i), refPath(ref, indexPath("", i)))
+			}
+			return
+		}
+	}
+	if ref == "" {
+		ref = strings.Join(references(v, 0), ", ")
+	}
+	e.sources[path] = []Source{{Definition: e.definition, Parameter: ref}}
+}
+
+func refPath(ref, sub string) string {
+	if ref == "" {
+		return ""
+	}
+	if strings.HasPrefix(sub, "[") {
+		return ref + sub
+	}
+	return ref + "." + sub
+}
+
+// reference returns the parameter or the context field that the value refers to directly
+func reference(v cue.Value) string {
+	_, path := v.Reference()
+	if len(path) == 0 || (path[0] != "parameter" && path[0] != "context") {
+		return ""
+	}
+	ref := path[0]
+	for _, label := range path[1:] {
+		ref = fieldPath(ref, label)
+	}
+	return ref
+}
+
+// references returns the parameters and the context fields that the expression of the value refers to, such as
+// `parameter.name` and `context.name` of "\(context.name)-\(parameter.name)"
+func references(v cue.Value, depth int) []string {
+	if ref := reference(v); ref != "" {
+		return []string{ref}
+	}
+	op, args := v.Expr()
+	if depth > 8 || (op == cue.NoOp && len(args) <= 1) {
+		return nil
+	}
+	var refs []string
+	seen := map[string]bool{}
+	for _, arg := range args {
+		for _, ref := range references(arg, depth+1) {
+			if !seen[ref] {
+				seen[ref] = true
+				refs = append(refs, ref)
+			}
+		}
+	}
+	return refs
+}
+
+// leaf is a leaf field of an object in JSON
+type leaf struct {
+	// path is the path of the field with the list indexes
+	path  string
+	value string
+}
+
+// keyedLeaves returns the leaf fields of the object in JSON by their keyed paths, the empty structs and lists are
+// leaves too. The indexes of the list items merged by patch keys are replaced by the values of the keys in the keyed
+// paths, e.g. `env[name="A"].value`, so that a field is matched across a patch even if the items are deleted or moved
+// by the patch. The keys are the patch keys of the lists by their paths with any index, e.g. `containers[*].env`.
+func keyedLeaves(obj interface{}, path, keyed string, keys map[string][]string, ret map[string]leaf) {
+	switch x := obj.(type) {
+	case map[string]interface{}:
+		if len(x) > 0 {
+			for k, v := range x {
+				keyedLeaves(v, fieldPath(path, k), fieldPath(keyed, k), keys, ret)
+			}
+			return
+		}
+	case []interface{}:
+		if len(x) > 0 {
+			labels := itemKeys(x, keys[anyIndex(path)])
+			for i, v := range x {
+				label := indexPath("", i)
+				if labels != nil {
+					label = labels[i]
+				}
+				keyedLeaves(v, indexPath(path, i), keyed+label, keys, ret)
+			}
+			return
+		}
+	}
+	bt, _ := json.Marshal(obj)
+	ret[keyed] = leaf{path: path, value: string(bt)}
+}
+
+// itemKeys returns the labels of the list items by the values of the patch keys, such as `[name="A"]`. It returns
+// nil if the list isn't merged by keys, or any item doesn't have the keys, or the keys of the items are not unique.
+func itemKeys(items []interface{}, keys []string) []string {
+	if len(keys) == 0 {
+		return nil
+	}
+	labels := make([]string, 0, len(items))
+	seen := map[string]bool{}
+	for _, item := range items {
+		m, ok := item.(map[string]interface{})
+		if !ok {
+			return nil
+		}
+		values := make([]string, 0, len(keys))
+		for _, key := range keys {
+			v, ok := m[key]
+			if !ok {
+				return nil
+			}
+			switch v.(type) {
+			case map[string]interface{}, []interface{}:
+				return nil
+			}
+			bt, _ := json.Marshal(v)
+			values = append(values, key+"="+string(bt))
+		}
+		label := "[" + strings.Join(values, ",") + "]"
+		if seen[label] {
+			return nil
+		}
+		seen[label] = true
+		labels = append(labels, label)
+	}
+	return labels
+}
+
+// patchKeys returns the patch keys of the lists in the patch by their paths with any index
+func patchKeys(patch string) (map[string][]string, error) {
+	pks, err := sets.PatchKeys(patch)
+	if err != nil {
+		return nil, err
+	}
+	keys := map[string][]string{}
+	for _, pk := range pks {
+		path := ""
+		for _, label := range pk.Path {
+			if _, err := strconv.Atoi(label); err == nil {
+				path += "[*]"
+				continue
+			}
+			path = fieldPath(path, label)
+		}
+		keys[path] = pk.Keys
+	}
+	return keys, nil
+}
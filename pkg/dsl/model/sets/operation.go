@@ -1,6 +1,7 @@
 package sets
 
 import (
+	"strconv"
 	"strings"
 
 	"cuelang.org/go/cue"
@@ -10,8 +11,22 @@ import (
 )
 
 const (
-	// TagPatchKey specify the primary key of the list items
+	// TagPatchKey specify the primary key of the list items, the items are merged by multiple keys if they're
+	// separated by commas, e.g. `+patchKey=name,protocol`
 	TagPatchKey = "patchKey"
+	// TagPatchStrategy specify how the field patches the base, it only applies to the tagged field
+	TagPatchStrategy = "patchStrategy"
+
+	// StrategyReplace replaces the value of the field in the base with the patch
+	StrategyReplace = "replace"
+	// StrategyRetainKeys removes the fields of the struct in the base that are not in the patch, the fields of the
+	// list items are retained in the same way if the items are merged by the patch key
+	StrategyRetainKeys = "retainKeys"
+
+	// DirectivePatch is the field of a struct in the patch that tells how to patch the struct
+	DirectivePatch = "$patch"
+	// DirectiveDelete deletes the struct field in the base, or the list item with the same patch key
+	DirectiveDelete = "delete"
 )
 
 var (
@@ -22,15 +37,18 @@ type interceptor func(node ast.Node) (ast.Node, error)
 
 func listMergeByKey(baseNode ast.Node) interceptor {
 	return func(lnode ast.Node) (ast.Node, error) {
+		var walkErr error
 		walker := newWalker(func(node ast.Node, ctx walkCtx) {
 			clist, ok := node.(*ast.ListLit)
-			if !ok {
+			if !ok || walkErr != nil {
 				return
 			}
-			key, ok := ctx.Tags()[TagPatchKey]
-			if !ok {
+			tags := ctx.Tags()
+			key, ok := tags[TagPatchKey]
+			if !ok || tags[TagPatchStrategy] == StrategyReplace {
 				return
 			}
+			keys := strings.Split(key, ",")
 			baseNode, err := lookUp(baseNode, ctx.Pos()...)
 			if err != nil {
 				return
@@ -41,39 +59,51 @@ func listMergeByKey(baseNode ast.Node) interceptor {
 			}
 
 			kmaps := map[string]ast.Expr{}
+			deleted := map[string]bool{}
 			nElts := []ast.Expr{}
 
 			for i, elt := range clist.Elts {
 				if _, ok := elt.(*ast.Ellipsis); ok {
 					continue
 				}
-				nodev, err := lookUp(elt, key)
-				if err != nil {
+				kv, ok := keyValue(elt, keys)
+				if !ok {
 					return
 				}
-				blit, ok := nodev.(*ast.BasicLit)
-				if !ok {
+				isDeleted, err := deleteDirective(elt)
+				if err != nil {
+					walkErr = err
 					return
 				}
-				kmaps[blit.Value] = clist.Elts[i]
+				if isDeleted {
+					deleted[kv] = true
+					continue
+				}
+				kmaps[kv] = clist.Elts[i]
 			}
+
+			// the base is changed only if all the items have the keys
+			baseElts := []ast.Expr{}
+			var merged [][2]ast.Expr
 			for _, elt := range baselist.Elts {
 				if _, ok := elt.(*ast.Ellipsis); ok {
+					baseElts = append(baseElts, elt)
 					continue
 				}
 
-				nodev, err := lookUp(elt, key)
-				if err != nil {
-					return
-				}
-				blit, ok := nodev.(*ast.BasicLit)
+				kv, ok := keyValue(elt, keys)
 				if !ok {
 					return
 				}
+				if deleted[kv] {
+					continue
+				}
+				baseElts = append(baseElts, elt)
 
-				if v, ok := kmaps[blit.Value]; ok {
+				if v, ok := kmaps[kv]; ok {
 					nElts = append(nElts, v)
-					delete(kmaps, blit.Value)
+					delete(kmaps, kv)
+					merged = append(merged, [2]ast.Expr{elt, v})
 				} else {
 					nElts = append(nElts, ast.NewStruct())
 				}
@@ -91,10 +121,192 @@ func listMergeByKey(baseNode ast.Node) interceptor {
 
 			nElts = append(nElts, &ast.Ellipsis{})
 			clist.Elts = nElts
+			baselist.Elts = baseElts
+			if tags[TagPatchStrategy] == StrategyRetainKeys {
+				for _, pair := range merged {
+					retainKeys(pair[0], pair[1])
+				}
+			}
 		})
 		walker.walk(lnode)
-		return lnode, nil
+		return lnode, walkErr
+	}
+}
+
+// keyValue joins the values of the keys of the list item, it returns false if the item doesn't have any of the keys
+func keyValue(elt ast.Node, keys []string) (string, bool) {
+	values := make([]string, 0, len(keys))
+	for _, key := range keys {
+		nodev, err := lookUp(elt, strings.TrimSpace(key))
+		if err != nil {
+			return "", false
+		}
+		blit, ok := nodev.(*ast.BasicLit)
+		if !ok {
+			return "", false
+		}
+		values = append(values, blit.Value)
 	}
+	return strings.Join(values, ","), true
+}
+
+// fieldReplace replaces the fields in the base with the fields tagged by `+patchStrategy=replace`, it runs before
+// the other interceptors so that the replaced fields are not merged
+func fieldReplace(baseNode ast.Node) interceptor {
+	return func(pnode ast.Node) (ast.Node, error) {
+		walker := newWalker(func(node ast.Node, ctx walkCtx) {
+			field, ok := node.(*ast.Field)
+			if !ok || findCommentTag(field.Comments())[TagPatchStrategy] != StrategyReplace {
+				return
+			}
+			baseField := lookUpField(baseNode, append(copyPath(ctx.Pos()), labelStr(field.Label))...)
+			if baseField == nil {
+				return
+			}
+			baseField.Value = ast.NewIdent("_")
+			// the list is kept open so that the later patches can still append items
+			if list, ok := field.Value.(*ast.ListLit); ok && !hasEllipsis(list) {
+				list.Elts = append(list.Elts, &ast.Ellipsis{})
+			}
+		})
+		walker.walk(pnode)
+		return pnode, nil
+	}
+}
+
+// fieldPatch deletes the struct fields with the delete directive, and retains the keys of the structs tagged by
+// `+patchStrategy=retainKeys`
+func fieldPatch(baseNode ast.Node) interceptor {
+	return func(pnode ast.Node) (ast.Node, error) {
+		var walkErr error
+		walker := newWalker(func(node ast.Node, ctx walkCtx) {
+			decls := declList(node)
+			if decls == nil || walkErr != nil {
+				return
+			}
+			kept := []ast.Decl{}
+			for _, decl := range *decls {
+				field, ok := decl.(*ast.Field)
+				if !ok {
+					kept = append(kept, decl)
+					continue
+				}
+				if labelStr(field.Label) == DirectivePatch {
+					walkErr = errors.Errorf("%s of %s is only supported in a struct field or in a list item merged by %s",
+						DirectivePatch, strings.Join(ctx.Pos(), "."), TagPatchKey)
+					return
+				}
+				path := append(copyPath(ctx.Pos()), labelStr(field.Label))
+				isDeleted, err := deleteDirective(field.Value)
+				if err != nil {
+					walkErr = err
+					return
+				}
+				if isDeleted {
+					deleteField(baseNode, path...)
+					continue
+				}
+				kept = append(kept, decl)
+				if findCommentTag(field.Comments())[TagPatchStrategy] == StrategyRetainKeys {
+					if baseValue, err := lookUp(baseNode, path...); err == nil {
+						retainKeys(baseValue, field.Value)
+					}
+				}
+			}
+			*decls = kept
+		})
+		walker.walk(pnode)
+		return pnode, walkErr
+	}
+}
+
+// deleteDirective checks whether the struct has the delete directive, i.e. `$patch: "delete"`
+func deleteDirective(node ast.Node) (bool, error) {
+	decls := declList(node)
+	if decls == nil {
+		return false, nil
+	}
+	for _, decl := range *decls {
+		field, ok := decl.(*ast.Field)
+		if !ok || labelStr(field.Label) != DirectivePatch {
+			continue
+		}
+		if blit, ok := field.Value.(*ast.BasicLit); ok {
+			if v, err := strconv.Unquote(blit.Value); err == nil && v == DirectiveDelete {
+				return true, nil
+			}
+		}
+		return false, errors.Errorf("unsupported patch directive %s", formatNode(field))
+	}
+	return false, nil
+}
+
+// retainKeys removes the fields of the base struct that are not in the patch struct
+func retainKeys(base, patch ast.Node) {
+	baseDecls, patchDecls := declList(base), declList(patch)
+	if baseDecls == nil || patchDecls == nil {
+		return
+	}
+	labels := map[string]bool{}
+	for _, decl := range *patchDecls {
+		if field, ok := decl.(*ast.Field); ok {
+			labels[labelStr(field.Label)] = true
+		}
+	}
+	kept := []ast.Decl{}
+	for _, decl := range *baseDecls {
+		if field, ok := decl.(*ast.Field); ok && !labels[labelStr(field.Label)] {
+			continue
+		}
+		kept = append(kept, decl)
+	}
+	*baseDecls = kept
+}
+
+func hasEllipsis(list *ast.ListLit) bool {
+	for _, elt := range list.Elts {
+		if _, ok := elt.(*ast.Ellipsis); ok {
+			return true
+		}
+	}
+	return false
+}
+
+func copyPath(path []string) []string {
+	return append([]string{}, path...)
+}
+
+// PatchKey is the patch key of a list in the patch
+type PatchKey struct {
+	// Path is the path of the list, the indexes of the lists on the path are like `0`
+	Path []string
+	Keys []string
+}
+
+// PatchKeys returns the patch keys of the lists in the patch that are merged by the keys
+func PatchKeys(patch string) ([]PatchKey, error) {
+	patchFile, err := parser.ParseFile("-", patch, parser.ParseComments)
+	if err != nil {
+		return nil, errors.WithMessage(err, "invalid patch cue file")
+	}
+	var keys []PatchKey
+	walker := newWalker(func(node ast.Node, ctx walkCtx) {
+		if _, ok := node.(*ast.ListLit); !ok {
+			return
+		}
+		tags := ctx.Tags()
+		key, ok := tags[TagPatchKey]
+		if !ok || tags[TagPatchStrategy] == StrategyReplace {
+			return
+		}
+		names := strings.Split(key, ",")
+		for i := range names {
+			names[i] = strings.TrimSpace(names[i])
+		}
+		keys = append(keys, PatchKey{Path: copyPath(ctx.Pos()), Keys: names})
+	})
+	walker.walk(patchFile)
+	return keys, nil
 }
 
 // StrategyUnify unify the objects by the strategy
@@ -108,7 +320,7 @@ func StrategyUnify(base, patch string) (string, error) {
 		return "", errors.WithMessage(err, "invalid patch cue file")
 	}
 
-	return strategyUnify(baseFile, patchFile, listMergeByKey(baseFile))
+	return strategyUnify(baseFile, patchFile, fieldReplace(baseFile), listMergeByKey(baseFile), fieldPatch(baseFile))
 }
 
 func strategyUnify(baseFile *ast.File, patchFile *ast.File, patchOpts ...interceptor) (string, error) {
@@ -138,6 +138,151 @@ containers: [{
 		value: "DEV"
 	}, ...]
 }, ...]
+`,
+		},
+
+		// a sidecar is appended to the containers
+		{
+			base: `spec: template: spec: containers: [{name: "main", image: "nginx"}, ...]`,
+			patch: `
+// +patchKey=name
+spec: template: spec: containers: [{name: "log", image: "fluentd"}]`,
+			result: `spec: {
+	template: {
+		spec: {
+			// +patchKey=name
+			containers: [{
+				name:  "main"
+				image: "nginx"
+			}, {
+				name:  "log"
+				image: "fluentd"
+			}, ...]
+		}
+	}
+}
+`,
+		},
+
+		// the env is replaced as a whole
+		{
+			base: `containers: [{name: "main", env: [{name: "A", value: "1"}, {name: "B", value: "2"}, ...]}, ...]`,
+			patch: `
+// +patchKey=name
+containers: [{name: "main",
+	// +patchStrategy=replace
+	env: [{name: "C", value: "3"}]}]`,
+			result: `// +patchKey=name
+containers: [{
+	name: "main"
+	// +patchStrategy=replace
+	env: [{
+		name:  "C"
+		value: "3"
+	}, ...]
+}, ...]
+`,
+		},
+
+		// an env is deleted and another is added
+		{
+			base: `containers: [{name: "main", env: [{name: "A", value: "1"}, {name: "B", value: "2"}, ...]}, ...]`,
+			patch: `
+// +patchKey=name
+containers: [{name: "main", env: [{name: "A", $patch: "delete"}, {name: "C", value: "3"}]}]`,
+			result: `// +patchKey=name
+containers: [{
+	name: "main"
+	env: [{
+		name:  "B"
+		value: "2"
+	}, {
+		name:  "C"
+		value: "3"
+	}, ...]
+}, ...]
+`,
+		},
+
+		// the ports are merged by the name and the protocol, and a port is dropped
+		{
+			base: `ports: [{name: "dns", protocol: "TCP", port: 53}, {name: "dns", protocol: "UDP", port: 53}, ...]`,
+			patch: `
+// +patchKey=name,protocol
+ports: [{name: "dns", protocol: "UDP", hostPort: 5353}, {name: "dns", protocol: "TCP", $patch: "delete"},
+	{name: "http", protocol: "TCP", port: 80}]`,
+			result: `// +patchKey=name,protocol
+ports: [{
+	name:     "dns"
+	protocol: "UDP"
+	port:     53
+	hostPort: 5353
+}, {
+	name:     "http"
+	protocol: "TCP"
+	port:     80
+}, ...]
+`,
+		},
+
+		// the source of a volume is switched from emptyDir to hostPath
+		{
+			base: `volumes: [{name: "data", emptyDir: {}}, {name: "cache", emptyDir: {}}, ...]`,
+			patch: `
+// +patchKey=name
+// +patchStrategy=retainKeys
+volumes: [{name: "data", hostPath: path: "/data"}]`,
+			result: `// +patchKey=name
+// +patchStrategy=retainKeys
+volumes: [{
+	name: "data"
+	hostPath: {
+		path: "/data"
+	}
+}, {
+	name: "cache"
+	emptyDir: {}
+}, ...]
+`,
+		},
+
+		// the keys of a struct are retained, and a struct field is deleted
+		{
+			base: `metadata: {labels: {a: "1"}, annotations: {x: "1"}}
+volume: {name: "data", emptyDir: {}}`,
+			patch: `
+metadata: annotations: {$patch: "delete"}
+// +patchStrategy=retainKeys
+volume: {name: "data", hostPath: path: "/data"}`,
+			result: `metadata: {
+	labels: {
+		a: "1"
+	}
+}
+// +patchStrategy=retainKeys
+volume: {
+	name: "data"
+	hostPath: {
+		path: "/data"
+	}
+}
+`,
+		},
+
+		// the strategy doesn't apply to the nested fields
+		{
+			base: `volume: {name: "data", hostPath: {path: "/data", type: "Directory"}}`,
+			patch: `
+// +patchStrategy=retainKeys
+volume: {name: "data", hostPath: path: "/data"}`,
+			result: `// +patchStrategy=retainKeys
+volume: {
+	name: "data"
+	hostPath: {
+		path: "/data"
+		type: "Directory"
+	}
+}
 `,
 		},
 	}
@@ -148,6 +293,29 @@ containers: [{
 	}
 }
 
+func TestPatchDirectiveErrors(t *testing.T) {
+	testCases := []struct {
+		base  string
+		patch string
+		err   string
+	}{
+		{
+			base:  `args: ["a", ...]`,
+			patch: `args: [{$patch: "delete"}]`,
+			err:   "process patchOption: $patch of args.0 is only supported in a struct field or in a list item merged by patchKey",
+		},
+		{
+			base:  `a: {b: 1}`,
+			patch: `a: {$patch: "replace"}`,
+			err:   `process patchOption: unsupported patch directive $patch: "replace"`,
+		},
+	}
+	for i, tcase := range testCases {
+		_, err := StrategyUnify(tcase.base, tcase.patch)
+		assert.Equal(t, tcase.err, fmt.Sprint(err), fmt.Sprintf("testPatchDirectiveErrors for case(no:%d)", i))
+	}
+}
+
 func TestParseCommentTags(t *testing.T) {
 	temp := `
 // +patchKey=name
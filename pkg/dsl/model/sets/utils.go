@@ -63,6 +63,63 @@ func lookField(node ast.Node, key string) ast.Node {
 	return nil
 }
 
+// declList returns the declarations of the file or the struct, it returns nil for the other nodes
+func declList(node ast.Node) *[]ast.Decl {
+	switch x := node.(type) {
+	case *ast.File:
+		return &x.Decls
+	case *ast.StructLit:
+		return &x.Elts
+	}
+	return nil
+}
+
+// lookUpField returns the field at the path, it returns nil if there is no such field
+func lookUpField(node ast.Node, paths ...string) *ast.Field {
+	if len(paths) == 0 {
+		return nil
+	}
+	parent, err := lookUp(node, paths[:len(paths)-1]...)
+	if err != nil {
+		return nil
+	}
+	decls := declList(parent)
+	if decls == nil {
+		return nil
+	}
+	for _, decl := range *decls {
+		if field, ok := decl.(*ast.Field); ok && labelStr(field.Label) == paths[len(paths)-1] {
+			return field
+		}
+	}
+	return nil
+}
+
+// deleteField deletes the field at the path if it exists
+func deleteField(node ast.Node, paths ...string) {
+	field := lookUpField(node, paths...)
+	if field == nil {
+		return
+	}
+	parent, _ := lookUp(node, paths[:len(paths)-1]...)
+	decls := declList(parent)
+	kept := []ast.Decl{}
+	for _, decl := range *decls {
+		if decl != field {
+			kept = append(kept, decl)
+		}
+	}
+	*decls = kept
+}
+
+func formatNode(node ast.Node) string {
+	b, err := format.Node(node)
+	if err != nil {
+		return fmt.Sprintf("%T", node)
+	}
+	return string(b)
+}
+
 func labelStr(label ast.Label) string {
 	switch v := label.(type) {
 	case *ast.Ident:
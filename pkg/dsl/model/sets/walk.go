@@ -43,8 +43,14 @@ func (nwk *nodewalker) walk(node ast.Node) {
 			origin := nwk.pos
 			oriTags := nwk.tags
 			nwk.pos = append(nwk.pos, labelStr(n.Label))
-			tags := findCommentTag(n.Comments())
-			for tk, tv := range tags {
+			// the tags are inherited by the nested fields, except the strategy that only applies to the tagged field
+			nwk.tags = map[string]string{}
+			for tk, tv := range oriTags {
+				if tk != TagPatchStrategy {
+					nwk.tags[tk] = tv
+				}
+			}
+			for tk, tv := range findCommentTag(n.Comments()) {
 				nwk.tags[tk] = tv
 			}
 
@@ -3,9 +3,12 @@ package process
 import (
 	"encoding/json"
 	"fmt"
+	"sort"
 	"strings"
 	"unicode"
 
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
 	"github.com/oam-dev/kubevela/pkg/dsl/model"
 )
 
@@ -14,6 +17,11 @@ type Context interface {
 	SetBase(base model.Instance)
 	PutAssistants(insts ...Assistant)
 	SetConfigs(configs []map[string]string)
+	SetCluster(reader client.Reader, namespace string)
+	SetApplication(app Application)
+	SetExplain(explain bool)
+	Explain() bool
+	Cluster() (client.Reader, string)
 	Output() (model.Instance, []Assistant)
 	Compile(label string) string
 }
@@ -22,6 +30,17 @@ type Context interface {
 type Assistant struct {
 	Ins  model.Instance
 	Type string
+	// Name is the key of the object in the `outputs` of the template, it's empty for the `output` of a trait
+	Name string
+}
+
+// Application is the information of the application and the component that a template is rendered for
+type Application struct {
+	Name          string
+	Revision      string
+	ComponentType string
+	Labels        map[string]string
+	Annotations   map[string]string
 }
 
 type context struct {
@@ -29,6 +48,10 @@ type context struct {
 	configs    []map[string]string
 	base       model.Instance
 	assistants []Assistant
+	reader     client.Reader
+	namespace  string
+	app        Application
+	explain    bool
 }
 
 // NewContext create render context
@@ -50,6 +73,33 @@ func (ctx *context) SetBase(base model.Instance) {
 	ctx.base = base
 }
 
+// SetCluster sets the client that the processing tasks of the templates read the cluster state with, and the
+// namespace of the objects they read by default
+func (ctx *context) SetCluster(reader client.Reader, namespace string) {
+	ctx.reader = reader
+	ctx.namespace = namespace
+}
+
+// SetApplication sets the information of the application and the component
+func (ctx *context) SetApplication(app Application) {
+	ctx.app = app
+}
+
+// SetExplain sets whether the templates record the sources of the fields they render
+func (ctx *context) SetExplain(explain bool) {
+	ctx.explain = explain
+}
+
+// Explain checks whether the templates record the sources of the fields they render
+func (ctx *context) Explain() bool {
+	return ctx.explain
+}
+
+// Cluster returns the client and namespace for the processing tasks, the client is nil if the cluster isn't available
+func (ctx *context) Cluster() (client.Reader, string) {
+	return ctx.reader, ctx.namespace
+}
+
 // PutAssistants add Assist model to context
 func (ctx *context) PutAssistants(insts ...Assistant) {
 	ctx.assistants = append(ctx.assistants, insts...)
@@ -59,6 +109,12 @@ func (ctx *context) PutAssistants(insts ...Assistant) {
 func (ctx *context) Compile(label string) string {
 	var buff string
 	buff += fmt.Sprintf("name: \"%s\"\n", ctx.name)
+	buff += fmt.Sprintf("appName: %s\n", jsonMarshal(ctx.app.Name))
+	buff += fmt.Sprintf("namespace: %s\n", jsonMarshal(ctx.namespace))
+	buff += fmt.Sprintf("appRevision: %s\n", jsonMarshal(ctx.app.Revision))
+	buff += fmt.Sprintf("componentType: %s\n", jsonMarshal(ctx.app.ComponentType))
+	buff += fmt.Sprintf("appLabels: %s\n", jsonMarshal(nonNil(ctx.app.Labels)))
+	buff += fmt.Sprintf("appAnnotations: %s\n", jsonMarshal(nonNil(ctx.app.Annotations)))
 
 	if ctx.base != nil {
 		buff += fmt.Sprintf("input: %s\n", structMarshal(ctx.base.String()))
@@ -66,7 +122,24 @@ func (ctx *context) Compile(label string) string {
 
 	if len(ctx.configs) > 0 {
 		bt, _ := json.Marshal(ctx.configs)
-		buff += "config: " + string(bt)
+		buff += "config: " + string(bt) + "\n"
+	}
+
+	// the objects rendered before the template, the `output`s of the traits are keyed by the trait type and the
+	// `outputs` of the workload and traits are keyed by their names, a later one overrides the same key
+	traits, outputs := map[string]string{}, map[string]string{}
+	for _, assist := range ctx.assistants {
+		if assist.Name == "" {
+			traits[assist.Type] = structMarshal(assist.Ins.String())
+		} else {
+			outputs[assist.Name] = structMarshal(assist.Ins.String())
+		}
+	}
+	if len(traits) > 0 {
+		buff += fmt.Sprintf("traits: %s\n", fieldsMarshal(traits))
+	}
+	if len(outputs) > 0 {
+		buff += fmt.Sprintf("outputs: %s\n", fieldsMarshal(outputs))
 	}
 
 	if label != "" {
@@ -99,3 +172,29 @@ func structMarshal(v string) string {
 	}
 	return fmt.Sprintf("{%s}", v)
 }
+
+func jsonMarshal(v interface{}) string {
+	bt, _ := json.Marshal(v)
+	return string(bt)
+}
+
+func nonNil(m map[string]string) map[string]string {
+	if m == nil {
+		return map[string]string{}
+	}
+	return m
+}
+
+// fieldsMarshal returns the cue struct of the fields sorted by their names
+func fieldsMarshal(fields map[string]string) string {
+	names := make([]string, 0, len(fields))
+	for name := range fields {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	var buff string
+	for _, name := range names {
+		buff += fmt.Sprintf("%s: %s\n", jsonMarshal(name), fields[name])
+	}
+	return fmt.Sprintf("{\n%s}", buff)
+}
@@ -41,3 +41,45 @@ image: "myserver"
 	assert.Equal(t, nil, err)
 	assert.Equal(t, `{"image":"myserver"}`, string(inputJs))
 }
+
+func TestContextApplication(t *testing.T) {
+	var r cue.Runtime
+	newInstance := func(src string) model.Instance {
+		inst, err := r.Compile("-", src)
+		assert.Equal(t, nil, err)
+		ins, err := model.NewOther(inst.Value())
+		assert.Equal(t, nil, err)
+		return ins
+	}
+
+	ctx := NewContext("myweb")
+	ctx.SetCluster(nil, "prod")
+	ctx.SetApplication(Application{
+		Name:          "myapp",
+		Revision:      "myapp-v2",
+		ComponentType: "webservice",
+		Labels:        map[string]string{"team": "vela"},
+	})
+	ctx.PutAssistants(Assistant{Ins: newInstance(`kind: "Service"`), Type: "AuxiliaryWorkload", Name: "service"},
+		Assistant{Ins: newInstance(`kind: "Route"`), Type: "route"})
+	ctxInst, err := r.Compile("-", ctx.Compile("context"))
+	if err != nil {
+		t.Error(err)
+		return
+	}
+
+	for field, want := range map[string]string{
+		"appName":        `"myapp"`,
+		"namespace":      `"prod"`,
+		"appRevision":    `"myapp-v2"`,
+		"componentType":  `"webservice"`,
+		"appLabels":      `{"team":"vela"}`,
+		"appAnnotations": `{}`,
+		"traits":         `{"route":{"kind":"Route"}}`,
+		"outputs":        `{"service":{"kind":"Service"}}`,
+	} {
+		js, err := ctxInst.Lookup("context", field).MarshalJSON()
+		assert.Equal(t, nil, err)
+		assert.Equal(t, want, string(js))
+	}
+}
@@ -1,39 +1,138 @@
 package task
 
 import (
+	"context"
 	"encoding/json"
 	"errors"
 	"fmt"
 
 	"cuelang.org/go/cue"
+	"sigs.k8s.io/controller-runtime/pkg/client"
 
 	"github.com/oam-dev/kubevela/pkg/builtin"
 	"github.com/oam-dev/kubevela/pkg/builtin/registry"
 )
 
-// Process processing the http task
-func Process(inst *cue.Instance) (*cue.Instance, error) {
-	taskVal := inst.Lookup("processing", "http")
-	if !taskVal.Exists() {
-		return inst, errors.New("there is no http in processing")
+// Process runs the tasks in `processing` of the template and fills their outputs into it.
+//
+// The tasks are the `processing.steps` which run in sequence, a step is a struct with an optional `name` and one
+// field keyed by the task type such as `http`, `kube`, `secret` or `configmap`. The output of a named step is filled
+// into `processing.outputs.<name>` so that the later steps and the template can refer to it, and the output of the
+// last step is filled into `processing.output`. A `processing.http` without steps is a single http step.
+//
+// The client reads the cluster state for the tasks such as `kube`, it can be nil if the cluster isn't available.
+func Process(inst *cue.Instance, c client.Reader, namespace string) (*cue.Instance, error) {
+	meta := func(v cue.Value) *registry.Meta {
+		return &registry.Meta{Context: context.Background(), Obj: v, Client: c, Namespace: namespace}
 	}
-	resp, err := exec(taskVal)
-	if err != nil {
-		return nil, fmt.Errorf("fail to exec http task, %w", err)
+	if !inst.Lookup("processing", "steps").Exists() {
+		taskVal := inst.Lookup("processing", "http")
+		if !taskVal.Exists() {
+			return inst, errors.New("there is no http or steps in processing")
+		}
+		resp, err := exec("http", taskVal, meta(taskVal))
+		if err != nil {
+			return nil, fmt.Errorf("fail to exec http task, %w", err)
+		}
+		appInst, err := inst.Fill(resp, "processing", "output")
+		if err != nil {
+			return nil, fmt.Errorf("fail to fill output from http, %w", err)
+		}
+		return appInst, nil
 	}
 
-	appInst, err := inst.Fill(resp, "processing", "output")
+	var last interface{}
+	for i := 0; ; i++ {
+		// the step is looked up in the latest instance as it may refer to the outputs of the former steps
+		step, ok, err := lookupStep(inst, i)
+		if err != nil {
+			return nil, err
+		}
+		if !ok {
+			break
+		}
+		name, key, err := parseStep(step)
+		if err != nil {
+			return nil, fmt.Errorf("invalid step %d of processing, %w", i, err)
+		}
+		taskVal := step.Lookup(key)
+		resp, err := exec(key, taskVal, meta(taskVal))
+		if err != nil {
+			return nil, fmt.Errorf("fail to exec %s task of step %s, %w", key, stepName(name, i), err)
+		}
+		if name != "" {
+			if inst, err = inst.Fill(resp, "processing", "outputs", name); err != nil {
+				return nil, fmt.Errorf("fail to fill output from step %s, %w", name, err)
+			}
+		}
+		last = resp
+	}
+	if last == nil {
+		return inst, nil
+	}
+	appInst, err := inst.Fill(last, "processing", "output")
 	if err != nil {
-		return nil, fmt.Errorf("fail to fill output from http, %w", err)
+		return nil, fmt.Errorf("fail to fill output from the last step, %w", err)
 	}
 	return appInst, nil
 }
 
-func exec(v cue.Value) (map[string]interface{}, error) {
-	got, err := builtin.RunTaskByKey("http", cue.Value{}, &registry.Meta{Obj: v})
+// lookupStep returns the i-th step of the processing, it returns false if there are no more steps
+func lookupStep(inst *cue.Instance, i int) (cue.Value, bool, error) {
+	iter, err := inst.Lookup("processing", "steps").List()
+	if err != nil {
+		return cue.Value{}, false, fmt.Errorf("invalid steps of processing, %w", err)
+	}
+	for j := 0; iter.Next(); j++ {
+		if j == i {
+			return iter.Value(), true, nil
+		}
+	}
+	return cue.Value{}, false, nil
+}
+
+// parseStep returns the name and the task type of a step
+func parseStep(step cue.Value) (string, string, error) {
+	var name string
+	if v := step.Lookup("name"); v.Exists() {
+		s, err := v.String()
+		if err != nil {
+			return "", "", fmt.Errorf("invalid name, %w", err)
+		}
+		name = s
+	}
+	iter, err := step.Fields()
+	if err != nil {
+		return "", "", err
+	}
+	var keys []string
+	for iter.Next() {
+		if iter.Label() != "name" {
+			keys = append(keys, iter.Label())
+		}
+	}
+	if len(keys) != 1 {
+		return "", "", fmt.Errorf("a step should have exactly one task but got %v", keys)
+	}
+	return name, keys[0], nil
+}
+
+func stepName(name string, i int) string {
+	if name == "" {
+		return fmt.Sprint(i)
+	}
+	return name
+}
+
+// exec runs the task, the JSON body of the response is the output of an http task
+func exec(key string, v cue.Value, meta *registry.Meta) (interface{}, error) {
+	got, err := builtin.RunTaskByKey(key, v, meta)
 	if err != nil {
 		return nil, err
 	}
+	if key != "http" {
+		return got, nil
+	}
 	gotMap, ok := got.(map[string]interface{})
 	if !ok {
 		return nil, fmt.Errorf("fail to convert got to map")
@@ -42,7 +141,7 @@ func exec(v cue.Value) (map[string]interface{}, error) {
 	if !ok {
 		return nil, fmt.Errorf("fail to convert body to string")
 	}
-	resp := make(map[string]interface{})
+	var resp interface{}
 	if err := json.Unmarshal([]byte(body), &resp); err != nil {
 		return nil, err
 	}
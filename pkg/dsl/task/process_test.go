@@ -11,6 +11,11 @@ import (
 	"cuelang.org/go/cue"
 	cueJson "cuelang.org/go/pkg/encoding/json"
 	"github.com/bmizerany/assert"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	clientgoscheme "k8s.io/client-go/kubernetes/scheme"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
 )
 
 const TaskTemplate = `
@@ -55,7 +60,7 @@ func TestProcess(t *testing.T) {
 		"serviceURL": "http://127.0.0.1:8090/api/v1/token?val=test-token",
 	}, "parameter")
 
-	inst, err := Process(taskTemplate)
+	inst, err := Process(taskTemplate, nil, "")
 	if err != nil {
 		t.Fatal(err)
 	}
@@ -64,6 +69,58 @@ func TestProcess(t *testing.T) {
 	assert.Equal(t, "{\"data\":\"test-token\"}", data)
 }
 
+const StepsTemplate = `
+processing: {
+  steps: [{
+    name: "service"
+    configmap: {name: "ingress-config", key: "service"}
+  }, {
+    name: "lb"
+    kube: {apiVersion: "v1", kind: "Service", namespace: "vela-system", name: processing.outputs.service}
+  }, {
+    http: url: "http://127.0.0.1:8090/api/v1/token?val=\(processing.outputs.lb.status.loadBalancer.ingress[0].ip)"
+    http: method: "GET"
+  }]
+}
+
+output: {
+  data: {
+    ip: processing.outputs.lb.status.loadBalancer.ingress[0].ip
+    token: processing.output.token
+  }
+}
+`
+
+func TestProcessSteps(t *testing.T) {
+	s := NewMock()
+	defer s.Close()
+
+	scheme := runtime.NewScheme()
+	_ = clientgoscheme.AddToScheme(scheme)
+	c := fake.NewFakeClientWithScheme(scheme,
+		&corev1.ConfigMap{ObjectMeta: metav1.ObjectMeta{Name: "ingress-config", Namespace: "default"},
+			Data: map[string]string{"service": "ingress"}},
+		&corev1.Service{ObjectMeta: metav1.ObjectMeta{Name: "ingress", Namespace: "vela-system"},
+			Status: corev1.ServiceStatus{LoadBalancer: corev1.LoadBalancerStatus{
+				Ingress: []corev1.LoadBalancerIngress{{IP: "10.0.0.1"}}}}})
+
+	r := cue.Runtime{}
+	taskTemplate, err := r.Compile("", StepsTemplate)
+	if err != nil {
+		t.Fatal(err)
+	}
+	inst, err := Process(taskTemplate, c, "default")
+	if err != nil {
+		t.Fatal(err)
+	}
+	data, _ := cueJson.Marshal(inst.Lookup("output"))
+	assert.Equal(t, `{"data":{"ip":"10.0.0.1","token":"10.0.0.1"}}`, data)
+
+	_, err = Process(taskTemplate, nil, "default")
+	assert.Equal(t, "fail to exec configmap task of step service, "+
+		"can't get ConfigMap ingress-config without the access to the cluster", err.Error())
+}
+
 func NewMock() *httptest.Server {
 	ts := httptest.NewUnstartedServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
 		if r.Method != "GET" {
@@ -25,6 +25,8 @@ const (
 	LabelAppComponent = "app.oam.dev/component"
 	// LabelAppComponentRevision records the revision name of Component
 	LabelAppComponentRevision = "app.oam.dev/revision"
+	// LabelAppRevisionHash records the hash of the ApplicationRevision
+	LabelAppRevisionHash = "app.oam.dev/revision-hash"
 	// LabelOAMResourceType whether a CR is workload or trait
 	LabelOAMResourceType = "app.oam.dev/resourceType"
 
@@ -32,6 +34,9 @@ const (
 	WorkloadTypeLabel = "workload.oam.dev/type"
 	// TraitTypeLabel indicates the type of the traitDefinition
 	TraitTypeLabel = "trait.oam.dev/type"
+	// TraitResourceLabel records the key of an object in the `outputs` of a trait template, it tells the object
+	// apart from the `output` of the trait
+	TraitResourceLabel = "trait.oam.dev/resource"
 )
 
 const (
@@ -48,4 +53,20 @@ const (
 	// AnnotationLastAppliedConfig records the previous configuration of a
 	// resource for use in a three way diff during a patching apply
 	AnnotationLastAppliedConfig = "app.oam.dev/last-applied-configuration"
+
+	// AnnotationOrphanResources keeps the resources rendered by an Application
+	// when the Application is deleted if it's set to "true"
+	AnnotationOrphanResources = "app.oam.dev/orphan-resources"
+
+	// AnnotationRollbackRevision records the ApplicationRevision an Application is rolled back to,
+	// the Application is rendered with the definition templates in the revision
+	AnnotationRollbackRevision = "app.oam.dev/rollback-revision"
+
+	// AnnotationDryRun makes an Application rendered without being applied if it's set to "true",
+	// the rendered resources are saved in a ConfigMap for review
+	AnnotationDryRun = "app.oam.dev/dry-run"
+
+	// AnnotationRolloutReplicas marks a workload under a rollout with its replicas before the rollout, the
+	// ApplicationConfiguration keeps the replicas of the marked workload as they're scaled by the rollout
+	AnnotationRolloutReplicas = "app.oam.dev/rollout-replicas"
 )
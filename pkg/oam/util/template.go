@@ -1,12 +1,16 @@
 package util
 
 import (
+	"container/list"
 	"context"
 	"encoding/json"
 	"fmt"
+	"sync"
 
 	"github.com/pkg/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/runtime/schema"
+	ktypes "k8s.io/apimachinery/pkg/types"
 	"sigs.k8s.io/controller-runtime/pkg/client"
 
 	"github.com/oam-dev/kubevela/apis/core.oam.dev/v1alpha2"
@@ -51,39 +55,123 @@ func GetScopeGVK(cli client.Client, dm discoverymapper.DiscoveryMapper,
 
 // LoadTemplate Get template according to key
 func LoadTemplate(cli client.Client, key string, kd types.CapType) (string, string, error) {
+	def, raw, err := getDefinition(cli, key, kd)
+	if err != nil {
+		return "", "", err
+	}
+	return parseTemplate(def, raw)
+}
+
+// getDefinition gets the definition of the workload type or the trait, and the raw extension of it
+func getDefinition(cli client.Client, key string, kd types.CapType) (metav1.Object, []byte, error) {
 	switch kd {
 	case types.TypeWorkload:
 		wd, err := GetWorkloadDefinition(cli, key)
 		if err != nil {
-			return "", "", errors.WithMessagef(err, "LoadTemplate [%s] ", key)
+			return nil, nil, errors.WithMessagef(err, "LoadTemplate [%s] ", key)
 		}
-		tmpl, health, err := getTemplAndHealth(wd.Spec.Extension.Raw)
-		if err != nil {
-			return "", "", errors.WithMessagef(err, "LoadTemplate [%s] ", key)
-		}
-		if tmpl == "" {
-			return "", "", errors.New("no template found in definition")
-		}
-		return tmpl, health, nil
+		return wd, wd.Spec.Extension.Raw, nil
 
 	case types.TypeTrait:
 		td, err := GetTraitDefinition(cli, key)
 		if err != nil {
-			return "", "", errors.WithMessagef(err, "LoadTemplate [%s] ", key)
-		}
-		tmpl, health, err := getTemplAndHealth(td.Spec.Extension.Raw)
-		if err != nil {
-			return "", "", errors.WithMessagef(err, "LoadTemplate [%s] ", key)
+			return nil, nil, errors.WithMessagef(err, "LoadTemplate [%s] ", key)
 		}
-		if tmpl == "" {
-			return "", "", errors.New("no template found in definition")
-		}
-		return tmpl, health, nil
+		return td, td.Spec.Extension.Raw, nil
 	case types.TypeScope:
 		// TODO: add scope template support
 	}
 
-	return "", "", fmt.Errorf("kind(%s) of %s not supported", kd, key)
+	return nil, nil, fmt.Errorf("kind(%s) of %s not supported", kd, key)
+}
+
+func parseTemplate(def metav1.Object, raw []byte) (string, string, error) {
+	tmpl, health, err := getTemplAndHealth(raw)
+	if err != nil {
+		return "", "", errors.WithMessagef(err, "LoadTemplate [%s] ", def.GetName())
+	}
+	if tmpl == "" {
+		return "", "", errors.New("no template found in definition")
+	}
+	return tmpl, health, nil
+}
+
+// maxCachedTemplates bounds the number of the definitions whose templates are cached, the least recently used one is
+// evicted when the cache is full
+const maxCachedTemplates = 256
+
+// TemplateCache keeps the templates parsed from the definitions, a template is parsed again only if the generation
+// of the definition is changed, or the definition is recreated. The definitions are still read with the client,
+// which reads them from the informers in the controller and the webhook, so a cached template is as fresh as the
+// definition read by the client.
+type TemplateCache struct {
+	mu sync.Mutex
+	// size is the maximum number of the cached definitions
+	size      int
+	templates map[string]*list.Element
+	// recent orders the cached templates from the most recently used to the least
+	recent *list.List
+}
+
+type definitionTemplate struct {
+	key        string
+	uid        ktypes.UID
+	generation int64
+	template   string
+	health     string
+}
+
+// NewTemplateCache creates an empty template cache
+func NewTemplateCache() *TemplateCache {
+	return &TemplateCache{size: maxCachedTemplates, templates: map[string]*list.Element{}, recent: list.New()}
+}
+
+// LoadTemplate loads the template and the health policy of the definition in the same way as LoadTemplate, the
+// cached ones are returned if the definition isn't changed since they're parsed
+func (c *TemplateCache) LoadTemplate(cli client.Client, key string, kd types.CapType) (string, string, error) {
+	def, raw, err := getDefinition(cli, key, kd)
+	if err != nil {
+		return "", "", err
+	}
+	cacheKey := string(kd) + "/" + key
+	if cached, ok := c.get(cacheKey); ok && cached.uid == def.GetUID() && cached.generation == def.GetGeneration() {
+		return cached.template, cached.health, nil
+	}
+
+	tmpl, health, err := parseTemplate(def, raw)
+	if err != nil {
+		return "", "", err
+	}
+	c.put(definitionTemplate{key: cacheKey, uid: def.GetUID(), generation: def.GetGeneration(), template: tmpl,
+		health: health})
+	return tmpl, health, nil
+}
+
+func (c *TemplateCache) get(key string) (definitionTemplate, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	elem, ok := c.templates[key]
+	if !ok {
+		return definitionTemplate{}, false
+	}
+	c.recent.MoveToFront(elem)
+	return elem.Value.(definitionTemplate), true
+}
+
+func (c *TemplateCache) put(t definitionTemplate) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if elem, ok := c.templates[t.key]; ok {
+		elem.Value = t
+		c.recent.MoveToFront(elem)
+		return
+	}
+	c.templates[t.key] = c.recent.PushFront(t)
+	for c.recent.Len() > c.size {
+		oldest := c.recent.Back()
+		c.recent.Remove(oldest)
+		delete(c.templates, oldest.Value.(definitionTemplate).key)
+	}
 }
 
 func getTemplAndHealth(raw []byte) (string, string, error) {
@@ -109,3 +109,74 @@ spec:
 		t.Errorf("parsered template is not correct")
 	}
 }
+
+func TestTemplateCache(t *testing.T) {
+	td := &v1alpha2.TraitDefinition{}
+	td.Name = "scaler"
+	td.UID = "uid-1"
+	td.Generation = 1
+	td.Spec.Extension = &runtime.RawExtension{Raw: []byte(`{"template":"output: replicas: 1"}`)}
+	gets := 0
+	tclient := test.MockClient{
+		MockGet: func(ctx context.Context, key ktypes.NamespacedName, obj runtime.Object) error {
+			gets++
+			td.DeepCopyInto(obj.(*v1alpha2.TraitDefinition))
+			return nil
+		},
+	}
+	cache := NewTemplateCache()
+	load := func() string {
+		temp, _, err := cache.LoadTemplate(&tclient, "scaler", types.TypeTrait)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return temp
+	}
+
+	if temp := load(); temp != "output: replicas: 1" {
+		t.Errorf("unexpected template %s", temp)
+	}
+	// the template isn't parsed again if the generation isn't changed
+	td.Spec.Extension.Raw = []byte(`{"template":"output: replicas: 2"}`)
+	if temp := load(); temp != "output: replicas: 1" {
+		t.Errorf("the cached template is expected, but got %s", temp)
+	}
+	td.Generation = 2
+	if temp := load(); temp != "output: replicas: 2" {
+		t.Errorf("the template of generation 2 is expected, but got %s", temp)
+	}
+	// the definition is recreated
+	td.UID = "uid-2"
+	td.Generation = 1
+	td.Spec.Extension.Raw = []byte(`{"template":"output: replicas: 3"}`)
+	if temp := load(); temp != "output: replicas: 3" {
+		t.Errorf("the template of the recreated definition is expected, but got %s", temp)
+	}
+	if gets != 4 {
+		t.Errorf("the definition is expected to be read every time, but it's read %d times", gets)
+	}
+
+	if _, _, err := cache.LoadTemplate(&tclient, "scaler", types.TypeScope); err == nil {
+		t.Error("the template of a scope is not supported")
+	}
+
+	// the least recently used template is evicted when the cache is full
+	cache.size = 2
+	load()
+	if _, _, err := cache.LoadTemplate(&tclient, "autoscaler", types.TypeTrait); err != nil {
+		t.Fatal(err)
+	}
+	load()
+	if _, _, err := cache.LoadTemplate(&tclient, "ingress", types.TypeTrait); err != nil {
+		t.Fatal(err)
+	}
+	if _, ok := cache.get("trait/scaler"); !ok {
+		t.Error("the recently used template of scaler is expected to be kept")
+	}
+	if _, ok := cache.get("trait/autoscaler"); ok {
+		t.Error("the least recently used template of autoscaler is expected to be evicted")
+	}
+	if cache.recent.Len() != 2 || len(cache.templates) != 2 {
+		t.Errorf("2 templates are expected to be cached, but got %d", len(cache.templates))
+	}
+}
@@ -4,6 +4,7 @@ import (
 	"k8s.io/apimachinery/pkg/runtime"
 
 	corev1alpha2 "github.com/oam-dev/kubevela/apis/core.oam.dev/v1alpha2"
+	"github.com/oam-dev/kubevela/apis/standard.oam.dev/v1alpha1"
 
 	"github.com/oam-dev/kubevela/apis/types"
 )
@@ -71,11 +72,13 @@ type ComponentMeta struct {
 	WorkloadName string                        `json:"workloadName,omitempty"`
 	Traits       []corev1alpha2.ComponentTrait `json:"traits,omitempty"`
 	// TraitNames for `vela comp ls`
-	TraitNames  []string                              `json:"traitsNames,omitempty"`
-	App         string                                `json:"app"`
-	CreatedTime string                                `json:"createdTime,omitempty"`
-	AppConfig   corev1alpha2.ApplicationConfiguration `json:"-"`
-	Component   corev1alpha2.Component                `json:"-"`
+	TraitNames []string `json:"traitsNames,omitempty"`
+	// Health is the health of the component recorded in the status of the Application
+	Health      *corev1alpha2.ApplicationComponentStatus `json:"health,omitempty"`
+	App         string                                   `json:"app"`
+	CreatedTime string                                   `json:"createdTime,omitempty"`
+	AppConfig   corev1alpha2.ApplicationConfiguration    `json:"-"`
+	Component   corev1alpha2.Component                   `json:"-"`
 }
 
 // ApplicationMeta used for dashboard restful API server
@@ -97,3 +100,22 @@ type CapabilityCenterMeta struct {
 	Name string `json:"name"`
 	URL  string `json:"url"`
 }
+
+// RolloutMeta is the rollout of an application, it's either an ApplicationDeployment or a RolloutTrait
+type RolloutMeta struct {
+	Kind              string                                `json:"kind"`
+	Name              string                                `json:"name"`
+	AppName           string                                `json:"app"`
+	RolloutPlan       v1alpha1.RolloutPlan                  `json:"rolloutPlan"`
+	Status            v1alpha1.RolloutStatus                `json:"status"`
+	ComponentStatuses []corev1alpha2.ComponentRolloutStatus `json:"componentStatuses,omitempty"`
+}
+
+// RevisionMeta is a revision of an application for `vela history`
+type RevisionMeta struct {
+	Name        string   `json:"name"`
+	Revision    int64    `json:"revision"`
+	Current     bool     `json:"current"`
+	Components  []string `json:"components"`
+	CreatedTime string   `json:"createdTime,omitempty"`
+}
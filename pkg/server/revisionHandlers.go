@@ -0,0 +1,57 @@
+package server
+
+import (
+	"fmt"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+
+	"github.com/oam-dev/kubevela/pkg/server/util"
+	"github.com/oam-dev/kubevela/pkg/serverlib"
+	"github.com/oam-dev/kubevela/pkg/utils/env"
+)
+
+func (s *APIServer) revisionOptions(c *gin.Context) (*serverlib.RevisionOptions, error) {
+	envMeta, err := env.GetEnvByName(c.Param("envName"))
+	if err != nil {
+		return nil, err
+	}
+	return &serverlib.RevisionOptions{
+		AppName: c.Param("appName"),
+		Client:  s.KubeClient,
+		Env:     envMeta,
+	}, nil
+}
+
+// ListRevisions requests the revisions of an application
+func (s *APIServer) ListRevisions(c *gin.Context) {
+	o, err := s.revisionOptions(c)
+	if err != nil {
+		util.HandleError(c, util.StatusInternalServerError, err)
+		return
+	}
+	revisions, err := o.ListRevisions(util.GetContext(c))
+	if err != nil {
+		util.HandleError(c, util.StatusInternalServerError, err.Error())
+		return
+	}
+	util.AssembleResponse(c, revisions, nil)
+}
+
+// RollbackApp rolls back an application to the revision in the `to` query, it's the previous revision by default
+func (s *APIServer) RollbackApp(c *gin.Context) {
+	o, err := s.revisionOptions(c)
+	if err != nil {
+		util.HandleError(c, util.StatusInternalServerError, err)
+		return
+	}
+	var to int64
+	if query := c.Query("to"); len(query) != 0 {
+		if to, err = strconv.ParseInt(query, 10, 64); err != nil {
+			util.HandleError(c, util.InvalidArgument, fmt.Sprintf("invalid revision %s", query))
+			return
+		}
+	}
+	message, err := o.Rollback(util.GetContext(c), to)
+	util.AssembleResponse(c, message, err)
+}
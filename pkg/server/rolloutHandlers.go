@@ -0,0 +1,71 @@
+package server
+
+import (
+	"context"
+
+	"github.com/gin-gonic/gin"
+
+	"github.com/oam-dev/kubevela/pkg/server/util"
+	"github.com/oam-dev/kubevela/pkg/serverlib"
+	"github.com/oam-dev/kubevela/pkg/utils/env"
+)
+
+// rolloutOptions builds the options to operate the rollout of the app in the gin.Context, the optional `svc` query
+// selects the service that has the rollout trait
+func (s *APIServer) rolloutOptions(c *gin.Context) (*serverlib.RolloutOptions, error) {
+	envMeta, err := env.GetEnvByName(c.Param("envName"))
+	if err != nil {
+		return nil, err
+	}
+	return &serverlib.RolloutOptions{
+		AppName:  c.Param("appName"),
+		CompName: c.Query("svc"),
+		Client:   s.KubeClient,
+		Env:      envMeta,
+	}, nil
+}
+
+// GetRollout requests the rollout plan and status of an application
+func (s *APIServer) GetRollout(c *gin.Context) {
+	o, err := s.rolloutOptions(c)
+	if err != nil {
+		util.HandleError(c, util.StatusInternalServerError, err)
+		return
+	}
+	rollout, err := o.GetRollout(util.GetContext(c))
+	if err != nil {
+		util.HandleError(c, util.StatusInternalServerError, err.Error())
+		return
+	}
+	util.AssembleResponse(c, rollout, nil)
+}
+
+// PauseRollout pauses the rollout of an application
+func (s *APIServer) PauseRollout(c *gin.Context) {
+	s.operateRollout(c, (*serverlib.RolloutOptions).PauseRollout)
+}
+
+// ResumeRollout resumes the paused rollout of an application
+func (s *APIServer) ResumeRollout(c *gin.Context) {
+	s.operateRollout(c, (*serverlib.RolloutOptions).ResumeRollout)
+}
+
+// PromoteRolloutBatch allows the rollout of an application to continue with the next batch
+func (s *APIServer) PromoteRolloutBatch(c *gin.Context) {
+	s.operateRollout(c, (*serverlib.RolloutOptions).PromoteBatch)
+}
+
+// AbortRollout aborts the rollout of an application
+func (s *APIServer) AbortRollout(c *gin.Context) {
+	s.operateRollout(c, (*serverlib.RolloutOptions).AbortRollout)
+}
+
+func (s *APIServer) operateRollout(c *gin.Context, operate func(*serverlib.RolloutOptions, context.Context) (string, error)) {
+	o, err := s.rolloutOptions(c)
+	if err != nil {
+		util.HandleError(c, util.StatusInternalServerError, err)
+		return
+	}
+	message, err := operate(o, util.GetContext(c))
+	util.AssembleResponse(c, message, err)
+}
@@ -81,6 +81,20 @@ func (s *APIServer) setupRoute(staticPath string) http.Handler {
 			apps.GET("", s.ListApps)
 			apps.DELETE("/:appName", s.DeleteApps)
 
+			// revision related operation
+			apps.GET("/:appName/revisions", s.ListRevisions)
+			apps.PUT("/:appName/rollback", s.RollbackApp)
+
+			// rollout related operation
+			rollout := apps.Group("/:appName/rollout")
+			{
+				rollout.GET("", s.GetRollout)
+				rollout.PUT("/pause", s.PauseRollout)
+				rollout.PUT("/resume", s.ResumeRollout)
+				rollout.PUT("/promote-batch", s.PromoteRolloutBatch)
+				rollout.DELETE("", s.AbortRollout)
+			}
+
 			// component related operation
 			components := apps.Group("/:appName/components")
 			{
@@ -151,6 +151,16 @@ func RetrieveApplicationStatusByName(ctx context.Context, c client.Client, appli
 	if len(appConfig.Status.Conditions) != 0 {
 		status = string(appConfig.Status.Conditions[0].Status)
 	}
+	// the health of the Application is checked by the Application controller, the ApplicationConfiguration
+	// may not be rendered by an Application
+	var app corev1alpha2.Application
+	if err := c.Get(ctx, client.ObjectKey{Name: applicationName, Namespace: namespace}, &app); err != nil {
+		if !apierrors.IsNotFound(err) {
+			return applicationMeta, err
+		}
+	} else if app.Status.Phase != "" {
+		status = string(app.Status.Phase)
+	}
 	applicationMeta.Name = appConfig.Name
 	applicationMeta.Status = status
 	applicationMeta.CreatedTime = appConfig.CreationTimestamp.Format(time.RFC3339)
@@ -167,13 +177,23 @@ func RetrieveApplicationStatusByName(ctx context.Context, c client.Client, appli
 			Status:   status,
 			Workload: component.Spec.Workload,
 			Traits:   com.Traits,
+			Health:   GetComponentHealth(&app, componentName),
 		})
-		applicationMeta.Status = status
-
 	}
 	return applicationMeta, nil
 }
 
+// GetComponentHealth returns the health of the component recorded in the status of the application, it returns nil
+// if the health isn't checked yet
+func GetComponentHealth(app *corev1alpha2.Application, compName string) *corev1alpha2.ApplicationComponentStatus {
+	for i := range app.Status.Services {
+		if app.Status.Services[i].Name == compName {
+			return &app.Status.Services[i]
+		}
+	}
+	return nil
+}
+
 // DeleteApp will delete app including server side
 func (o *DeleteOptions) DeleteApp() (string, error) {
 	if err := application.Delete(o.Env.Name, o.AppName); err != nil && !os.IsNotExist(err) {
@@ -0,0 +1,38 @@
+package serverlib
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+
+	corev1alpha2 "github.com/oam-dev/kubevela/apis/core.oam.dev/v1alpha2"
+	"github.com/oam-dev/kubevela/pkg/utils/common"
+)
+
+func TestRetrieveApplicationStatusByName(t *testing.T) {
+	ctx := context.Background()
+	ac := &corev1alpha2.ApplicationConfiguration{ObjectMeta: metav1.ObjectMeta{Name: "myapp", Namespace: "default"}}
+	ac.Spec.Components = []corev1alpha2.ApplicationConfigurationComponent{{ComponentName: "myweb"}}
+	comp := &corev1alpha2.Component{ObjectMeta: metav1.ObjectMeta{Name: "myweb", Namespace: "default"}}
+	app := &corev1alpha2.Application{ObjectMeta: metav1.ObjectMeta{Name: "myapp", Namespace: "default"}}
+	app.Status.Phase = corev1alpha2.ApplicationDegraded
+	app.Status.Services = []corev1alpha2.ApplicationComponentStatus{{Name: "myweb", WorkloadDefinition: "webservice",
+		Message: "the workload is unhealthy"}}
+
+	// the health is read from the status of the application
+	c := fake.NewFakeClientWithScheme(common.Scheme, ac, comp, app)
+	meta, err := RetrieveApplicationStatusByName(ctx, c, "myapp", "default")
+	assert.NoError(t, err)
+	assert.Equal(t, "degraded", meta.Status)
+	assert.Equal(t, &app.Status.Services[0], meta.Components[0].Health)
+
+	// the ApplicationConfiguration isn't rendered by an application
+	c = fake.NewFakeClientWithScheme(common.Scheme, ac, comp)
+	meta, err = RetrieveApplicationStatusByName(ctx, c, "myapp", "default")
+	assert.NoError(t, err)
+	assert.Equal(t, "Unknown", meta.Status)
+	assert.Nil(t, meta.Components[0].Health)
+}
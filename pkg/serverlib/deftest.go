@@ -0,0 +1,301 @@
+package serverlib
+
+import (
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+
+	"cuelang.org/go/cue"
+	"github.com/google/go-cmp/cmp"
+	"sigs.k8s.io/yaml"
+
+	"github.com/oam-dev/kubevela/apis/types"
+	"github.com/oam-dev/kubevela/pkg/appfile/template"
+	"github.com/oam-dev/kubevela/pkg/dsl/definition"
+	"github.com/oam-dev/kubevela/pkg/dsl/model"
+	"github.com/oam-dev/kubevela/pkg/dsl/process"
+)
+
+const (
+	// DefinitionTestSuffix is the suffix of the definition test files, e.g. `webservice.test.yaml`
+	DefinitionTestSuffix = ".test.yaml"
+	// DefinitionGoldenSuffix is the suffix of the golden files that keep the expected results of the definition
+	// tests, e.g. `webservice.golden.yaml` of `webservice.test.yaml`
+	DefinitionGoldenSuffix = ".golden.yaml"
+)
+
+// DefinitionTest is the test cases of a definition in a test file
+type DefinitionTest struct {
+	// Definition is the name of the definition under test
+	Definition string               `json:"definition"`
+	Cases      []DefinitionTestCase `json:"cases"`
+	// File is the path of the test file
+	File string `json:"-"`
+}
+
+// DefinitionTestCase renders the definition with the parameter and the context
+type DefinitionTestCase struct {
+	Name      string                 `json:"name"`
+	Context   DefinitionTestContext  `json:"context,omitempty"`
+	Parameter map[string]interface{} `json:"parameter,omitempty"`
+	// Workload is the workload that a trait is applied to, it's the `context.input` of the trait and patched by the
+	// `patch` of the trait. A definition loaded from a standalone CUE file is tested as a trait if it's set.
+	Workload map[string]interface{} `json:"workload,omitempty"`
+}
+
+// DefinitionTestContext is the `context` of the template, the name of the component is the definition name if it's
+// not set
+type DefinitionTestContext struct {
+	Name           string              `json:"name,omitempty"`
+	AppName        string              `json:"appName,omitempty"`
+	Namespace      string              `json:"namespace,omitempty"`
+	AppRevision    string              `json:"appRevision,omitempty"`
+	ComponentType  string              `json:"componentType,omitempty"`
+	AppLabels      map[string]string   `json:"appLabels,omitempty"`
+	AppAnnotations map[string]string   `json:"appAnnotations,omitempty"`
+	Config         []map[string]string `json:"config,omitempty"`
+}
+
+// DefinitionTestOutput is what the definition renders in a test case, it's kept in the golden file
+type DefinitionTestOutput struct {
+	// Output is the workload rendered by a workload definition, or the `output` of a trait
+	Output map[string]interface{} `json:"output,omitempty"`
+	// Outputs are the objects in the `outputs` of the template
+	Outputs map[string]interface{} `json:"outputs,omitempty"`
+	// Patched is the workload patched by the `patch` of a trait, it's omitted if the trait doesn't change the workload
+	Patched map[string]interface{} `json:"patched,omitempty"`
+}
+
+// DefinitionTestResult is the result of a test case
+type DefinitionTestResult struct {
+	File       string
+	Definition string
+	Case       string
+	// Diff is the difference from the golden output to the rendered output, it's empty if they're the same
+	Diff string
+	// Err is the error of rendering, or there is no golden output of the case
+	Err error
+}
+
+// Passed checks whether the rendered output is the same as the golden output
+func (r DefinitionTestResult) Passed() bool {
+	return r.Err == nil && r.Diff == ""
+}
+
+// LoadDefinitionTests loads the test files under the directory
+func LoadDefinitionTests(dir string) ([]*DefinitionTest, error) {
+	var tests []*DefinitionTest
+	err := filepath.Walk(dir, func(path string, info os.FileInfo, err error) error {
+		if err != nil || info.IsDir() || !strings.HasSuffix(path, DefinitionTestSuffix) {
+			return err
+		}
+		data, err := ioutil.ReadFile(filepath.Clean(path))
+		if err != nil {
+			return err
+		}
+		test := &DefinitionTest{}
+		if err := yaml.Unmarshal(data, test); err != nil {
+			return fmt.Errorf("decode definition test %s err %w", path, err)
+		}
+		if test.Definition == "" {
+			return fmt.Errorf("no definition is specified in definition test %s", path)
+		}
+		names := map[string]bool{}
+		for _, c := range test.Cases {
+			if c.Name == "" || names[c.Name] {
+				return fmt.Errorf("the cases of definition test %s must have unique names", path)
+			}
+			names[c.Name] = true
+		}
+		test.File = path
+		tests = append(tests, test)
+		return nil
+	})
+	return tests, err
+}
+
+// RunDefinitionTests renders the test cases with the local definitions and compares the outputs with the golden
+// files. If update is true, the golden files are rewritten with the rendered outputs instead, and only the cases
+// failed to render are reported.
+func RunDefinitionTests(defs *template.LocalManager, tests []*DefinitionTest, update bool) ([]DefinitionTestResult,
+	error) {
+	var results []DefinitionTestResult
+	for _, test := range tests {
+		goldenFile := strings.TrimSuffix(test.File, DefinitionTestSuffix) + DefinitionGoldenSuffix
+		golden, err := loadGolden(goldenFile)
+		if err != nil {
+			return nil, err
+		}
+		updated := map[string]*DefinitionTestOutput{}
+		def, found := defs.Definitions[test.Definition]
+		for _, c := range test.Cases {
+			result := DefinitionTestResult{File: test.File, Definition: test.Definition, Case: c.Name}
+			var got *DefinitionTestOutput
+			if !found {
+				result.Err = fmt.Errorf("definition %s is not defined locally", test.Definition)
+			} else {
+				got, result.Err = RenderDefinitionTestCase(def, c)
+			}
+			switch {
+			case result.Err != nil:
+				if golden[c.Name] != nil {
+					updated[c.Name] = golden[c.Name]
+				}
+			case update:
+				updated[c.Name] = got
+			case golden[c.Name] == nil:
+				result.Err = fmt.Errorf("no golden output in %s, run with --update to create it", goldenFile)
+			default:
+				result.Diff = cmp.Diff(golden[c.Name], got)
+			}
+			results = append(results, result)
+		}
+		if update {
+			if err := saveGolden(goldenFile, updated); err != nil {
+				return nil, err
+			}
+		}
+	}
+	return results, nil
+}
+
+func loadGolden(path string) (map[string]*DefinitionTestOutput, error) {
+	golden := map[string]*DefinitionTestOutput{}
+	data, err := ioutil.ReadFile(filepath.Clean(path))
+	if os.IsNotExist(err) {
+		return golden, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	if err := yaml.Unmarshal(data, &golden); err != nil {
+		return nil, fmt.Errorf("decode golden file %s err %w", path, err)
+	}
+	return golden, nil
+}
+
+func saveGolden(path string, golden map[string]*DefinitionTestOutput) error {
+	data, err := yaml.Marshal(golden)
+	if err != nil {
+		return err
+	}
+	return ioutil.WriteFile(path, data, 0644)
+}
+
+// RenderDefinitionTestCase renders the definition in the same way as the application parser does for a component,
+// the outputs are decoded from JSON so that they're comparable with the golden outputs.
+func RenderDefinitionTestCase(def *template.LocalDefinition, c DefinitionTestCase) (*DefinitionTestOutput, error) {
+	name := c.Context.Name
+	if name == "" {
+		name = def.Name
+	}
+	pCtx := process.NewContext(name)
+	pCtx.SetCluster(nil, c.Context.Namespace)
+	pCtx.SetApplication(process.Application{
+		Name:          c.Context.AppName,
+		Revision:      c.Context.AppRevision,
+		ComponentType: c.Context.ComponentType,
+		Labels:        c.Context.AppLabels,
+		Annotations:   c.Context.AppAnnotations,
+	})
+	if len(c.Context.Config) > 0 {
+		pCtx.SetConfigs(c.Context.Config)
+	}
+
+	isTrait := def.Type == types.TypeTrait || (def.Type == "" && c.Workload != nil)
+	var workload map[string]interface{}
+	if c.Workload != nil {
+		if !isTrait {
+			return nil, fmt.Errorf("workload is only used to test traits, but %s is a workload type", def.Name)
+		}
+		base, err := newBase(c.Workload)
+		if err != nil {
+			return nil, fmt.Errorf("compile workload err %w", err)
+		}
+		pCtx.SetBase(base)
+		if workload, err = normalize(c.Workload); err != nil {
+			return nil, err
+		}
+	}
+
+	var templater definition.Template
+	if isTrait {
+		templater = definition.NewTDTemplater(def.Name, def.Template, def.Health)
+	} else {
+		templater = definition.NewWDTemplater(def.Name, def.Template, def.Health)
+	}
+	params := c.Parameter
+	if params == nil {
+		params = map[string]interface{}{}
+	}
+	if err := templater.Params(params).Complete(pCtx); err != nil {
+		return nil, err
+	}
+
+	out := &DefinitionTestOutput{}
+	base, assists := pCtx.Output()
+	if base != nil {
+		obj, err := toMap(base)
+		if err != nil {
+			return nil, err
+		}
+		if !isTrait {
+			out.Output = obj
+		} else if !reflect.DeepEqual(obj, workload) {
+			out.Patched = obj
+		}
+	}
+	for _, assist := range assists {
+		obj, err := toMap(assist.Ins)
+		if err != nil {
+			return nil, err
+		}
+		if assist.Name == "" {
+			out.Output = obj
+			continue
+		}
+		if out.Outputs == nil {
+			out.Outputs = map[string]interface{}{}
+		}
+		out.Outputs[assist.Name] = obj
+	}
+	return out, nil
+}
+
+func newBase(obj map[string]interface{}) (model.Instance, error) {
+	bt, err := json.Marshal(obj)
+	if err != nil {
+		return nil, err
+	}
+	var r cue.Runtime
+	inst, err := r.Compile("-", string(bt))
+	if err != nil {
+		return nil, err
+	}
+	return model.NewBase(inst.Value())
+}
+
+func toMap(inst model.Instance) (map[string]interface{}, error) {
+	u, err := inst.Unstructured()
+	if err != nil {
+		return nil, err
+	}
+	return normalize(u.Object)
+}
+
+// normalize decodes the object from JSON in the same way as the golden files are decoded, e.g. numbers are float64
+func normalize(obj map[string]interface{}) (map[string]interface{}, error) {
+	bt, err := json.Marshal(obj)
+	if err != nil {
+		return nil, err
+	}
+	normalized := map[string]interface{}{}
+	if err := yaml.Unmarshal(bt, &normalized); err != nil {
+		return nil, err
+	}
+	return normalized, nil
+}
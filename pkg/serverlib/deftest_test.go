@@ -0,0 +1,93 @@
+package serverlib
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/oam-dev/kubevela/pkg/appfile/template"
+)
+
+func TestRunDefinitionTests(t *testing.T) {
+	for _, dir := range []string{"../../hack/vela-templates", "testdata/deftest"} {
+		t.Run(dir, func(t *testing.T) {
+			defs, err := template.LoadLocalDefinitions(dir)
+			assert.NoError(t, err)
+			tests, err := LoadDefinitionTests(dir)
+			assert.NoError(t, err)
+			assert.NotEmpty(t, tests)
+			results, err := RunDefinitionTests(defs, tests, false)
+			assert.NoError(t, err)
+			for _, r := range results {
+				assert.True(t, r.Passed(), "%s/%s: %v %s", r.Definition, r.Case, r.Err, r.Diff)
+			}
+		})
+	}
+}
+
+func TestRunDefinitionTestsFailed(t *testing.T) {
+	defs, err := template.LoadLocalDefinitions("testdata/deftest")
+	assert.NoError(t, err)
+	tests, err := LoadDefinitionTests("testdata/deftest")
+	assert.NoError(t, err)
+	for _, test := range tests {
+		switch test.Definition {
+		case "webservice":
+			test.Cases[0].Parameter["port"] = 9090
+		case "sidecar":
+			test.Cases = append(test.Cases, DefinitionTestCase{Name: "new"},
+				DefinitionTestCase{Name: "no-workload", Parameter: test.Cases[0].Parameter})
+			test.Cases[1].Workload = test.Cases[0].Workload
+		}
+	}
+
+	results, err := RunDefinitionTests(defs, tests, false)
+	assert.NoError(t, err)
+	failed := map[string]DefinitionTestResult{}
+	for _, r := range results {
+		if !r.Passed() {
+			failed[r.Definition+"/"+r.Case] = r
+		}
+	}
+	assert.Equal(t, 3, len(failed))
+	assert.Contains(t, failed["webservice/service"].Diff, "9090")
+	assert.Contains(t, failed["sidecar/new"].Err.Error(), "no golden output in testdata/deftest/sidecar.golden.yaml")
+	assert.Contains(t, failed["sidecar/no-workload"].Err.Error(), "there is no workload to patch")
+}
+
+func TestUpdateDefinitionTests(t *testing.T) {
+	dir, err := ioutil.TempDir("", "deftest")
+	assert.NoError(t, err)
+	defer os.RemoveAll(dir)
+	files, err := ioutil.ReadDir("testdata/deftest")
+	assert.NoError(t, err)
+	for _, f := range files {
+		if strings.HasSuffix(f.Name(), DefinitionGoldenSuffix) {
+			continue
+		}
+		data, err := ioutil.ReadFile(filepath.Join("testdata/deftest", f.Name()))
+		assert.NoError(t, err)
+		assert.NoError(t, ioutil.WriteFile(filepath.Join(dir, f.Name()), data, 0644))
+	}
+
+	defs, err := template.LoadLocalDefinitions(dir)
+	assert.NoError(t, err)
+	tests, err := LoadDefinitionTests(dir)
+	assert.NoError(t, err)
+	results, err := RunDefinitionTests(defs, tests, true)
+	assert.NoError(t, err)
+	for _, r := range results {
+		assert.True(t, r.Passed())
+	}
+	for _, name := range []string{"sidecar.golden.yaml", "webservice.golden.yaml"} {
+		want, err := ioutil.ReadFile(filepath.Join("testdata/deftest", name))
+		assert.NoError(t, err)
+		got, err := ioutil.ReadFile(filepath.Join(dir, name))
+		assert.NoError(t, err)
+		assert.Equal(t, string(want), string(got))
+	}
+}
@@ -0,0 +1,120 @@
+package serverlib
+
+import (
+	"fmt"
+	"io/ioutil"
+	"path/filepath"
+
+	"sigs.k8s.io/yaml"
+
+	corev1alpha2 "github.com/oam-dev/kubevela/apis/core.oam.dev/v1alpha2"
+	"github.com/oam-dev/kubevela/apis/types"
+	"github.com/oam-dev/kubevela/pkg/appfile"
+	"github.com/oam-dev/kubevela/pkg/appfile/template"
+	cmdutil "github.com/oam-dev/kubevela/pkg/commands/util"
+	"github.com/oam-dev/kubevela/pkg/controller/core.oam.dev/v1alpha2/application"
+)
+
+// LoadApplication loads the Application in the file, the file is either an Application YAML or an Appfile. The
+// traits of the services in the Appfile are told by the template manager, and the images are not built.
+func LoadApplication(path, namespace string, tm template.Manager, io cmdutil.IOStreams) (
+	*corev1alpha2.Application, error) {
+	data, err := ioutil.ReadFile(filepath.Clean(path))
+	if err != nil {
+		return nil, err
+	}
+	obj := map[string]interface{}{}
+	if err := yaml.Unmarshal(data, &obj); err != nil {
+		return nil, fmt.Errorf("decode %s err %w", path, err)
+	}
+	if obj["kind"] == corev1alpha2.ApplicationKind {
+		app := &corev1alpha2.Application{}
+		if err := yaml.Unmarshal(data, app); err != nil {
+			return nil, fmt.Errorf("decode application %s err %w", path, err)
+		}
+		if app.Namespace == "" {
+			app.Namespace = namespace
+		}
+		return app, nil
+	}
+
+	af, err := appfile.LoadFromFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("load appfile %s err %w", path, err)
+	}
+	for _, svc := range af.Services {
+		delete(svc, "build")
+	}
+	app, _, err := af.BuildOAMApplication(&types.EnvMeta{Namespace: namespace}, io, tm, true)
+	if err != nil {
+		return nil, fmt.Errorf("build application from appfile %s err %w", path, err)
+	}
+	return app, nil
+}
+
+// HasScopes checks whether the application puts its components into scopes
+func HasScopes(app *corev1alpha2.Application) bool {
+	if len(app.Spec.Scopes) > 0 {
+		return true
+	}
+	for _, comp := range app.Spec.Components {
+		if len(comp.Scopes) > 0 {
+			return true
+		}
+	}
+	return false
+}
+
+// RenderOffline renders the application with the local definitions in the same way as the application controller
+// does, but without a cluster. The scopes are skipped as their types can't be discovered, and the parameters are
+// validated before rendering.
+func RenderOffline(app *corev1alpha2.Application, defs *template.LocalManager) (*application.RenderedResources,
+	error) {
+	p, app, err := offlineParser(app, defs)
+	if err != nil {
+		return nil, err
+	}
+	return p.Render(app)
+}
+
+// ExplainOffline renders the application with the local definitions in the same way as RenderOffline, and explains
+// where the fields of the rendered objects come from
+func ExplainOffline(app *corev1alpha2.Application, defs *template.LocalManager) (*application.RenderedResources,
+	error) {
+	p, app, err := offlineParser(app, defs)
+	if err != nil {
+		return nil, err
+	}
+	return p.Explain(app)
+}
+
+// offlineParser returns a parser with the local definitions, and the application without the scopes
+func offlineParser(app *corev1alpha2.Application, defs *template.LocalManager) (*application.Parser,
+	*corev1alpha2.Application, error) {
+	app = app.DeepCopy()
+	app.Spec.Scopes = nil
+	for i := range app.Spec.Components {
+		comp := &app.Spec.Components[i]
+		comp.Scopes = nil
+		if _, ok := defs.Get(comp.WorkloadType, types.TypeWorkload); !ok {
+			return nil, nil, fmt.Errorf("workload type %s of component %s is not defined locally", comp.WorkloadType,
+				comp.Name)
+		}
+		for _, tr := range comp.Traits {
+			if _, ok := defs.Get(tr.Name, types.TypeTrait); !ok {
+				return nil, nil, fmt.Errorf("trait %s of component %s is not defined locally", tr.Name, comp.Name)
+			}
+		}
+	}
+
+	p := application.NewApplicationParser(nil, nil)
+	p.UseRevisionTemplates(defs.Revision())
+	af, err := p.GenerateAppFile(app.Name, app)
+	if err != nil {
+		return nil, nil, err
+	}
+	if err := af.ValidateParameters(); err != nil {
+		return nil, nil, err
+	}
+	return p, app, nil
+}
@@ -0,0 +1,73 @@
+package serverlib
+
+import (
+	"os"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+
+	"github.com/oam-dev/kubevela/apis/types"
+	"github.com/oam-dev/kubevela/pkg/appfile/template"
+	cmdutil "github.com/oam-dev/kubevela/pkg/commands/util"
+	"github.com/oam-dev/kubevela/pkg/dsl/model"
+)
+
+func TestRenderOffline(t *testing.T) {
+	io := cmdutil.IOStreams{In: os.Stdin, Out: os.Stdout, ErrOut: os.Stderr}
+	defs, err := template.LoadLocalDefinitions("../../hack/vela-templates")
+	assert.NoError(t, err)
+	scaler, ok := defs.Get("scaler", types.TypeTrait)
+	assert.True(t, ok)
+	assert.Equal(t, "../../hack/vela-templates/cue/manualscale.cue", scaler.File)
+	_, ok = defs.Get("scaler", types.TypeWorkload)
+	assert.False(t, ok)
+
+	for _, file := range []string{"testdata/render/application.yaml", "testdata/render/vela.yaml"} {
+		t.Run(file, func(t *testing.T) {
+			app, err := LoadApplication(file, "default", defs, io)
+			assert.NoError(t, err)
+			assert.Equal(t, "website", app.Name)
+			assert.Equal(t, "default", app.Namespace)
+
+			rendered, err := RenderOffline(app, defs)
+			assert.NoError(t, err)
+			assert.Equal(t, 1, len(rendered.Components))
+			workload := rendered.Components[0].Spec.Workload.Object.(*unstructured.Unstructured)
+			assert.Equal(t, "Deployment", workload.GetKind())
+			traits := rendered.AppConfig.Spec.Components[0].Traits
+			assert.Equal(t, 1, len(traits))
+			replicas, _, _ := unstructured.NestedInt64(traits[0].Trait.Object.(*unstructured.Unstructured).Object,
+				"spec", "replicaCount")
+			assert.Equal(t, int64(2), replicas)
+		})
+	}
+
+	app, err := LoadApplication("testdata/render/application.yaml", "default", defs, io)
+	assert.NoError(t, err)
+	app.Spec.Components[0].Traits[0].Name = "autoscaler"
+	_, err = RenderOffline(app, defs)
+	assert.EqualError(t, err, "trait autoscaler of component frontend is not defined locally")
+}
+
+func TestExplainOffline(t *testing.T) {
+	io := cmdutil.IOStreams{In: os.Stdin, Out: os.Stdout, ErrOut: os.Stderr}
+	defs, err := template.LoadLocalDefinitions("../../hack/vela-templates")
+	assert.NoError(t, err)
+	app, err := LoadApplication("testdata/render/application.yaml", "default", defs, io)
+	assert.NoError(t, err)
+
+	rendered, err := ExplainOffline(app, defs)
+	assert.NoError(t, err)
+	assert.Equal(t, 1, len(rendered.Explanations))
+	exp := rendered.Explanations[0]
+	assert.Equal(t, "frontend", exp.Component)
+	assert.Equal(t, []model.Source{{Definition: "webservice", Parameter: "parameter.image"}},
+		exp.Workload["spec.template.spec.containers[0].image"])
+	assert.Equal(t, []model.Source{{Definition: "webservice", Parameter: "context.name"}},
+		exp.Workload["spec.template.spec.containers[0].name"])
+	assert.Equal(t, 1, len(exp.Traits))
+	assert.Equal(t, "scaler", exp.Traits[0].Type)
+	assert.Equal(t, []model.Source{{Definition: "scaler", Parameter: "parameter.replicas"}},
+		exp.Traits[0].Fields["spec.replicaCount"])
+}
@@ -0,0 +1,105 @@
+package serverlib
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	corev1alpha2 "github.com/oam-dev/kubevela/apis/core.oam.dev/v1alpha2"
+	"github.com/oam-dev/kubevela/apis/types"
+	"github.com/oam-dev/kubevela/pkg/controller/core.oam.dev/v1alpha2/application"
+	"github.com/oam-dev/kubevela/pkg/oam"
+	"github.com/oam-dev/kubevela/pkg/server/apis"
+)
+
+// RevisionOptions is options for the revisions of an application
+type RevisionOptions struct {
+	AppName string
+	Client  client.Client
+	Env     *types.EnvMeta
+}
+
+func (o *RevisionOptions) getApp(ctx context.Context) (*corev1alpha2.Application, error) {
+	app := &corev1alpha2.Application{}
+	if err := o.Client.Get(ctx, client.ObjectKey{Namespace: o.Env.Namespace, Name: o.AppName}, app); err != nil {
+		return nil, fmt.Errorf("get application %s err %w", o.AppName, err)
+	}
+	return app, nil
+}
+
+// ListRevisions lists the revisions of the app from the oldest to the newest
+func (o *RevisionOptions) ListRevisions(ctx context.Context) ([]apis.RevisionMeta, error) {
+	app, err := o.getApp(ctx)
+	if err != nil {
+		return nil, err
+	}
+	revs, err := application.ListRevisions(ctx, o.Client, o.Env.Namespace, o.AppName)
+	if err != nil {
+		return nil, fmt.Errorf("list application revisions err %w", err)
+	}
+	var revisions []apis.RevisionMeta
+	for _, rev := range revs {
+		var components []string
+		for _, comp := range rev.Spec.Application.Components {
+			components = append(components, comp.Name)
+		}
+		revisions = append(revisions, apis.RevisionMeta{
+			Name:        rev.Name,
+			Revision:    rev.Spec.Revision,
+			Current:     app.Status.LatestRevision != nil && app.Status.LatestRevision.Name == rev.Name,
+			Components:  components,
+			CreatedTime: rev.CreationTimestamp.Format(time.RFC3339),
+		})
+	}
+	return revisions, nil
+}
+
+// Rollback applies the spec of the revision to the app and the app is rendered with the templates of the revision.
+// The app is rolled back to the revision before the current one if the revision is 0.
+func (o *RevisionOptions) Rollback(ctx context.Context, revision int64) (string, error) {
+	app, err := o.getApp(ctx)
+	if err != nil {
+		return "", err
+	}
+	revs, err := application.ListRevisions(ctx, o.Client, o.Env.Namespace, o.AppName)
+	if err != nil {
+		return "", fmt.Errorf("list application revisions err %w", err)
+	}
+	var target *corev1alpha2.ApplicationRevision
+	if revision == 0 {
+		if app.Status.LatestRevision == nil {
+			return "", fmt.Errorf("app %s has no revision yet", o.AppName)
+		}
+		// the previous revision is the newest one before the current revision as the old revisions may be deleted
+		for i := range revs {
+			if revs[i].Spec.Revision < app.Status.LatestRevision.Revision {
+				target = &revs[i]
+			}
+		}
+		if target == nil {
+			return "", fmt.Errorf("app %s has no revision before the current revision %d", o.AppName,
+				app.Status.LatestRevision.Revision)
+		}
+	} else {
+		for i := range revs {
+			if revs[i].Spec.Revision == revision {
+				target = &revs[i]
+			}
+		}
+		if target == nil {
+			return "", fmt.Errorf("revision %d of app %s is not found", revision, o.AppName)
+		}
+	}
+
+	app.Spec = *target.Spec.Application.DeepCopy()
+	if app.Annotations == nil {
+		app.Annotations = map[string]string{}
+	}
+	app.Annotations[oam.AnnotationRollbackRevision] = target.Name
+	if err := o.Client.Update(ctx, app); err != nil {
+		return "", fmt.Errorf("rollback application %s err %w", o.AppName, err)
+	}
+	return fmt.Sprintf("app %s is rolled back to revision %d", o.AppName, target.Spec.Revision), nil
+}
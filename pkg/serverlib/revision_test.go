@@ -0,0 +1,66 @@
+package serverlib
+
+import (
+	"context"
+	"fmt"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+
+	corev1alpha2 "github.com/oam-dev/kubevela/apis/core.oam.dev/v1alpha2"
+	"github.com/oam-dev/kubevela/apis/types"
+	"github.com/oam-dev/kubevela/pkg/oam"
+	"github.com/oam-dev/kubevela/pkg/utils/common"
+)
+
+func newRevision(revision int64, components ...string) *corev1alpha2.ApplicationRevision {
+	rev := &corev1alpha2.ApplicationRevision{
+		ObjectMeta: metav1.ObjectMeta{Name: fmt.Sprintf("myapp-v%d", revision), Namespace: "default",
+			Labels: map[string]string{oam.LabelAppName: "myapp"}},
+		Spec: corev1alpha2.ApplicationRevisionSpec{Revision: revision},
+	}
+	for _, comp := range components {
+		rev.Spec.Application.Components = append(rev.Spec.Application.Components,
+			corev1alpha2.ApplicationComponent{Name: comp, WorkloadType: "webservice"})
+	}
+	return rev
+}
+
+func TestRevisions(t *testing.T) {
+	ctx := context.Background()
+	app := &corev1alpha2.Application{ObjectMeta: metav1.ObjectMeta{Name: "myapp", Namespace: "default"}}
+	app.Spec = newRevision(4, "frontend", "backend").Spec.Application
+	app.Status.LatestRevision = &corev1alpha2.Revision{Name: "myapp-v4", Revision: 4}
+	c := fake.NewFakeClientWithScheme(common.Scheme, app, newRevision(2, "frontend"),
+		newRevision(4, "frontend", "backend"), newRevision(3, "frontend", "worker"))
+	o := &RevisionOptions{AppName: "myapp", Client: c, Env: &types.EnvMeta{Namespace: "default"}}
+
+	revisions, err := o.ListRevisions(ctx)
+	assert.NoError(t, err)
+	assert.Equal(t, 3, len(revisions))
+	assert.Equal(t, int64(2), revisions[0].Revision)
+	assert.Equal(t, []string{"frontend", "worker"}, revisions[1].Components)
+	assert.False(t, revisions[1].Current)
+	assert.True(t, revisions[2].Current)
+
+	getApp := func() *corev1alpha2.Application {
+		var latest corev1alpha2.Application
+		assert.NoError(t, c.Get(ctx, client.ObjectKey{Namespace: "default", Name: "myapp"}, &latest))
+		return &latest
+	}
+	// the app is rolled back to the previous revision by default
+	_, err = o.Rollback(ctx, 0)
+	assert.NoError(t, err)
+	assert.Equal(t, newRevision(3, "frontend", "worker").Spec.Application, getApp().Spec)
+	assert.Equal(t, "myapp-v3", getApp().Annotations[oam.AnnotationRollbackRevision])
+
+	_, err = o.Rollback(ctx, 2)
+	assert.NoError(t, err)
+	assert.Equal(t, newRevision(2, "frontend").Spec.Application, getApp().Spec)
+
+	_, err = o.Rollback(ctx, 1)
+	assert.Error(t, err)
+}
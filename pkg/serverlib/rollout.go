@@ -0,0 +1,203 @@
+package serverlib
+
+import (
+	"context"
+	"fmt"
+
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/utils/pointer"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	corev1alpha2 "github.com/oam-dev/kubevela/apis/core.oam.dev/v1alpha2"
+	"github.com/oam-dev/kubevela/apis/standard.oam.dev/v1alpha1"
+	"github.com/oam-dev/kubevela/apis/types"
+	"github.com/oam-dev/kubevela/pkg/oam"
+	"github.com/oam-dev/kubevela/pkg/server/apis"
+)
+
+// The kinds of the objects that roll out an application
+const (
+	RolloutKindApplicationDeployment = "ApplicationDeployment"
+	RolloutKindRolloutTrait          = "RolloutTrait"
+)
+
+// RolloutOptions is options for operating the rollout of an application
+type RolloutOptions struct {
+	AppName string
+	// CompName is optional, it's required only if more than one components of the app have rollout traits
+	CompName string
+	Client   client.Client
+	Env      *types.EnvMeta
+}
+
+// rollout is the object that rolls out the application
+type rollout struct {
+	obj  runtime.Object
+	plan *v1alpha1.RolloutPlan
+	meta apis.RolloutMeta
+}
+
+// RolloutBatchCount returns the number of batches of the rollout plan
+func RolloutBatchCount(plan v1alpha1.RolloutPlan) int32 {
+	if len(plan.RolloutBatches) != 0 {
+		return int32(len(plan.RolloutBatches))
+	}
+	if plan.NumBatches != nil {
+		return *plan.NumBatches
+	}
+	return 1
+}
+
+// getRollout finds the rollout of the application. An ApplicationDeployment that upgrades to the application is
+// preferred, the newest one is used if there are many. Otherwise it's the RolloutTrait of the application.
+func (o *RolloutOptions) getRollout(ctx context.Context) (*rollout, error) {
+	var appDeploys corev1alpha2.ApplicationDeploymentList
+	if err := o.Client.List(ctx, &appDeploys, client.InNamespace(o.Env.Namespace)); err != nil {
+		return nil, fmt.Errorf("list application deployments err %w", err)
+	}
+	var appDeploy *corev1alpha2.ApplicationDeployment
+	for i, d := range appDeploys.Items {
+		if d.Spec.TargetApplicationName != o.AppName {
+			continue
+		}
+		if appDeploy == nil || appDeploy.CreationTimestamp.Before(&d.CreationTimestamp) {
+			appDeploy = &appDeploys.Items[i]
+		}
+	}
+	if appDeploy != nil {
+		return &rollout{
+			obj:  appDeploy,
+			plan: &appDeploy.Spec.RolloutPlan,
+			meta: apis.RolloutMeta{
+				Kind:              RolloutKindApplicationDeployment,
+				Name:              appDeploy.Name,
+				AppName:           o.AppName,
+				RolloutPlan:       appDeploy.Spec.RolloutPlan,
+				Status:            appDeploy.Status.RolloutStatus,
+				ComponentStatuses: appDeploy.Status.ComponentStatuses,
+			},
+		}, nil
+	}
+
+	labels := client.MatchingLabels{oam.LabelAppName: o.AppName}
+	if len(o.CompName) != 0 {
+		labels[oam.LabelAppComponent] = o.CompName
+	}
+	var traits v1alpha1.RolloutTraitList
+	if err := o.Client.List(ctx, &traits, client.InNamespace(o.Env.Namespace), labels); err != nil {
+		return nil, fmt.Errorf("list rollout traits err %w", err)
+	}
+	switch len(traits.Items) {
+	case 0:
+		return nil, fmt.Errorf("no rollout is found for app %s", o.AppName)
+	case 1:
+	default:
+		return nil, fmt.Errorf("app %s has %d rollout traits, please specify the service", o.AppName, len(traits.Items))
+	}
+	trait := &traits.Items[0]
+	return &rollout{
+		obj:  trait,
+		plan: &trait.Spec.RolloutPlan,
+		meta: apis.RolloutMeta{
+			Kind:        RolloutKindRolloutTrait,
+			Name:        trait.Name,
+			AppName:     o.AppName,
+			RolloutPlan: trait.Spec.RolloutPlan,
+			Status:      trait.Status,
+		},
+	}, nil
+}
+
+// patchRolloutPlan changes the rollout plan with a merge patch
+func (o *RolloutOptions) patchRolloutPlan(ctx context.Context, r *rollout, mutate func(plan *v1alpha1.RolloutPlan)) error {
+	orig := r.obj.DeepCopyObject()
+	mutate(r.plan)
+	if err := o.Client.Patch(ctx, r.obj, client.MergeFrom(orig)); err != nil {
+		return fmt.Errorf("update the rollout plan of %s %s err %w", r.meta.Kind, r.meta.Name, err)
+	}
+	return nil
+}
+
+// GetRollout returns the rollout plan and status of the app
+func (o *RolloutOptions) GetRollout(ctx context.Context) (apis.RolloutMeta, error) {
+	r, err := o.getRollout(ctx)
+	if err != nil {
+		return apis.RolloutMeta{}, err
+	}
+	return r.meta, nil
+}
+
+// PauseRollout stops the rollout at the current batch
+func (o *RolloutOptions) PauseRollout(ctx context.Context) (string, error) {
+	r, err := o.getRollout(ctx)
+	if err != nil {
+		return "", err
+	}
+	if r.plan.Paused {
+		return fmt.Sprintf("the rollout of app %s is already paused", o.AppName), nil
+	}
+	if err := o.patchRolloutPlan(ctx, r, func(plan *v1alpha1.RolloutPlan) {
+		plan.Paused = true
+	}); err != nil {
+		return "", err
+	}
+	return fmt.Sprintf("the rollout of app %s is paused", o.AppName), nil
+}
+
+// ResumeRollout resumes a paused rollout
+func (o *RolloutOptions) ResumeRollout(ctx context.Context) (string, error) {
+	r, err := o.getRollout(ctx)
+	if err != nil {
+		return "", err
+	}
+	if !r.plan.Paused {
+		return fmt.Sprintf("the rollout of app %s is not paused", o.AppName), nil
+	}
+	if err := o.patchRolloutPlan(ctx, r, func(plan *v1alpha1.RolloutPlan) {
+		plan.Paused = false
+	}); err != nil {
+		return "", err
+	}
+	return fmt.Sprintf("the rollout of app %s is resumed", o.AppName), nil
+}
+
+// PromoteBatch moves the batch partition to the next batch so that the rollout can continue with one more batch
+func (o *RolloutOptions) PromoteBatch(ctx context.Context) (string, error) {
+	r, err := o.getRollout(ctx)
+	if err != nil {
+		return "", err
+	}
+	if r.plan.BatchPartition == nil {
+		return "", fmt.Errorf("the rollout of app %s has no batch partition, all the batches are rolled out", o.AppName)
+	}
+	next := *r.plan.BatchPartition + 1
+	if next >= RolloutBatchCount(*r.plan) {
+		return "", fmt.Errorf("all the batches of the rollout of app %s are already promoted", o.AppName)
+	}
+	if err := o.patchRolloutPlan(ctx, r, func(plan *v1alpha1.RolloutPlan) {
+		plan.BatchPartition = pointer.Int32Ptr(next)
+	}); err != nil {
+		return "", err
+	}
+	return fmt.Sprintf("the rollout of app %s is promoted to batch %d", o.AppName, next), nil
+}
+
+// AbortRollout deletes the ApplicationDeployment of the app, the rollout is reverted if it has RevertOnDelete set
+func (o *RolloutOptions) AbortRollout(ctx context.Context) (string, error) {
+	r, err := o.getRollout(ctx)
+	if err != nil {
+		return "", err
+	}
+	appDeploy, ok := r.obj.(*corev1alpha2.ApplicationDeployment)
+	if !ok {
+		return "", fmt.Errorf("the rollout trait of app %s is managed by the app and can't be aborted, pause it instead",
+			o.AppName)
+	}
+	if err := o.Client.Delete(ctx, appDeploy); err != nil {
+		return "", fmt.Errorf("delete application deployment err %w", err)
+	}
+	if appDeploy.Spec.RevertOnDelete != nil && *appDeploy.Spec.RevertOnDelete {
+		return fmt.Sprintf("the rollout of app %s is aborted and is being reverted", o.AppName), nil
+	}
+	return fmt.Sprintf("the rollout of app %s is aborted", o.AppName), nil
+}
@@ -0,0 +1,102 @@
+package serverlib
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/utils/pointer"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+
+	corev1alpha2 "github.com/oam-dev/kubevela/apis/core.oam.dev/v1alpha2"
+	"github.com/oam-dev/kubevela/apis/standard.oam.dev/v1alpha1"
+	"github.com/oam-dev/kubevela/apis/types"
+	"github.com/oam-dev/kubevela/pkg/oam"
+	"github.com/oam-dev/kubevela/pkg/utils/common"
+)
+
+func newRolloutTrait(name, app, comp string) *v1alpha1.RolloutTrait {
+	return &v1alpha1.RolloutTrait{
+		ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: "default",
+			Labels: map[string]string{oam.LabelAppName: app, oam.LabelAppComponent: comp}},
+	}
+}
+
+func TestRolloutOfApplicationDeployment(t *testing.T) {
+	ctx := context.Background()
+	appDeploy := &corev1alpha2.ApplicationDeployment{
+		ObjectMeta: metav1.ObjectMeta{Name: "frontend-v2", Namespace: "default"},
+		Spec: corev1alpha2.ApplicationDeploymentSpec{
+			TargetApplicationName: "frontend",
+			RolloutPlan:           v1alpha1.RolloutPlan{NumBatches: pointer.Int32Ptr(3), BatchPartition: pointer.Int32Ptr(0)},
+		},
+	}
+	appDeploy.Status.RollingState = v1alpha1.Rolling
+	c := fake.NewFakeClientWithScheme(common.Scheme, appDeploy, newRolloutTrait("frontend-rollout", "frontend", "web"))
+	o := &RolloutOptions{AppName: "frontend", Client: c, Env: &types.EnvMeta{Namespace: "default"}}
+	key := client.ObjectKey{Namespace: "default", Name: "frontend-v2"}
+	getPlan := func() v1alpha1.RolloutPlan {
+		var latest corev1alpha2.ApplicationDeployment
+		assert.NoError(t, c.Get(ctx, key, &latest))
+		return latest.Spec.RolloutPlan
+	}
+
+	// the application deployment is preferred to the rollout trait
+	rollout, err := o.GetRollout(ctx)
+	assert.NoError(t, err)
+	assert.Equal(t, RolloutKindApplicationDeployment, rollout.Kind)
+	assert.Equal(t, v1alpha1.Rolling, rollout.Status.RollingState)
+
+	_, err = o.PauseRollout(ctx)
+	assert.NoError(t, err)
+	assert.True(t, getPlan().Paused)
+	_, err = o.ResumeRollout(ctx)
+	assert.NoError(t, err)
+	assert.False(t, getPlan().Paused)
+
+	_, err = o.PromoteBatch(ctx)
+	assert.NoError(t, err)
+	assert.Equal(t, int32(1), *getPlan().BatchPartition)
+	_, err = o.PromoteBatch(ctx)
+	assert.NoError(t, err)
+	_, err = o.PromoteBatch(ctx)
+	assert.Error(t, err)
+
+	_, err = o.AbortRollout(ctx)
+	assert.NoError(t, err)
+	assert.True(t, apierrors.IsNotFound(c.Get(ctx, key, &corev1alpha2.ApplicationDeployment{})))
+}
+
+func TestRolloutOfRolloutTrait(t *testing.T) {
+	ctx := context.Background()
+	c := fake.NewFakeClientWithScheme(common.Scheme, newRolloutTrait("web-rollout", "frontend", "web"),
+		newRolloutTrait("api-rollout", "frontend", "api"), newRolloutTrait("other-rollout", "backend", "web"))
+	o := &RolloutOptions{AppName: "frontend", Client: c, Env: &types.EnvMeta{Namespace: "default"}}
+
+	// the service is required if the app has more than one rollout traits
+	_, err := o.GetRollout(ctx)
+	assert.Error(t, err)
+
+	o.CompName = "api"
+	rollout, err := o.GetRollout(ctx)
+	assert.NoError(t, err)
+	assert.Equal(t, RolloutKindRolloutTrait, rollout.Kind)
+	assert.Equal(t, "api-rollout", rollout.Name)
+
+	_, err = o.PauseRollout(ctx)
+	assert.NoError(t, err)
+	var trait v1alpha1.RolloutTrait
+	assert.NoError(t, c.Get(ctx, client.ObjectKey{Namespace: "default", Name: "api-rollout"}, &trait))
+	assert.True(t, trait.Spec.RolloutPlan.Paused)
+
+	// the rollout trait is managed by the app
+	_, err = o.AbortRollout(ctx)
+	assert.Error(t, err)
+
+	o.AppName = "unknown"
+	_, err = o.GetRollout(ctx)
+	assert.Error(t, err)
+}
@@ -0,0 +1,13 @@
+patch: {
+	spec: template: spec: {
+		// +patchKey=name
+		containers: [{
+			name:  parameter.name
+			image: parameter.image
+		}]
+	}
+}
+parameter: {
+	name:  string
+	image: string
+}
@@ -160,3 +160,21 @@ func MustBeControllableBy(u types.UID) ApplyOption {
 		return nil
 	}
 }
+
+// DetectDrift sets drifted to whether the existing object has drifted from the configuration applied last time, that
+// is, some fields set by the last apply are changed by others. The drifted fields are overwritten by the apply while
+// the fields not set by the apply are preserved.
+func DetectDrift(drifted *bool) ApplyOption {
+	return func(_ context.Context, existing, _ runtime.Object) error {
+		*drifted = false
+		if existing == nil {
+			return nil
+		}
+		d, err := hasDrifted(existing)
+		if err != nil {
+			return errors.Wrap(err, "cannot detect drift")
+		}
+		*drifted = d
+		return nil
+	}
+}
@@ -149,3 +149,38 @@ func getOriginalConfiguration(obj runtime.Object) ([]byte, error) {
 	}
 	return []byte(original), nil
 }
+
+// hasDrifted checks whether the fields recorded in the last-applied-state annotation are changed in the current
+// state of the object, the fields that are not recorded and the status are ignored.
+func hasDrifted(currentObj runtime.Object) (bool, error) {
+	original, err := getOriginalConfiguration(currentObj)
+	if err != nil || original == nil {
+		return false, err
+	}
+	if original, err = withoutStatus(original); err != nil {
+		return false, err
+	}
+	current, err := json.Marshal(currentObj)
+	if err != nil {
+		return false, err
+	}
+	if current, err = withoutStatus(current); err != nil {
+		return false, err
+	}
+	// the patch to restore the last applied configuration, it doesn't delete the fields that are not recorded
+	patch, err := jsonmergepatch.CreateThreeWayJSONMergePatch(original, original, current)
+	if err != nil {
+		return false, err
+	}
+	return string(patch) != "{}", nil
+}
+
+// withoutStatus removes the status from the serialized object, the status is not applied but updated by controllers
+func withoutStatus(obj []byte) ([]byte, error) {
+	var m map[string]interface{}
+	if err := json.Unmarshal(obj, &m); err != nil {
+		return nil, err
+	}
+	delete(m, "status")
+	return json.Marshal(m)
+}
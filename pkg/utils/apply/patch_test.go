@@ -76,3 +76,64 @@ func TestGetOriginalConfig(t *testing.T) {
 		})
 	}
 }
+
+func TestHasDrifted(t *testing.T) {
+	newObj := func(spec map[string]interface{}) *unstructured.Unstructured {
+		obj := &unstructured.Unstructured{Object: map[string]interface{}{
+			"apiVersion": "core.oam.dev/v1alpha2",
+			"kind":       "Component",
+			"metadata":   map[string]interface{}{"name": "comp", "namespace": "default"},
+			"spec":       spec,
+		}}
+		return obj
+	}
+	applied := newObj(map[string]interface{}{"replicas": int64(1)})
+	if err := addLastAppliedConfigAnnotation(applied); err != nil {
+		t.Fatal(err)
+	}
+	original := applied.GetAnnotations()
+
+	cases := map[string]struct {
+		reason      string
+		obj         *unstructured.Unstructured
+		wantDrifted bool
+	}{
+		"NotApplied": {
+			reason: "An object not applied before should not be drifted",
+			obj:    newObj(map[string]interface{}{"replicas": int64(2)}),
+		},
+		"Unchanged": {
+			reason: "An object should not be drifted if the applied fields are unchanged",
+			obj:    newObj(map[string]interface{}{"replicas": int64(1)}),
+		},
+		"UnownedFieldAdded": {
+			reason: "An object should not be drifted if only the fields not applied are added",
+			obj:    newObj(map[string]interface{}{"replicas": int64(1), "paused": true}),
+		},
+		"AppliedFieldChanged": {
+			reason:      "An object should be drifted if an applied field is changed",
+			obj:         newObj(map[string]interface{}{"replicas": int64(2)}),
+			wantDrifted: true,
+		},
+		"AppliedFieldRemoved": {
+			reason:      "An object should be drifted if an applied field is removed",
+			obj:         newObj(map[string]interface{}{}),
+			wantDrifted: true,
+		},
+	}
+
+	for caseName, tc := range cases {
+		t.Run(caseName, func(t *testing.T) {
+			if caseName != "NotApplied" {
+				tc.obj.SetAnnotations(original)
+			}
+			drifted, err := hasDrifted(tc.obj)
+			if err != nil {
+				t.Fatalf("\n%s\nhasDrifted(...): unexpected error: %v", tc.reason, err)
+			}
+			if diff := cmp.Diff(tc.wantDrifted, drifted); diff != "" {
+				t.Errorf("\n%s\nhasDrifted(...): -want , +got \n%s\n", tc.reason, diff)
+			}
+		})
+	}
+}
@@ -1,6 +1,9 @@
 package rollout
 
 import (
+	"net/url"
+	"time"
+
 	"k8s.io/apimachinery/pkg/util/validation/field"
 
 	"github.com/oam-dev/kubevela/apis/standard.oam.dev/v1alpha1"
@@ -15,7 +18,63 @@ func DefaultRolloutPlan(rollout *v1alpha1.RolloutPlan) {
 func ValidateCreate(rollout *v1alpha1.RolloutPlan) field.ErrorList {
 	// 1. The total number of replicas in the batches match the current target resource pod size
 	// 2. The TargetSize and NumBatches are mutually exclusive to RolloutBatches
-	return nil
+	var allErrs field.ErrorList
+	fldPath := field.NewPath("spec", "rolloutPlan")
+	allErrs = append(allErrs, validateWebhooks(rollout.RolloutWebhooks, fldPath.Child("rolloutWebhooks"),
+		v1alpha1.InitializeRolloutHook, v1alpha1.PreBatchRolloutHook, v1alpha1.PostBatchRolloutHook,
+		v1alpha1.FinalizeRolloutHook)...)
+	for i, batch := range rollout.RolloutBatches {
+		allErrs = append(allErrs, validateWebhooks(batch.BatchRolloutWebhooks,
+			fldPath.Child("rolloutBatches").Index(i).Child("batchRolloutWebhooks"),
+			v1alpha1.PreBatchRolloutHook, v1alpha1.PostBatchRolloutHook)...)
+	}
+	return allErrs
+}
+
+// maxWebhookAttempts is the max number of attempts of a webhook before it gives up retrying
+const maxWebhookAttempts = 10
+
+// validateWebhooks makes sure the webhooks are of the allowed types and their settings can be parsed
+func validateWebhooks(hooks []v1alpha1.RolloutWebhook, fldPath *field.Path, allowed ...v1alpha1.HookType) field.ErrorList {
+	var allErrs field.ErrorList
+	var allowedTypes []string
+	for _, t := range allowed {
+		allowedTypes = append(allowedTypes, string(t))
+	}
+	for i, hook := range hooks {
+		hookPath := fldPath.Index(i)
+		validType := false
+		for _, t := range allowed {
+			validType = validType || hook.Type == t
+		}
+		if !validType {
+			allErrs = append(allErrs, field.NotSupported(hookPath.Child("type"), hook.Type, allowedTypes))
+		}
+		if _, err := url.ParseRequestURI(hook.URL); err != nil {
+			allErrs = append(allErrs, field.Invalid(hookPath.Child("url"), hook.URL, err.Error()))
+		}
+		if len(hook.Timeout) != 0 {
+			if _, err := time.ParseDuration(hook.Timeout); err != nil {
+				allErrs = append(allErrs, field.Invalid(hookPath.Child("timeout"), hook.Timeout, err.Error()))
+			}
+		}
+		if hook.SecretRef != nil && (len(hook.SecretRef.Name) == 0 || len(hook.SecretRef.Key) == 0) {
+			allErrs = append(allErrs, field.Required(hookPath.Child("secretRef"), "both name and key of the secret are required"))
+		}
+		if hook.Retry == nil {
+			continue
+		}
+		if hook.Retry.Attempts != nil && (*hook.Retry.Attempts < 1 || *hook.Retry.Attempts > maxWebhookAttempts) {
+			allErrs = append(allErrs, field.Invalid(hookPath.Child("retry", "attempts"), *hook.Retry.Attempts,
+				"the attempts must be between 1 and 10"))
+		}
+		if len(hook.Retry.Backoff) != 0 {
+			if _, err := time.ParseDuration(hook.Retry.Backoff); err != nil {
+				allErrs = append(allErrs, field.Invalid(hookPath.Child("retry", "backoff"), hook.Retry.Backoff, err.Error()))
+			}
+		}
+	}
+	return allErrs
 }
 
 // ValidateUpdate validate if one can change the rollout plan from the previous psec
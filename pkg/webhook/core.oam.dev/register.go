@@ -6,6 +6,7 @@ import (
 	"github.com/oam-dev/kubevela/pkg/webhook/core.oam.dev/v1alpha2/application"
 	"github.com/oam-dev/kubevela/pkg/webhook/core.oam.dev/v1alpha2/applicationconfiguration"
 	"github.com/oam-dev/kubevela/pkg/webhook/core.oam.dev/v1alpha2/applicationdeployment"
+	"github.com/oam-dev/kubevela/pkg/webhook/core.oam.dev/v1alpha2/applicationrevision"
 	"github.com/oam-dev/kubevela/pkg/webhook/core.oam.dev/v1alpha2/component"
 )
 
@@ -19,6 +20,7 @@ func Register(mgr manager.Manager) error {
 	}
 	applicationconfiguration.RegisterMutatingHandler(mgr)
 	applicationdeployment.RegisterMutatingHandler(mgr)
+	applicationrevision.RegisterValidatingHandler(mgr)
 	if err := component.RegisterMutatingHandler(mgr); err != nil {
 		return err
 	}
@@ -14,6 +14,7 @@ import (
 	"github.com/oam-dev/kubevela/apis/core.oam.dev/v1alpha2"
 	"github.com/oam-dev/kubevela/pkg/controller/core.oam.dev/v1alpha2/application"
 	"github.com/oam-dev/kubevela/pkg/oam/discoverymapper"
+	"github.com/oam-dev/kubevela/pkg/oam/util"
 )
 
 var _ admission.Handler = &ValidatingHandler{}
@@ -24,6 +25,8 @@ type ValidatingHandler struct {
 	Client client.Client
 	// Decoder decodes objects
 	Decoder *admission.Decoder
+	// templates caches the templates parsed from the definitions
+	templates *util.TemplateCache
 }
 
 var _ inject.Client = &ValidatingHandler{}
@@ -60,7 +63,12 @@ func (h *ValidatingHandler) Handle(ctx context.Context, req admission.Request) a
 
 	// try render to validate
 	appParser := application.NewApplicationParser(h.Client, h.dm)
-	if _, err := appParser.GenerateAppFile(app.Name, app); err != nil {
+	appParser.UseTemplateCache(h.templates)
+	appfile, err := appParser.GenerateAppFile(app.Name, app)
+	if err != nil {
+		return admission.Denied(err.Error())
+	}
+	if err := appfile.ValidateParameters(); err != nil {
 		return admission.Denied(err.Error())
 	}
 	return admission.ValidationResponse(true, "")
@@ -73,6 +81,7 @@ func RegisterValidatingHandler(mgr manager.Manager) error {
 		return err
 	}
 	server := mgr.GetWebhookServer()
-	server.Register("/validating-core-oam-dev-v1alpha2-applications", &webhook.Admission{Handler: &ValidatingHandler{dm: mapper}})
+	handler := &ValidatingHandler{dm: mapper, templates: util.NewTemplateCache()}
+	server.Register("/validating-core-oam-dev-v1alpha2-applications", &webhook.Admission{Handler: handler})
 	return nil
 }
@@ -68,4 +68,24 @@ var _ = Describe("Test Application Validater", func() {
 		resp := handler.Handle(ctx, req)
 		Expect(resp.Allowed).Should(BeFalse())
 	})
+	It("Test Application Validater [Invalid parameters]", func() {
+		req := admission.Request{
+			AdmissionRequest: admissionv1beta1.AdmissionRequest{
+				Operation: admissionv1beta1.Create,
+				Resource:  metav1.GroupVersionResource{Group: "core.oam.dev", Version: "v1alpha2", Resource: "applications"},
+				Object: runtime.RawExtension{
+					Raw: []byte(`{"apiVersion":"core.oam.dev/v1alpha2",
+"kind":"Application",
+"metadata":{"name":"application-sample"},
+"spec":{"components":[{"name":"myweb","settings":{"cmds":["sleep","1000"],"image":"busybox"},
+"traits":[{"name":"scaler","properties":{"replicas":"10"}}],"type":"worker"}]}}`),
+				},
+			},
+		}
+		resp := handler.Handle(ctx, req)
+		Expect(resp.Allowed).Should(BeFalse())
+		Expect(string(resp.Result.Reason)).Should(ContainSubstring("component myweb: settings.cmds: unknown field"))
+		Expect(string(resp.Result.Reason)).Should(ContainSubstring(
+			`component myweb trait scaler: properties.replicas: expected int, got "10"`))
+	})
 })
@@ -2,10 +2,13 @@ package applicationdeployment
 
 import (
 	"context"
+	"fmt"
 	"net/http"
 
 	admissionv1beta1 "k8s.io/api/admission/v1beta1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
 	apimachineryvalidation "k8s.io/apimachinery/pkg/api/validation"
+	"k8s.io/apimachinery/pkg/types"
 	"k8s.io/apimachinery/pkg/util/validation/field"
 	"k8s.io/klog/v2"
 	"sigs.k8s.io/controller-runtime/pkg/client"
@@ -41,7 +44,9 @@ func (h *ValidatingHandler) Handle(ctx context.Context, req admission.Request) a
 
 	switch req.AdmissionRequest.Operation {
 	case admissionv1beta1.Create:
-		if allErrs := ValidateCreate(obj); len(allErrs) > 0 {
+		allErrs := ValidateCreate(obj)
+		allErrs = append(allErrs, h.ValidateComponents(ctx, obj)...)
+		if len(allErrs) > 0 {
 			return admission.Errored(http.StatusUnprocessableEntity, allErrs.ToAggregate())
 		}
 	case admissionv1beta1.Update:
@@ -50,7 +55,9 @@ func (h *ValidatingHandler) Handle(ctx context.Context, req admission.Request) a
 			return admission.Errored(http.StatusBadRequest, err)
 		}
 
-		if allErrs := ValidateUpdate(obj, oldObj); len(allErrs) > 0 {
+		allErrs := ValidateUpdate(obj, oldObj)
+		allErrs = append(allErrs, h.ValidateComponents(ctx, obj)...)
+		if len(allErrs) > 0 {
 			return admission.Errored(http.StatusUnprocessableEntity, allErrs.ToAggregate())
 		}
 	default:
@@ -73,15 +80,71 @@ func ValidateCreate(r *v1alpha2.ApplicationDeployment) field.ErrorList {
 			"source application cannot be empty"))
 	}
 
-	//TODO: Add ComponentList check
-	// 1. there can only be one component or less
-	// 2. if there are no components, make sure the applications has only one common component and that's the default
-	// 3. it is contained in both source and target application
+	seen := make(map[string]bool)
+	for i, name := range r.Spec.ComponentList {
+		if seen[name] {
+			allErrs = append(allErrs, field.Duplicate(fldPath.Child("componentList").Index(i), name))
+		}
+		seen[name] = true
+	}
 
 	allErrs = append(allErrs, rollout.ValidateCreate(&r.Spec.RolloutPlan)...)
 	return allErrs
 }
 
+// ValidateComponents makes sure that the components to upgrade are contained in both the source and the target
+// application. If the component list is omitted, the target application must have one and only one component.
+func (h *ValidatingHandler) ValidateComponents(ctx context.Context, r *v1alpha2.ApplicationDeployment) field.ErrorList {
+	fldPath := field.NewPath("spec")
+	var target v1alpha2.Application
+	if err := h.Client.Get(ctx, types.NamespacedName{Namespace: r.Namespace, Name: r.Spec.TargetApplicationName},
+		&target); err != nil {
+		return field.ErrorList{applicationError(fldPath.Child("targetApplicationName"), r.Spec.TargetApplicationName, err)}
+	}
+	componentList := r.Spec.ComponentList
+	if len(componentList) == 0 {
+		if len(target.Spec.Components) != 1 {
+			return field.ErrorList{field.Required(fldPath.Child("componentList"),
+				"the component list is required if the target application doesn't have exactly one component")}
+		}
+		componentList = []string{target.Spec.Components[0].Name}
+	}
+
+	allErrs := validateComponentsInApp(fldPath.Child("componentList"), componentList, &target)
+	if r.Spec.SourceApplicationName != nil && len(*r.Spec.SourceApplicationName) != 0 {
+		var source v1alpha2.Application
+		if err := h.Client.Get(ctx, types.NamespacedName{Namespace: r.Namespace, Name: *r.Spec.SourceApplicationName},
+			&source); err != nil {
+			return append(allErrs, applicationError(fldPath.Child("sourceApplicationName"),
+				*r.Spec.SourceApplicationName, err))
+		}
+		allErrs = append(allErrs, validateComponentsInApp(fldPath.Child("componentList"), componentList, &source)...)
+	}
+	return allErrs
+}
+
+func validateComponentsInApp(fldPath *field.Path, componentList []string, app *v1alpha2.Application) field.ErrorList {
+	var allErrs field.ErrorList
+	components := make(map[string]bool)
+	for _, comp := range app.Spec.Components {
+		components[comp.Name] = true
+	}
+	for i, name := range componentList {
+		if !components[name] {
+			allErrs = append(allErrs, field.NotFound(fldPath.Index(i),
+				fmt.Sprintf("component %s in application %s", name, app.Name)))
+		}
+	}
+	return allErrs
+}
+
+func applicationError(fldPath *field.Path, name string, err error) *field.Error {
+	if apierrors.IsNotFound(err) {
+		return field.NotFound(fldPath, name)
+	}
+	return field.InternalError(fldPath, err)
+}
+
 // ValidateUpdate validates the ApplicationDeployment on update
 func ValidateUpdate(new *v1alpha2.ApplicationDeployment, prev *v1alpha2.ApplicationDeployment) field.ErrorList {
 	klog.InfoS("validate update", "name", new.Name)
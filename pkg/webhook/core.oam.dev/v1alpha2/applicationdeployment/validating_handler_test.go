@@ -0,0 +1,93 @@
+package applicationdeployment
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/util/validation/field"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+
+	core "github.com/oam-dev/kubevela/apis/core.oam.dev"
+	"github.com/oam-dev/kubevela/apis/core.oam.dev/v1alpha2"
+)
+
+func newApplication(name string, components ...string) *v1alpha2.Application {
+	app := &v1alpha2.Application{ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: "default"}}
+	for _, comp := range components {
+		app.Spec.Components = append(app.Spec.Components, v1alpha2.ApplicationComponent{Name: comp, WorkloadType: "webservice"})
+	}
+	return app
+}
+
+func TestValidateComponents(t *testing.T) {
+	s := runtime.NewScheme()
+	assert.NoError(t, core.AddToScheme(s))
+	h := &ValidatingHandler{Client: fake.NewFakeClientWithScheme(s,
+		newApplication("single", "frontend"),
+		newApplication("source", "frontend", "backend"),
+		newApplication("target", "frontend", "backend", "worker"),
+	)}
+
+	tests := map[string]struct {
+		target        string
+		source        string
+		componentList []string
+		want          field.ErrorList
+	}{
+		"components in both applications": {
+			target:        "target",
+			source:        "source",
+			componentList: []string{"backend", "frontend"},
+		},
+		"the only component is the default": {
+			target: "single",
+			source: "source",
+		},
+		"component list is required": {
+			target: "target",
+			want: field.ErrorList{field.Required(field.NewPath("spec", "componentList"),
+				"the component list is required if the target application doesn't have exactly one component")},
+		},
+		"component not in the source": {
+			target:        "target",
+			source:        "source",
+			componentList: []string{"frontend", "worker"},
+			want: field.ErrorList{field.NotFound(field.NewPath("spec", "componentList").Index(1),
+				"component worker in application source")},
+		},
+		"application not found": {
+			target:        "target",
+			source:        "unknown",
+			componentList: []string{"frontend"},
+			want:          field.ErrorList{field.NotFound(field.NewPath("spec", "sourceApplicationName"), "unknown")},
+		},
+	}
+	for name, tc := range tests {
+		appdeploy := &v1alpha2.ApplicationDeployment{
+			ObjectMeta: metav1.ObjectMeta{Name: "deploy", Namespace: "default"},
+			Spec: v1alpha2.ApplicationDeploymentSpec{
+				TargetApplicationName: tc.target,
+				ComponentList:         tc.componentList,
+			},
+		}
+		if len(tc.source) != 0 {
+			appdeploy.Spec.SourceApplicationName = &tc.source
+		}
+		assert.Equal(t, tc.want, h.ValidateComponents(context.Background(), appdeploy), name)
+	}
+}
+
+func TestValidateCreateDuplicateComponents(t *testing.T) {
+	appdeploy := &v1alpha2.ApplicationDeployment{
+		ObjectMeta: metav1.ObjectMeta{Name: "deploy", Namespace: "default"},
+		Spec: v1alpha2.ApplicationDeploymentSpec{
+			TargetApplicationName: "target",
+			ComponentList:         []string{"frontend", "backend", "frontend"},
+		},
+	}
+	assert.Equal(t, field.ErrorList{field.Duplicate(field.NewPath("spec", "componentList").Index(2), "frontend")},
+		ValidateCreate(appdeploy))
+}
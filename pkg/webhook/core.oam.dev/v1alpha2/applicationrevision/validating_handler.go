@@ -0,0 +1,65 @@
+package applicationrevision
+
+import (
+	"context"
+	"net/http"
+
+	admissionv1beta1 "k8s.io/api/admission/v1beta1"
+	apiequality "k8s.io/apimachinery/pkg/api/equality"
+	"k8s.io/apimachinery/pkg/util/validation/field"
+	"sigs.k8s.io/controller-runtime/pkg/manager"
+	"sigs.k8s.io/controller-runtime/pkg/webhook"
+	"sigs.k8s.io/controller-runtime/pkg/webhook/admission"
+
+	"github.com/oam-dev/kubevela/apis/core.oam.dev/v1alpha2"
+)
+
+// ValidatingHandler handles ApplicationRevision
+type ValidatingHandler struct {
+	// Decoder decodes objects
+	Decoder *admission.Decoder
+}
+
+var _ admission.Handler = &ValidatingHandler{}
+
+// Handle rejects any change to the spec of an ApplicationRevision as it's immutable
+func (h *ValidatingHandler) Handle(ctx context.Context, req admission.Request) admission.Response {
+	if req.AdmissionRequest.Operation != admissionv1beta1.Update {
+		return admission.ValidationResponse(true, "")
+	}
+	obj := &v1alpha2.ApplicationRevision{}
+	if err := h.Decoder.Decode(req, obj); err != nil {
+		return admission.Errored(http.StatusBadRequest, err)
+	}
+	oldObj := &v1alpha2.ApplicationRevision{}
+	if err := h.Decoder.DecodeRaw(req.AdmissionRequest.OldObject, oldObj); err != nil {
+		return admission.Errored(http.StatusBadRequest, err)
+	}
+	if allErrs := ValidateUpdate(obj, oldObj); len(allErrs) > 0 {
+		return admission.Errored(http.StatusUnprocessableEntity, allErrs.ToAggregate())
+	}
+	return admission.ValidationResponse(true, "")
+}
+
+// ValidateUpdate makes sure the spec of the ApplicationRevision is not changed
+func ValidateUpdate(new *v1alpha2.ApplicationRevision, prev *v1alpha2.ApplicationRevision) field.ErrorList {
+	if !apiequality.Semantic.DeepEqual(new.Spec, prev.Spec) {
+		return field.ErrorList{field.Forbidden(field.NewPath("spec"), "the application revision is immutable")}
+	}
+	return nil
+}
+
+var _ admission.DecoderInjector = &ValidatingHandler{}
+
+// InjectDecoder injects the decoder into the ValidatingHandler
+func (h *ValidatingHandler) InjectDecoder(d *admission.Decoder) error {
+	h.Decoder = d
+	return nil
+}
+
+// RegisterValidatingHandler will register application revision validation to webhook
+func RegisterValidatingHandler(mgr manager.Manager) {
+	server := mgr.GetWebhookServer()
+	server.Register("/validating-core-oam-dev-v1alpha2-applicationrevisions",
+		&webhook.Admission{Handler: &ValidatingHandler{}})
+}
@@ -0,0 +1,33 @@
+package applicationrevision
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/validation/field"
+
+	"github.com/oam-dev/kubevela/apis/core.oam.dev/v1alpha2"
+)
+
+func TestValidateUpdate(t *testing.T) {
+	prev := &v1alpha2.ApplicationRevision{
+		ObjectMeta: metav1.ObjectMeta{Name: "myapp-v1", Namespace: "default"},
+		Spec: v1alpha2.ApplicationRevisionSpec{
+			Revision: 1,
+			Application: v1alpha2.ApplicationSpec{Components: []v1alpha2.ApplicationComponent{
+				{Name: "myweb", WorkloadType: "webservice"},
+			}},
+		},
+	}
+
+	// the metadata can be changed
+	labeled := prev.DeepCopy()
+	labeled.Labels = map[string]string{"team": "web"}
+	assert.Empty(t, ValidateUpdate(labeled, prev))
+
+	changed := prev.DeepCopy()
+	changed.Spec.Application.Components[0].WorkloadType = "worker"
+	assert.Equal(t, field.ErrorList{field.Forbidden(field.NewPath("spec"), "the application revision is immutable")},
+		ValidateUpdate(changed, prev))
+}